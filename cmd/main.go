@@ -1,16 +1,20 @@
 package main
 
 import (
+	"crypto/rand"
 	"jobs-svc/middleware"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"jobs-svc/internal/handlers"
 	"jobs-svc/internal/kafka"
 	"jobs-svc/internal/models"
 	"jobs-svc/internal/repos"
 	"jobs-svc/internal/services"
+	"jobs-svc/internal/storage"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -22,6 +26,83 @@ func LoadEnv() {
 	}
 }
 
+// purgeRetention reads how many days soft-deleted jobs are kept from JOB_PURGE_RETENTION_DAYS
+func purgeRetention() time.Duration {
+	value := os.Getenv("JOB_PURGE_RETENTION_DAYS")
+	if value == "" {
+		return services.DefaultJobPurgeRetention
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		log.Fatalf("Invalid JOB_PURGE_RETENTION_DAYS: %s", value)
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// reapplyCooldown reads how many days a candidate waits to reapply after withdrawing from APPLICATION_REAPPLY_COOLDOWN_DAYS
+func reapplyCooldown() time.Duration {
+	value := os.Getenv("APPLICATION_REAPPLY_COOLDOWN_DAYS")
+	if value == "" {
+		return services.DefaultReapplyCooldown
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		log.Fatalf("Invalid APPLICATION_REAPPLY_COOLDOWN_DAYS: %s", value)
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// offerSweepInterval reads how many minutes pass between sweeps for expired offers from OFFER_SWEEP_INTERVAL_MINUTES
+func offerSweepInterval() time.Duration {
+	value := os.Getenv("OFFER_SWEEP_INTERVAL_MINUTES")
+	if value == "" {
+		return services.DefaultOfferSweepInterval
+	}
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 1 {
+		log.Fatalf("Invalid OFFER_SWEEP_INTERVAL_MINUTES: %s", value)
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// resumeLinks reads how resume download links are signed: RESUME_LINK_SECRET keys the
+// signatures, RESUME_LINK_TTL_MINUTES sets how long links last and PUBLIC_BASE_URL is
+// where clients reach the service
+func resumeLinks() *services.ResumeLinks {
+	links := &services.ResumeLinks{
+		Secret:  []byte(os.Getenv("RESUME_LINK_SECRET")),
+		TTL:     services.DefaultResumeLinkTTL,
+		BaseURL: os.Getenv("PUBLIC_BASE_URL"),
+	}
+	if len(links.Secret) == 0 {
+		log.Println("RESUME_LINK_SECRET is not set, resume links will stop working when the service restarts")
+		links.Secret = make([]byte, 32)
+		if _, err := rand.Read(links.Secret); err != nil {
+			log.Fatalf("Failed to generate resume link secret: %v", err)
+		}
+	}
+	if links.BaseURL == "" {
+		links.BaseURL = "http://localhost:8080"
+	}
+	if value := os.Getenv("RESUME_LINK_TTL_MINUTES"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes < 1 {
+			log.Fatalf("Invalid RESUME_LINK_TTL_MINUTES: %s", value)
+		}
+		links.TTL = time.Duration(minutes) * time.Minute
+	}
+	return links
+}
+
+// sweepExpiredOffers expires offers past their expiry once per interval, for as long as the service runs
+func sweepExpiredOffers(handler *handlers.OfferHandler, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		handler.ExpireOffers(now)
+	}
+}
+
 func main() {
 	LoadEnv()
 
@@ -32,7 +113,7 @@ func main() {
 	jobsDB := models.PostgresDB
 	appsDB := models.MongoDB
 
-	err := jobsDB.AutoMigrate(&models.Job{})
+	err := jobsDB.AutoMigrate(&models.Job{}, &models.JobRevision{})
 	if err != nil {
 		return
 	}
@@ -74,16 +155,117 @@ func main() {
 
 	// init repositories, services,handlers
 	jobRepo := repos.JobRepo{DB: jobsDB}
+	jobRevisionRepo := repos.JobRevisionRepo{DB: jobsDB}
 	applicationRepo := &repos.ApplicationRepo{Collection: appsDB.Collection("applications")}
 
 	// Create unique index for applications
 	if err := applicationRepo.CreateUniqueIndex(); err != nil {
 		log.Fatal("Failed to create unique index for applications:", err)
 	}
-	log.Println("Created unique index on candidate_id and job_id")
+	log.Println("Created unique index on candidate_id and job_id for active applications")
+	if err := applicationRepo.CreateListingIndexes(); err != nil {
+		log.Fatal("Failed to create listing indexes for applications:", err)
+	}
+	log.Println("Created listing indexes for applications")
+
+	pipelineRepo := &repos.PipelineRepo{Collection: appsDB.Collection("pipelines")}
+	if err := pipelineRepo.CreateUniqueIndex(); err != nil {
+		log.Fatal("Failed to create unique index for pipelines:", err)
+	}
+
+	noteRepo := &repos.NoteRepo{Collection: appsDB.Collection("application_notes")}
+	if err := noteRepo.CreateIndexes(); err != nil {
+		log.Fatal("Failed to create indexes for application notes:", err)
+	}
+
+	interviewRepo := &repos.InterviewRepo{
+		Collection: appsDB.Collection("interviews"),
+		Slots:      appsDB.Collection("interviewer_slots"),
+	}
+	if err := interviewRepo.CreateIndexes(); err != nil {
+		log.Fatal("Failed to create indexes for interviews:", err)
+	}
+
+	offerRepo := &repos.OfferRepo{Collection: appsDB.Collection("offers")}
+	if err := offerRepo.CreateIndexes(); err != nil {
+		log.Fatal("Failed to create indexes for offers:", err)
+	}
+
+	reasonRepo := &repos.RejectionReasonRepo{Collection: appsDB.Collection("rejection_reasons")}
+	if err := reasonRepo.CreateUniqueIndex(); err != nil {
+		log.Fatal("Failed to create unique index for rejection reasons:", err)
+	}
+
+	analyticsRepo := &repos.AnalyticsRepo{Collection: appsDB.Collection("applications")}
+	if err := analyticsRepo.CreateIndexes(); err != nil {
+		log.Fatal("Failed to create analytics indexes for applications:", err)
+	}
+
+	resumeDir := os.Getenv("RESUME_STORAGE_DIR")
+	if resumeDir == "" {
+		resumeDir = "data/resumes"
+	}
+	resumeStore, err := storage.NewLocalStore(resumeDir)
+	if err != nil {
+		log.Fatal("Failed to initialize resume storage:", err)
+	}
+	links := resumeLinks()
+	resumeMatcher := &services.ResumeMatcher{
+		AppRepo: applicationRepo,
+		JobRepo: &jobRepo,
+		Store:   resumeStore,
+		Queue:   make(chan string, services.DefaultMatchQueueSize),
+	}
 
-	jobService := services.JobService{JobRepo: &jobRepo}
-	applicationService := services.ApplicationsService{AppRepo: applicationRepo}
+	jobService := services.JobService{
+		JobRepo:        &jobRepo,
+		RevisionRepo:   &jobRevisionRepo,
+		PurgeRetention: purgeRetention(),
+		AppRepo:        applicationRepo,
+		PipelineRepo:   pipelineRepo,
+	}
+	applicationService := services.ApplicationsService{
+		AppRepo:         applicationRepo,
+		JobRepo:         &jobRepo,
+		PipelineRepo:    pipelineRepo,
+		ReasonRepo:      reasonRepo,
+		ReapplyCooldown: reapplyCooldown(),
+		ResumeLinks:     links,
+		Matcher:         resumeMatcher,
+	}
+	resumeService := services.ResumeService{
+		AppRepo:      applicationRepo,
+		JobRepo:      &jobRepo,
+		PipelineRepo: pipelineRepo,
+		Store:        resumeStore,
+		Links:        links,
+		Matcher:      resumeMatcher,
+	}
+	pipelineService := services.PipelineService{PipelineRepo: pipelineRepo, JobRepo: &jobRepo}
+	noteService := services.NoteService{
+		NoteRepo: noteRepo,
+		AppRepo:  applicationRepo,
+		JobRepo:  &jobRepo,
+	}
+	interviewService := services.InterviewService{
+		InterviewRepo: interviewRepo,
+		AppRepo:       applicationRepo,
+		JobRepo:       &jobRepo,
+		PipelineRepo:  pipelineRepo,
+	}
+	offerService := services.OfferService{
+		OfferRepo:    offerRepo,
+		AppRepo:      applicationRepo,
+		JobRepo:      &jobRepo,
+		PipelineRepo: pipelineRepo,
+	}
+	analyticsService := services.AnalyticsService{
+		AnalyticsRepo: analyticsRepo,
+		JobRepo:       &jobRepo,
+		PipelineRepo:  pipelineRepo,
+		ReasonRepo:    reasonRepo,
+	}
+	reasonService := services.RejectionReasonService{ReasonRepo: reasonRepo}
 
 	jobHandler := handlers.JobHandler{
 		JobService:     jobService,
@@ -93,6 +275,25 @@ func main() {
 		ApplicationService: applicationService,
 		KafkaPublisher:     kafkaPublisher,
 	}
+	resumeHandler := handlers.ResumeHandler{
+		ResumeService:  resumeService,
+		KafkaPublisher: kafkaPublisher,
+	}
+	pipelineHandler := handlers.PipelineHandler{PipelineService: pipelineService}
+	analyticsHandler := handlers.AnalyticsHandler{AnalyticsService: analyticsService}
+	reasonHandler := handlers.RejectionReasonHandler{RejectionReasonService: reasonService}
+	interviewHandler := handlers.InterviewHandler{InterviewService: interviewService}
+	noteHandler := handlers.NoteHandler{
+		NoteService:    noteService,
+		KafkaPublisher: kafkaPublisher,
+	}
+	offerHandler := handlers.OfferHandler{
+		OfferService:   offerService,
+		KafkaPublisher: kafkaPublisher,
+	}
+
+	go sweepExpiredOffers(&offerHandler, offerSweepInterval())
+	go resumeMatcher.Run()
 
 	router := mux.NewRouter()
 	//router.Use(middleware.CORSMiddleware)
@@ -102,17 +303,81 @@ func main() {
 	router.Handle("/jobs", middleware.AuthMiddleware("create_job")(http.HandlerFunc(jobHandler.CreateJob))).Methods("POST")
 	router.HandleFunc("/jobs", jobHandler.GetJobs).Methods("GET")
 	router.HandleFunc("/jobs/summary", jobHandler.GetJobsByIDs).Methods("POST")
+	router.HandleFunc("/jobs/search", jobHandler.SearchJobs).Methods("GET")
+	router.Handle("/jobs/deleted", middleware.AuthMiddleware("delete_job")(http.HandlerFunc(jobHandler.GetDeletedJobs))).Methods("GET")
 	router.HandleFunc("/jobs/{id}", jobHandler.GetJobByID).Methods("GET")
 	router.HandleFunc("/jobs/recruiter/{id}", jobHandler.GetJobsByRecruiterID).Methods("GET")
-	router.HandleFunc("/jobs/{id}", jobHandler.UpdateJob).Methods("PUT")
-	router.HandleFunc("/jobs/{id}", jobHandler.DeleteJob).Methods("DELETE")
+	router.Handle("/jobs/{id}", middleware.AuthMiddleware("update_job")(http.HandlerFunc(jobHandler.UpdateJob))).Methods("PUT")
+	router.Handle("/jobs/{id}", middleware.AuthMiddleware("update_job")(http.HandlerFunc(jobHandler.PatchJob))).Methods("PATCH")
+	router.Handle("/jobs/{id}", middleware.AuthMiddleware("delete_job")(http.HandlerFunc(jobHandler.DeleteJob))).Methods("DELETE")
+	router.Handle("/jobs/{id}/restore", middleware.AuthMiddleware("delete_job")(http.HandlerFunc(jobHandler.RestoreJob))).Methods("POST")
+	router.Handle("/jobs/{id}/publish", middleware.AuthMiddleware("update_job")(http.HandlerFunc(jobHandler.PublishJob))).Methods("POST")
+	router.Handle("/jobs/{id}/pause", middleware.AuthMiddleware("update_job")(http.HandlerFunc(jobHandler.PauseJob))).Methods("POST")
+	router.Handle("/jobs/{id}/close", middleware.AuthMiddleware("update_job")(http.HandlerFunc(jobHandler.CloseJob))).Methods("POST")
+	router.Handle("/jobs/{id}/revisions", middleware.AuthMiddleware("update_job")(http.HandlerFunc(jobHandler.GetJobRevisions))).Methods("GET")
+	router.Handle("/jobs/{id}/revisions/{rev}/diff", middleware.AuthMiddleware("update_job")(http.HandlerFunc(jobHandler.GetJobRevisionDiff))).Methods("GET")
+
+	// admin routes
+	router.Handle("/admin/jobs/purge", middleware.AuthMiddleware("purge_jobs")(http.HandlerFunc(jobHandler.PurgeDeletedJobs))).Methods("POST")
 
 	// app related routes
 	router.HandleFunc("/applications", applicationHandler.CreateApplication).Methods("POST")
-	router.HandleFunc("/applications/job/{id}", applicationHandler.GetApplicationsByJobID).Methods("GET")
+	router.Handle("/applications/bulk", middleware.AuthMiddleware("update_application")(http.HandlerFunc(applicationHandler.BulkUpdateApplications))).Methods("POST")
+	router.Handle("/jobs/{id}/board", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(applicationHandler.GetJobBoard))).Methods("GET")
+	router.Handle("/applications/job/{id}", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(applicationHandler.GetApplicationsByJobID))).Methods("GET")
 	router.HandleFunc("/applications/{id}", applicationHandler.GetApplicationByID).Methods("GET")
+	router.Handle("/applications/{id}/stage", middleware.AuthMiddleware("update_application")(http.HandlerFunc(applicationHandler.UpdateApplicationStage))).Methods("PATCH")
+	router.Handle("/applications/{id}/withdraw", middleware.CandidateAuthMiddleware("withdraw_application")(http.HandlerFunc(applicationHandler.WithdrawApplication))).Methods("POST")
+	router.Handle("/applications/{id}/scorecards", middleware.AuthMiddleware("submit_scorecard")(http.HandlerFunc(applicationHandler.SubmitScorecard))).Methods("POST")
+	router.Handle("/applications/{id}/scorecards", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(applicationHandler.GetScorecards))).Methods("GET")
 	router.HandleFunc("/applications/candidate/{id}", applicationHandler.GetApplicationByCandidateID).Methods("GET")
 
+	// resume routes; downloads are authorized by the link's signature
+	router.Handle("/applications/{id}/resume", middleware.CandidateAuthMiddleware("upload_resume")(http.HandlerFunc(resumeHandler.UploadResume))).Methods("POST")
+	router.HandleFunc("/applications/{id}/resume", resumeHandler.DownloadResume).Methods("GET")
+
+	// application note routes
+	router.Handle("/applications/{id}/notes", middleware.AuthMiddleware("manage_notes")(http.HandlerFunc(noteHandler.CreateNote))).Methods("POST")
+	router.Handle("/applications/{id}/notes", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(noteHandler.GetNotes))).Methods("GET")
+	router.Handle("/applications/{id}/notes/{noteId}", middleware.AuthMiddleware("manage_notes")(http.HandlerFunc(noteHandler.UpdateNote))).Methods("PUT")
+	router.Handle("/applications/{id}/notes/{noteId}", middleware.AuthMiddleware("manage_notes")(http.HandlerFunc(noteHandler.DeleteNote))).Methods("DELETE")
+
+	// interview routes
+	router.Handle("/applications/{id}/interviews", middleware.AuthMiddleware("schedule_interview")(http.HandlerFunc(interviewHandler.ScheduleInterview))).Methods("POST")
+	router.Handle("/applications/{id}/interviews", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(interviewHandler.GetInterviews))).Methods("GET")
+	router.Handle("/interviews/{id}", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(interviewHandler.GetInterview))).Methods("GET")
+	router.Handle("/interviews/{id}", middleware.AuthMiddleware("schedule_interview")(http.HandlerFunc(interviewHandler.RescheduleInterview))).Methods("PUT")
+	router.Handle("/interviews/{id}/cancel", middleware.AuthMiddleware("schedule_interview")(http.HandlerFunc(interviewHandler.CancelInterview))).Methods("POST")
+	router.Handle("/interviews/{id}/invite.ics", middleware.CandidateAuthMiddleware("view_interview")(http.HandlerFunc(interviewHandler.GetInterviewCalendar))).Methods("GET")
+
+	// offer routes
+	router.Handle("/applications/{id}/offers", middleware.AuthMiddleware("manage_offers")(http.HandlerFunc(offerHandler.CreateOffer))).Methods("POST")
+	router.Handle("/applications/{id}/offers", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(offerHandler.GetOffers))).Methods("GET")
+	router.Handle("/offers/{id}", middleware.CandidateAuthMiddleware("view_offer")(http.HandlerFunc(offerHandler.GetOffer))).Methods("GET")
+	router.Handle("/offers/{id}", middleware.AuthMiddleware("manage_offers")(http.HandlerFunc(offerHandler.UpdateOffer))).Methods("PUT")
+	router.Handle("/offers/{id}/submit", middleware.AuthMiddleware("manage_offers")(http.HandlerFunc(offerHandler.SubmitOffer))).Methods("POST")
+	router.Handle("/offers/{id}/extend", middleware.AuthMiddleware("approve_offers")(http.HandlerFunc(offerHandler.ExtendOffer))).Methods("POST")
+	router.Handle("/offers/{id}/accept", middleware.CandidateAuthMiddleware("respond_offer")(http.HandlerFunc(offerHandler.AcceptOffer))).Methods("POST")
+	router.Handle("/offers/{id}/decline", middleware.CandidateAuthMiddleware("respond_offer")(http.HandlerFunc(offerHandler.DeclineOffer))).Methods("POST")
+
+	// pipeline routes
+	router.Handle("/pipelines", middleware.AuthMiddleware("manage_pipelines")(http.HandlerFunc(pipelineHandler.CreatePipeline))).Methods("POST")
+	router.Handle("/pipelines", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(pipelineHandler.GetPipelines))).Methods("GET")
+	router.HandleFunc("/pipelines/default", pipelineHandler.GetDefaultPipeline).Methods("GET")
+	router.Handle("/pipelines/{id}", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(pipelineHandler.GetPipelineByID))).Methods("GET")
+	router.Handle("/pipelines/{id}", middleware.AuthMiddleware("manage_pipelines")(http.HandlerFunc(pipelineHandler.UpdatePipeline))).Methods("PUT")
+	router.Handle("/pipelines/{id}", middleware.AuthMiddleware("manage_pipelines")(http.HandlerFunc(pipelineHandler.DeletePipeline))).Methods("DELETE")
+
+	// rejection reason routes
+	router.Handle("/rejection-reasons", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(reasonHandler.GetReasons))).Methods("GET")
+	router.Handle("/rejection-reasons", middleware.AuthMiddleware("manage_rejection_reasons")(http.HandlerFunc(reasonHandler.CreateReason))).Methods("POST")
+	router.Handle("/rejection-reasons/{code}", middleware.AuthMiddleware("manage_rejection_reasons")(http.HandlerFunc(reasonHandler.UpdateReason))).Methods("PUT")
+
+	// analytics routes
+	router.Handle("/analytics/jobs/{id}/funnel", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(analyticsHandler.GetJobFunnel))).Methods("GET")
+	router.Handle("/analytics/companies/{id}/funnel", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(analyticsHandler.GetCompanyFunnel))).Methods("GET")
+	router.Handle("/analytics/jobs/{id}/rejections", middleware.AuthMiddleware("view_applications")(http.HandlerFunc(analyticsHandler.GetJobRejections))).Methods("GET")
+
 	corsRouter := middleware.CORSMiddleware(router)
 
 	log.Println("Server started on port 8080...")
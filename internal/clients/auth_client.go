@@ -32,8 +32,25 @@ type UserLoginResponse struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
-// ValidateToken validates a token and checks if the user is authorized for a given action
+// ValidateToken validates a token and checks if the user is authorized for a given action.
+// The user must belong to an organization.
 func ValidateToken(token string, action string) (*UserResponse, error) {
+	userResp, err := ValidateCandidateToken(token, action)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user has an organization
+	if userResp.Org == nil {
+		return nil, errors.New("user does not have an associated organization")
+	}
+
+	return userResp, nil
+}
+
+// ValidateCandidateToken validates a token and checks if the user is authorized for a
+// given action without requiring an organization, for endpoints candidates call
+func ValidateCandidateToken(token string, action string) (*UserResponse, error) {
 	// Get auth service URL from environment variable
 	authServiceURL := os.Getenv("AUTH_SERVICE_URL")
 	if authServiceURL == "" {
@@ -89,11 +106,6 @@ func ValidateToken(token string, action string) (*UserResponse, error) {
 		return nil, fmt.Errorf("failed to decode user response: %v", err)
 	}
 
-	// Check if user has an organization
-	if userResp.Org == nil {
-		return nil, errors.New("user does not have an associated organization")
-	}
-
 	return &userResp, nil
 }
 
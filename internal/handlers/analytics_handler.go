@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type AnalyticsHandler struct {
+	AnalyticsService services.AnalyticsService
+}
+
+// GetJobFunnel reports conversion, drop-off and time in stage for one job
+func (h *AnalyticsHandler) GetJobFunnel(w http.ResponseWriter, r *http.Request) {
+	jobID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid job ID format", http.StatusBadRequest)
+		return
+	}
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	filter, err := parseFunnelFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.AnalyticsService.GetJobFunnel(userInfo, uint(jobID), filter)
+	if err != nil {
+		writeAnalyticsError(w, err, "Failed to build funnel report")
+		return
+	}
+	writeFunnelReport(w, report)
+}
+
+// GetCompanyFunnel reports conversion, drop-off and time in stage across a company's jobs
+func (h *AnalyticsHandler) GetCompanyFunnel(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid company ID format", http.StatusBadRequest)
+		return
+	}
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	filter, err := parseFunnelFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.AnalyticsService.GetCompanyFunnel(userInfo, uint(companyID), filter)
+	if err != nil {
+		writeAnalyticsError(w, err, "Failed to build funnel report")
+		return
+	}
+	writeFunnelReport(w, report)
+}
+
+// GetJobRejections reports the reasons a job's applications were rejected for
+func (h *AnalyticsHandler) GetJobRejections(w http.ResponseWriter, r *http.Request) {
+	jobID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid job ID format", http.StatusBadRequest)
+		return
+	}
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	filter, err := parseFunnelFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.AnalyticsService.GetJobRejections(userInfo, uint(jobID), filter)
+	if err != nil {
+		writeAnalyticsError(w, err, "Failed to build rejection report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func writeFunnelReport(w http.ResponseWriter, report *models.FunnelReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func writeAnalyticsError(w http.ResponseWriter, err error, fallback string) {
+	if errors.Is(err, services.ErrAnalyticsForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	writeApplicationError(w, err, fallback)
+}
+
+// parseFunnelFilter reads the applied date range of a funnel report
+func parseFunnelFilter(r *http.Request) (models.FunnelFilter, error) {
+	query := r.URL.Query()
+	var filter models.FunnelFilter
+
+	dateParams := map[string]**time.Time{"appliedAfter": &filter.AppliedAfter, "appliedBefore": &filter.AppliedBefore}
+	for name, dest := range dateParams {
+		if value := query.Get(name); value != "" {
+			parsed, err := parseDate(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, expected RFC3339 or YYYY-MM-DD", name)
+			}
+			*dest = &parsed
+		}
+	}
+	if filter.AppliedAfter != nil && filter.AppliedBefore != nil && filter.AppliedAfter.After(*filter.AppliedBefore) {
+		return filter, fmt.Errorf("appliedAfter must not be later than appliedBefore")
+	}
+	return filter, nil
+}
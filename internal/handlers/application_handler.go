@@ -2,7 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"jobs-svc/internal/kafka"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
 	"jobs-svc/internal/services"
 	"log"
 	"net/http"
@@ -11,8 +16,6 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -22,69 +25,39 @@ type ApplicationHandler struct {
 }
 
 func (h *ApplicationHandler) CreateApplication(w http.ResponseWriter, r *http.Request) {
-	var application bson.M
+	var application models.Application
 	log.Println("Creating application..")
 	if err := json.NewDecoder(r.Body).Decode(&application); err != nil {
 		log.Printf("Error decoding request body: %v", err)
 		http.Error(w, "Invalid JSON payload", http.StatusBadRequest)
 		return
 	}
-	log.Printf("Received application data: %+v", application)
 
-	if application["applicationId"] == nil || application["applicationId"] == "" {
-		application["applicationId"] = primitive.NewObjectID().Hex()
-	}
-
-	// snake_case for mongo
-	mongoDoc := convertToSnakeCase(application)
-	log.Printf("Converted to snake_case: %+v", mongoDoc)
-
-	// validate required fields
-	if mongoDoc["job_id"] == nil || mongoDoc["candidate_id"] == nil {
-		log.Printf("Missing required fields. job_id: %v, candidate_id: %v", mongoDoc["job_id"], mongoDoc["candidate_id"])
-		http.Error(w, "JobID and CandidateID are required", http.StatusBadRequest)
-		return
-	}
-
-	// validate status struct
-	if mongoDoc["status"] == nil {
-		mongoDoc["status"] = bson.M{
-			"current_stage": "Applied",
-			"last_updated":  time.Now(),
-		}
-	} else if status, ok := mongoDoc["status"].(bson.M); ok {
-		if status["current_stage"] == nil {
-			status["current_stage"] = "Applied"
-		}
-		if status["last_updated"] == nil {
-			status["last_updated"] = time.Now()
-		}
-	}
-
-	log.Printf("Final document to be inserted: %+v", mongoDoc)
-	if err := h.ApplicationService.CreateApplication(mongoDoc); err != nil {
+	if err := h.ApplicationService.CreateApplication(&application); err != nil {
 		log.Printf("Error creating application: %v", err)
-		if err.Error() == "candidate has already applied for this job" {
+		var validationErr *models.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrJobNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, repos.ErrDuplicateApplication), errors.Is(err, services.ErrJobNotOpen), errors.Is(err, services.ErrReapplyCooldown):
 			http.Error(w, err.Error(), http.StatusConflict)
-		} else {
+		default:
 			http.Error(w, "Failed to create application", http.StatusInternalServerError)
 		}
 		return
 	}
 
-	// camelCase for res
-	response := convertToCamelCase(mongoDoc)
-	log.Printf("Converted to camelCase for Kafka: %+v", response)
-
 	// publish application to kafka
-	if err := h.KafkaPublisher.PublishApplication(response); err != nil {
+	if err := h.KafkaPublisher.PublishApplication(&application); err != nil {
 		log.Printf("Failed to publish application to Kafka: %v", err)
 		// Continue with the response even if Kafka publish fails
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(application)
 }
 
 func (h *ApplicationHandler) GetApplicationsByJobID(w http.ResponseWriter, r *http.Request) {
@@ -96,30 +69,73 @@ func (h *ApplicationHandler) GetApplicationsByJobID(w http.ResponseWriter, r *ht
 		return
 	}
 
-	applications, err := h.ApplicationService.GetApplicationsByJobID(uint(jobID))
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	opts, err := parseApplicationQueryOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.ApplicationService.GetApplicationsByJobID(userInfo, uint(jobID), opts)
+	if errors.Is(err, services.ErrJobNotFound) || errors.Is(err, services.ErrJobForbidden) {
+		writeJobError(w, err, "Failed to fetch applications")
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Initialize responses as an empty array
-	responses := make([]bson.M, 0)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
 
-	// Convert to camelCase for response
-	if applications != nil {
-		for _, app := range applications {
-			responses = append(responses, convertToCamelCase(app))
+// GetJobBoard returns a job's applications grouped by stage for a Kanban board
+func (h *ApplicationHandler) GetJobBoard(w http.ResponseWriter, r *http.Request) {
+	jobID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid job ID format", http.StatusBadRequest)
+		return
+	}
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
 		}
 	}
 
+	board, err := h.ApplicationService.GetJobBoard(userInfo, uint(jobID), limit)
+	if err != nil {
+		writeApplicationError(w, err, "Failed to fetch job board")
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(responses); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if err := json.NewEncoder(w).Encode(board); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
+// GetApplicationByID returns the public view of an application, since it needs no authentication
 func (h *ApplicationHandler) GetApplicationByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	applicationID := vars["id"]
@@ -133,18 +149,189 @@ func (h *ApplicationHandler) GetApplicationByID(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Convert to camelCase for response
-	var response bson.M
-	if application != nil {
-		response = convertToCamelCase(application)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(application.PublicView()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// stageUpdateRequest is the body of PATCH /applications/{id}/stage. Disposition is
+// required when the stage rejects the application.
+type stageUpdateRequest struct {
+	Stage       string              `json:"stage"`
+	Reason      string              `json:"reason"`
+	Disposition *models.Disposition `json:"disposition"`
+}
+
+// UpdateApplicationStage moves an application to another pipeline stage
+func (h *ApplicationHandler) UpdateApplicationStage(w http.ResponseWriter, r *http.Request) {
+	applicationID := mux.Vars(r)["id"]
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var request stageUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(request.Stage) == "" {
+		http.Error(w, "Stage is required", http.StatusBadRequest)
+		return
+	}
+
+	application, err := h.ApplicationService.MoveStage(userInfo, applicationID, request.Stage, request.Reason, request.Disposition)
+	if err != nil {
+		writeApplicationError(w, err, "Failed to update application stage")
+		return
+	}
+
+	// rejections carry a disposition and get their own event, like the bulk reject action
+	event := kafka.ApplicationEventStageChanged
+	if request.Disposition != nil {
+		event = kafka.ApplicationEventRejected
+	}
+	if err := h.KafkaPublisher.PublishApplicationEvent(event, application); err != nil {
+		log.Printf("Failed to publish %s for %s to Kafka: %v", event, application.ApplicationID, err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(application); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// bulkActionEvents is the application event published for each application a bulk action changes
+var bulkActionEvents = map[string]string{
+	models.BulkActionMoveStage:  kafka.ApplicationEventStageChanged,
+	models.BulkActionReject:     kafka.ApplicationEventRejected,
+	models.BulkActionAddTags:    kafka.ApplicationEventTagsChanged,
+	models.BulkActionRemoveTags: kafka.ApplicationEventTagsChanged,
+}
+
+// BulkUpdateApplications applies one action to many applications and reports the
+// outcome per application. Applications that fail do not stop the others.
+func (h *ApplicationHandler) BulkUpdateApplications(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
 		return
 	}
+
+	var action models.BulkAction
+	if err := json.NewDecoder(r.Body).Decode(&action); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	result, changed, err := h.ApplicationService.BulkUpdate(userInfo, &action)
+	if err != nil {
+		writeApplicationError(w, err, "Failed to update applications")
+		return
+	}
+
+	for i := range changed {
+		if err := h.KafkaPublisher.PublishApplicationEvent(bulkActionEvents[action.Action], &changed[i]); err != nil {
+			log.Printf("Failed to publish application event for %s to Kafka: %v", changed[i].ApplicationID, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// withdrawRequest is the optional body of POST /applications/{id}/withdraw
+type withdrawRequest struct {
+	Reason string `json:"reason"`
+}
+
+// WithdrawApplication lets the candidate who submitted an application retract it
+func (h *ApplicationHandler) WithdrawApplication(w http.ResponseWriter, r *http.Request) {
+	applicationID := mux.Vars(r)["id"]
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var request withdrawRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	application, err := h.ApplicationService.WithdrawApplication(userInfo, applicationID, strings.TrimSpace(request.Reason))
+	if err != nil {
+		writeApplicationError(w, err, "Failed to withdraw application")
+		return
+	}
+
+	if err := h.KafkaPublisher.PublishApplicationEvent(kafka.ApplicationEventWithdrawn, application); err != nil {
+		log.Printf("Failed to publish application withdrawal to Kafka: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(application.CandidateView()); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// SubmitScorecard records the caller's scorecard for an application and returns the updated rating
+func (h *ApplicationHandler) SubmitScorecard(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var scorecard models.Scorecard
+	if err := json.NewDecoder(r.Body).Decode(&scorecard); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.ApplicationService.SubmitScorecard(userInfo, mux.Vars(r)["id"], &scorecard)
+	if err != nil {
+		writeApplicationError(w, err, "Failed to submit scorecard")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *ApplicationHandler) GetScorecards(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	summary, err := h.ApplicationService.GetScorecards(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeApplicationError(w, err, "Failed to fetch scorecards")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
 }
 
 func (h *ApplicationHandler) GetApplicationByCandidateID(w http.ResponseWriter, r *http.Request) {
@@ -163,115 +350,109 @@ func (h *ApplicationHandler) GetApplicationByCandidateID(w http.ResponseWriter,
 		return
 	}
 
-	// Initialize responses as an empty array
-	responses := make([]bson.M, 0)
-
-	// Convert to camelCase for response
-	if applications != nil {
-		for _, app := range applications {
-			responses = append(responses, convertToCamelCase(app))
-		}
+	// Respond with an empty array rather than null, and with the public view, since this needs no authentication
+	publicViews := make([]models.Application, 0, len(applications))
+	for i := range applications {
+		publicViews = append(publicViews, *applications[i].PublicView())
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(responses); err != nil {
+	if err := json.NewEncoder(w).Encode(publicViews); err != nil {
 		log.Printf("Error encoding response: %v", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
-func convertToSnakeCase(doc bson.M) bson.M {
-	result := make(bson.M)
+// writeApplicationError maps application service errors to HTTP responses and
+// falls back to writeJobError for errors about the application's job
+func writeApplicationError(w http.ResponseWriter, err error, fallback string) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrApplicationNotFound), errors.Is(err, services.ErrPipelineNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrApplicationForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrUnknownStage), errors.Is(err, services.ErrRejectionReasonRequired), errors.Is(err, services.ErrWithdrawnStage):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidStageTransition), errors.Is(err, repos.ErrStageConflict), errors.Is(err, services.ErrApplicationClosed),
+		errors.Is(err, services.ErrNoScorecardTemplate):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeJobError(w, err, fallback)
+	}
+}
 
-	for k, v := range doc {
-		if nested, ok := v.(map[string]any); ok {
-			result[toSnakeCase(k)] = convertToSnakeCase(nested)
-			continue
-		}
+// parseApplicationQueryOptions reads cursor paging, filter and sort query params for application listings
+func parseApplicationQueryOptions(r *http.Request) (models.ApplicationQueryOptions, error) {
+	query := r.URL.Query()
+	var opts models.ApplicationQueryOptions
 
-		if arr, ok := v.([]any); ok {
-			var newArr []any
-			for _, item := range arr {
-				if nested, ok := item.(map[string]any); ok {
-					newArr = append(newArr, convertToSnakeCase(nested))
-				} else {
-					newArr = append(newArr, item)
-				}
-			}
-			result[toSnakeCase(k)] = newArr
-			continue
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return opts, fmt.Errorf("invalid limit")
 		}
-
-		result[toSnakeCase(k)] = v
+		opts.Limit = limit
 	}
 
-	return result
-}
+	opts.Stages = splitList(query["stage"])
+	opts.Tags = splitList(query["tag"])
 
-func convertToCamelCase(doc bson.M) bson.M {
-	result := make(bson.M)
-
-	for k, v := range doc {
-		switch val := v.(type) {
-		case bson.M:
-			result[toCamelCase(k)] = convertToCamelCase(val)
-		case []interface{}:
-			var newArr []interface{}
-			for _, item := range val {
-				if nested, ok := item.(bson.M); ok {
-					newArr = append(newArr, convertToCamelCase(nested))
-				} else {
-					newArr = append(newArr, item)
-				}
+	dateParams := map[string]**time.Time{"appliedAfter": &opts.AppliedAfter, "appliedBefore": &opts.AppliedBefore}
+	for name, dest := range dateParams {
+		if value := query.Get(name); value != "" {
+			parsed, err := parseDate(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s, expected RFC3339 or YYYY-MM-DD", name)
 			}
-			result[toCamelCase(k)] = newArr
-		default:
-			// Special handling for specific fields
-			switch k {
-			case "candidate_id":
-				result["candidateId"] = v
-			case "job_id":
-				result["jobId"] = v
-			case "application_id":
-				result["applicationId"] = v
-			case "resume_url":
-				result["resumeUrl"] = v
-			default:
-				result[toCamelCase(k)] = v
+			*dest = &parsed
+		}
+	}
+
+	ratingParams := map[string]**float64{"minRating": &opts.MinRating, "maxRating": &opts.MaxRating}
+	for name, dest := range ratingParams {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s", name)
 			}
+			*dest = &parsed
 		}
 	}
 
-	return result
-}
+	if sort := query.Get("sort"); sort != "" {
+		opts.SortDesc = strings.HasPrefix(sort, "-")
+		opts.SortBy = strings.TrimPrefix(sort, "-")
+		if _, ok := models.ApplicationSortFields[opts.SortBy]; !ok {
+			return opts, fmt.Errorf("invalid sort field: %s", opts.SortBy)
+		}
+	}
+	opts.Normalize()
 
-func toSnakeCase(s string) string {
-	var result strings.Builder
-	for i, r := range s {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			result.WriteByte('_')
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := models.DecodeApplicationCursor(cursor, opts)
+		if err != nil {
+			return opts, err
 		}
-		result.WriteRune(r)
+		opts.After = after
 	}
-	return strings.ToLower(result.String())
+
+	return opts, nil
 }
 
-func toCamelCase(s string) string {
-	var result strings.Builder
-	words := strings.Split(s, "_")
-	for i, word := range words {
-		if i == 0 {
-			result.WriteString(strings.ToLower(word))
-		} else {
-			if len(word) > 0 {
-				result.WriteString(strings.ToUpper(string(word[0])))
-				if len(word) > 1 {
-					result.WriteString(strings.ToLower(word[1:]))
-				}
+// splitList flattens repeated and comma-separated query values, dropping blanks
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
 			}
 		}
 	}
-	return result.String()
+	return items
 }
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type InterviewHandler struct {
+	InterviewService services.InterviewService
+}
+
+func (h *InterviewHandler) ScheduleInterview(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var interview models.Interview
+	if err := json.NewDecoder(r.Body).Decode(&interview); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.InterviewService.ScheduleInterview(userInfo, mux.Vars(r)["id"], &interview); err != nil {
+		writeInterviewError(w, err, "Failed to schedule interview")
+		return
+	}
+	writeInterview(w, http.StatusCreated, &interview)
+}
+
+// GetInterviews lists an application's interviews, earliest first
+func (h *InterviewHandler) GetInterviews(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	interviews, err := h.InterviewService.GetInterviews(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeInterviewError(w, err, "Failed to fetch interviews")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(interviews); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *InterviewHandler) GetInterview(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	interview, err := h.InterviewService.GetInterview(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeInterviewError(w, err, "Failed to fetch interview")
+		return
+	}
+	writeInterview(w, http.StatusOK, interview)
+}
+
+// RescheduleInterview replaces the slot, interviewers and place of an interview
+func (h *InterviewHandler) RescheduleInterview(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var update models.Interview
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	interview, err := h.InterviewService.RescheduleInterview(userInfo, mux.Vars(r)["id"], &update)
+	if err != nil {
+		writeInterviewError(w, err, "Failed to reschedule interview")
+		return
+	}
+	writeInterview(w, http.StatusOK, interview)
+}
+
+func (h *InterviewHandler) CancelInterview(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	interview, err := h.InterviewService.CancelInterview(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeInterviewError(w, err, "Failed to cancel interview")
+		return
+	}
+	writeInterview(w, http.StatusOK, interview)
+}
+
+// GetInterviewCalendar downloads an interview as an .ics file
+func (h *InterviewHandler) GetInterviewCalendar(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	interviewID := mux.Vars(r)["id"]
+	calendar, err := h.InterviewService.GetInterviewCalendar(userInfo, interviewID)
+	if err != nil {
+		writeInterviewError(w, err, "Failed to export interview")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"interview-%s.ics\"", interviewID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(calendar)
+}
+
+func writeInterview(w http.ResponseWriter, status int, interview *models.Interview) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(interview); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// writeInterviewError maps interview service errors to HTTP responses
+func writeInterviewError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInterviewNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInterviewForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrInterviewerUnavailable), errors.Is(err, services.ErrInterviewCancelled),
+		errors.Is(err, services.ErrNotInterviewStage):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeApplicationError(w, err, fallback)
+	}
+}
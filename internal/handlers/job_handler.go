@@ -2,12 +2,18 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"jobs-svc/internal/kafka"
 	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
 	"jobs-svc/internal/services"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"jobs-svc/internal/clients"
 
@@ -19,31 +25,65 @@ type JobHandler struct {
 	KafkaPublisher kafka.PublisherInterface
 }
 
+// jobUpdateRequest is a PUT body. Status shadows the embedded job's status so an
+// omitted status can be told apart from Open.
+type jobUpdateRequest struct {
+	models.Job
+	Status *models.JobStatus `json:"status"`
+}
+
 type JobResponse struct {
 	models.Job
 	DaysPostedAgo int `json:"daysPostedAgo"`
 }
 
 func (h *JobHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
-	jobs, err := h.JobService.GetJobs()
+	opts, err := parseJobQueryOptions(r)
 	if err != nil {
-		http.Error(w, "Failed to fetch jobs", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Initialize response as an empty array
-	response := make([]models.Job, 0)
-	if jobs != nil {
-		response = *jobs
+	page, err := h.JobService.GetJobs(opts)
+	if err != nil {
+		http.Error(w, "Failed to fetch jobs", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := json.NewEncoder(w).Encode(page); err != nil {
 		http.Error(w, "Failed to encode jobs response", http.StatusInternalServerError)
 	}
 }
 
+func (h *JobHandler) SearchJobs(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Search query is required", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseJobQueryOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.JobService.SearchJobs(query, opts)
+	if err != nil {
+		log.Printf("Failed to search jobs: %v", err)
+		http.Error(w, "Failed to search jobs", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		http.Error(w, "Failed to encode search response", http.StatusInternalServerError)
+	}
+}
+
 func (h *JobHandler) GetJobsByRecruiterID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	recruiterID := vars["id"]
@@ -94,12 +134,20 @@ func (h *JobHandler) GetJobByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// drafts and archived jobs are not public
 	job, err := h.JobService.GetJobByID(uint(jobID))
-	if err != nil {
+	if err != nil || job == nil || !job.Status.IsListed() {
 		http.Error(w, "Job not found", http.StatusNotFound)
 		return
 	}
 
+	etag := job.ETag()
+	w.Header().Set("ETag", etag)
+	if matchesIfNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	// calculate and add days posted ago field
 	response := map[string]interface{}{
 		"id":               job.ID,
@@ -117,6 +165,7 @@ func (h *JobHandler) GetJobByID(w http.ResponseWriter, r *http.Request) {
 		"recruiterId":      job.RecruiterId,
 		"daysPostedAgo":    job.DaysPostedAgo(),
 		"benefitsAndPerks": job.BenefitsAndPerks,
+		"version":          job.Version,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -133,8 +182,8 @@ func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user info from context (set by auth middleware)
-	userInfo, ok := r.Context().Value("userInfo").(*clients.UserResponse)
-	if !ok || userInfo == nil {
+	userInfo, ok := userFromContext(r)
+	if !ok {
 		http.Error(w, "User information not found", http.StatusUnauthorized)
 		return
 	}
@@ -149,8 +198,8 @@ func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
 	// Set the company ID from the auth service response
 	job.CompanyID = uint(companyID)
 
-	if err := h.JobService.CreateJob(&job); err != nil {
-		http.Error(w, "Failed to create job", http.StatusInternalServerError)
+	if err := h.JobService.CreateJob(userInfo, &job); err != nil {
+		writeJobError(w, err, "Failed to create job")
 		return
 	}
 
@@ -182,18 +231,131 @@ func (h *JobHandler) UpdateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var job models.Job
-	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	expectedVersion, err := requireIfMatch(r)
+	if err != nil {
+		writeJobError(w, err, "Failed to update job")
+		return
+	}
+
+	var body jobUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
+	job := body.Job
 	job.ID = uint(jobID)
-	if err := h.JobService.UpdateJob(&job); err != nil {
-		http.Error(w, "Failed to update job", http.StatusInternalServerError)
+	if err := h.JobService.UpdateJob(userInfo, &job, body.Status, expectedVersion); err != nil {
+		writeJobError(w, err, "Failed to update job")
+		return
+	}
+
+	w.Header().Set("ETag", job.ETag())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// PatchJob applies a JSON merge patch (RFC 7396) to a job
+func (h *JobHandler) PatchJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid Job ID", http.StatusBadRequest)
+		return
+	}
+
+	contentType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0])
+	if contentType != "" && contentType != "application/merge-patch+json" && contentType != "application/json" {
+		http.Error(w, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	expectedVersion, err := requireIfMatch(r)
+	if err != nil {
+		writeJobError(w, err, "Failed to update job")
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	job, changed, err := h.JobService.PatchJob(userInfo, uint(jobID), patch, expectedVersion)
+	if err != nil {
+		writeJobError(w, err, "Failed to update job")
 		return
 	}
 
+	// republish the job only when the patch changed it
+	if changed {
+		if err := h.KafkaPublisher.PublishJob(job); err != nil {
+			log.Printf("Failed to publish job to Kafka: %v", err)
+		}
+	}
+
+	w.Header().Set("ETag", job.ETag())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *JobHandler) PublishJob(w http.ResponseWriter, r *http.Request) {
+	h.transitionJob(w, r, models.Open)
+}
+
+func (h *JobHandler) PauseJob(w http.ResponseWriter, r *http.Request) {
+	h.transitionJob(w, r, models.Paused)
+}
+
+func (h *JobHandler) CloseJob(w http.ResponseWriter, r *http.Request) {
+	h.transitionJob(w, r, models.Closed)
+}
+
+func (h *JobHandler) transitionJob(w http.ResponseWriter, r *http.Request, target models.JobStatus) {
+	vars := mux.Vars(r)
+	jobID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid Job ID", http.StatusBadRequest)
+		return
+	}
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	job, err := h.JobService.TransitionJob(userInfo, uint(jobID), target)
+	if err != nil {
+		writeJobError(w, err, "Failed to update job status")
+		return
+	}
+
+	// publish status change to kafka
+	if err := h.KafkaPublisher.PublishJob(job); err != nil {
+		log.Printf("Failed to publish job to Kafka: %v", err)
+	}
+
+	w.Header().Set("ETag", job.ETag())
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(job); err != nil {
@@ -215,15 +377,168 @@ func (h *JobHandler) DeleteJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.JobService.DeleteJob(uint(jobID)); err != nil {
-		http.Error(w, "Failed to delete job", http.StatusInternalServerError)
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
 		return
 	}
 
+	job, err := h.JobService.DeleteJob(userInfo, uint(jobID))
+	if err != nil {
+		writeJobError(w, err, "Failed to delete job")
+		return
+	}
+
+	if err := h.KafkaPublisher.PublishJobEvent(kafka.JobEventDeleted, job); err != nil {
+		log.Printf("Failed to publish job deletion to Kafka: %v", err)
+	}
+
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Job deleted successfully"))
 }
 
+// GetDeletedJobs lists the caller's company's soft-deleted jobs
+func (h *JobHandler) GetDeletedJobs(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	jobs, err := h.JobService.GetDeletedJobs(userInfo)
+	if err != nil {
+		writeJobError(w, err, "Failed to fetch deleted jobs")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(jobs); err != nil {
+		http.Error(w, "Failed to encode jobs response", http.StatusInternalServerError)
+	}
+}
+
+func (h *JobHandler) RestoreJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid Job ID", http.StatusBadRequest)
+		return
+	}
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	job, err := h.JobService.RestoreJob(userInfo, uint(jobID))
+	if err != nil {
+		writeJobError(w, err, "Failed to restore job")
+		return
+	}
+
+	if err := h.KafkaPublisher.PublishJobEvent(kafka.JobEventRestored, job); err != nil {
+		log.Printf("Failed to publish job restore to Kafka: %v", err)
+	}
+
+	w.Header().Set("ETag", job.ETag())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// PurgeDeletedJobs hard-deletes jobs soft-deleted longer ago than the configured retention
+func (h *JobHandler) PurgeDeletedJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.JobService.PurgeDeletedJobs()
+	if err != nil {
+		writeJobError(w, err, "Failed to purge deleted jobs")
+		return
+	}
+
+	jobIDs := make([]uint, len(jobs))
+	for i := range jobs {
+		jobIDs[i] = jobs[i].ID
+		if err := h.KafkaPublisher.PublishJobEvent(kafka.JobEventPurged, &jobs[i]); err != nil {
+			log.Printf("Failed to publish job purge to Kafka: %v", err)
+		}
+	}
+
+	response := map[string]interface{}{
+		"purged": len(jobs),
+		"jobIds": jobIDs,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetJobRevisions lists the edit history of a job, newest first
+func (h *JobHandler) GetJobRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid Job ID", http.StatusBadRequest)
+		return
+	}
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	revisions, err := h.JobService.GetJobRevisions(userInfo, uint(jobID))
+	if err != nil {
+		writeJobError(w, err, "Failed to fetch job revisions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(revisions); err != nil {
+		http.Error(w, "Failed to encode revisions response", http.StatusInternalServerError)
+	}
+}
+
+// GetJobRevisionDiff returns the field changes a revision made to the previous one
+func (h *JobHandler) GetJobRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid Job ID", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := strconv.ParseUint(vars["rev"], 10, 32)
+	if err != nil || revision == 0 {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	diff, err := h.JobService.DiffJobRevision(userInfo, uint(jobID), uint(revision))
+	if err != nil {
+		writeJobError(w, err, "Failed to diff job revision")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(diff); err != nil {
+		http.Error(w, "Failed to encode diff response", http.StatusInternalServerError)
+	}
+}
+
 func (h *JobHandler) GetJobsByIDs(w http.ResponseWriter, r *http.Request) {
 	var jobIDs []string
 	if err := json.NewDecoder(r.Body).Decode(&jobIDs); err != nil {
@@ -280,3 +595,147 @@ func (h *JobHandler) GetJobsByIDs(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to encode jobs response", http.StatusInternalServerError)
 	}
 }
+
+var errMissingIfMatch = errors.New("If-Match header is required")
+
+// requireIfMatch reads the expected job version from the If-Match header.
+// "*" matches any version and is returned as 0.
+func requireIfMatch(r *http.Request) (uint, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, errMissingIfMatch
+	}
+	if header == "*" {
+		return 0, nil
+	}
+	version, err := strconv.ParseUint(strings.Trim(strings.TrimPrefix(header, "W/"), `"`), 10, 32)
+	if err != nil || version == 0 {
+		return 0, repos.ErrJobVersionConflict
+	}
+	return uint(version), nil
+}
+
+// matchesIfNoneMatch reports whether the If-None-Match header matches etag, using weak comparison
+func matchesIfNoneMatch(r *http.Request, etag string) bool {
+	header := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// userFromContext returns the user the auth middleware stored on the request
+func userFromContext(r *http.Request) (*clients.UserResponse, bool) {
+	userInfo, ok := r.Context().Value("userInfo").(*clients.UserResponse)
+	return userInfo, ok && userInfo != nil
+}
+
+// writeJobError maps job service errors to HTTP responses
+func writeJobError(w http.ResponseWriter, err error, fallback string) {
+	var immutableErr *services.ImmutableFieldError
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &immutableErr), errors.As(err, &validationErr):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidPatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrJobNotFound), errors.Is(err, services.ErrJobRevisionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrJobForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrInvalidStatusTransition), errors.Is(err, services.ErrJobPipelineLocked):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrUnknownPipeline):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidInitialStatus):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, errMissingIfMatch):
+		http.Error(w, err.Error(), http.StatusPreconditionRequired)
+	case errors.Is(err, repos.ErrJobVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	default:
+		log.Printf("%s: %v", fallback, err)
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+// parseJobQueryOptions reads paging, filter and sort query params for job listings
+func parseJobQueryOptions(r *http.Request) (models.JobQueryOptions, error) {
+	query := r.URL.Query()
+	var opts models.JobQueryOptions
+
+	intParams := map[string]*int{"page": &opts.Page, "limit": &opts.Limit}
+	for name, dest := range intParams {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				return opts, fmt.Errorf("invalid %s", name)
+			}
+			*dest = parsed
+		}
+	}
+
+	idParams := map[string]*uint{"companyId": &opts.CompanyID, "recruiterId": &opts.RecruiterID}
+	for name, dest := range idParams {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s", name)
+			}
+			*dest = uint(parsed)
+		}
+	}
+
+	// these routes are public, so only listed jobs are returned
+	if value := query.Get("status"); value != "" {
+		status, err := models.ParseJobStatus(value)
+		if err != nil {
+			return opts, err
+		}
+		if !status.IsListed() {
+			return opts, fmt.Errorf("%s jobs are not listed", status)
+		}
+		opts.Status = &status
+	} else {
+		opts.Statuses = models.ListedJobStatuses
+	}
+
+	opts.Location = query.Get("location")
+	opts.Company = query.Get("company")
+
+	dateParams := map[string]**time.Time{"postedAfter": &opts.PostedAfter, "postedBefore": &opts.PostedBefore}
+	for name, dest := range dateParams {
+		if value := query.Get(name); value != "" {
+			parsed, err := parseDate(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s, expected RFC3339 or YYYY-MM-DD", name)
+			}
+			*dest = &parsed
+		}
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		opts.SortDesc = strings.HasPrefix(sort, "-")
+		opts.SortBy = strings.TrimPrefix(sort, "-")
+		if _, ok := models.JobSortFields[opts.SortBy]; !ok {
+			return opts, fmt.Errorf("invalid sort field: %s", opts.SortBy)
+		}
+	}
+
+	return opts, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
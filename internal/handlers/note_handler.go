@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"jobs-svc/internal/kafka"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type NoteHandler struct {
+	NoteService    services.NoteService
+	KafkaPublisher kafka.PublisherInterface
+}
+
+// noteRequest is the body of note create and update requests
+type noteRequest struct {
+	Body       string `json:"body"`
+	Visibility string `json:"visibility"`
+}
+
+func (h *NoteHandler) CreateNote(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var request noteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	note := models.Note{Body: request.Body, Visibility: request.Visibility}
+	if err := h.NoteService.CreateNote(userInfo, mux.Vars(r)["id"], &note); err != nil {
+		writeNoteError(w, err, "Failed to create note")
+		return
+	}
+	h.publishMentions(&note, note.Mentions)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(note); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetNotes lists an application's team notes and the caller's private notes
+func (h *NoteHandler) GetNotes(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	notes, err := h.NoteService.GetNotes(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeNoteError(w, err, "Failed to fetch notes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(notes); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// UpdateNote replaces the body and visibility of one of the caller's notes. Only users
+// the note did not mention before are notified.
+func (h *NoteHandler) UpdateNote(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var request noteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	update := models.Note{Body: request.Body, Visibility: request.Visibility}
+	note, mentioned, err := h.NoteService.UpdateNote(userInfo, vars["id"], vars["noteId"], &update)
+	if err != nil {
+		writeNoteError(w, err, "Failed to update note")
+		return
+	}
+	h.publishMentions(note, mentioned)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(note); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *NoteHandler) DeleteNote(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	if err := h.NoteService.DeleteNote(userInfo, vars["id"], vars["noteId"]); err != nil {
+		writeNoteError(w, err, "Failed to delete note")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Note deleted successfully"))
+}
+
+// publishMentions sends one notification per mentioned user. The note is already saved,
+// so failures are logged rather than failing the request.
+func (h *NoteHandler) publishMentions(note *models.Note, userIDs []uint) {
+	for _, userID := range userIDs {
+		if err := h.KafkaPublisher.PublishNoteMention(note, userID); err != nil {
+			log.Printf("Failed to publish mention of user %d in note %s to Kafka: %v", userID, note.ID, err)
+		}
+	}
+}
+
+// writeNoteError maps note service errors to HTTP responses
+func writeNoteError(w http.ResponseWriter, err error, fallback string) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrNoteNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrNoteForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		writeApplicationError(w, err, fallback)
+	}
+}
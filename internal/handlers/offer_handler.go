@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"jobs-svc/internal/kafka"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"jobs-svc/internal/services"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type OfferHandler struct {
+	OfferService   services.OfferService
+	KafkaPublisher kafka.PublisherInterface
+}
+
+// CreateOffer drafts an offer for an application
+func (h *OfferHandler) CreateOffer(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var offer models.Offer
+	if err := json.NewDecoder(r.Body).Decode(&offer); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.OfferService.CreateOffer(userInfo, mux.Vars(r)["id"], &offer); err != nil {
+		writeOfferError(w, err, "Failed to create offer")
+		return
+	}
+	h.publishOfferEvent(kafka.OfferEventCreated, &offer)
+	writeOffer(w, http.StatusCreated, &offer)
+}
+
+// GetOffers lists an application's offers, oldest first
+func (h *OfferHandler) GetOffers(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	offers, err := h.OfferService.GetOffers(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeOfferError(w, err, "Failed to fetch offers")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(offers); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *OfferHandler) GetOffer(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	offer, err := h.OfferService.GetOffer(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeOfferError(w, err, "Failed to fetch offer")
+		return
+	}
+	writeOffer(w, http.StatusOK, offer)
+}
+
+// UpdateOffer replaces the terms of a draft or pending offer
+func (h *OfferHandler) UpdateOffer(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var update models.Offer
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	offer, err := h.OfferService.UpdateOffer(userInfo, mux.Vars(r)["id"], &update)
+	if err != nil {
+		writeOfferError(w, err, "Failed to update offer")
+		return
+	}
+	h.publishOfferEvent(kafka.OfferEventUpdated, offer)
+	writeOffer(w, http.StatusOK, offer)
+}
+
+// SubmitOffer sends a draft offer for approval
+func (h *OfferHandler) SubmitOffer(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	offer, err := h.OfferService.SubmitOffer(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeOfferError(w, err, "Failed to submit offer")
+		return
+	}
+	h.publishOfferEvent(kafka.OfferEventSubmitted, offer)
+	writeOffer(w, http.StatusOK, offer)
+}
+
+// ExtendOffer approves a pending offer and extends it to the candidate
+func (h *OfferHandler) ExtendOffer(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	offer, application, err := h.OfferService.ExtendOffer(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeOfferError(w, err, "Failed to extend offer")
+		return
+	}
+	h.publishOfferEvent(kafka.OfferEventExtended, offer)
+	h.publishApplicationEvent(kafka.ApplicationEventStageChanged, application)
+	writeOffer(w, http.StatusOK, offer)
+}
+
+// AcceptOffer lets the candidate accept an extended offer
+func (h *OfferHandler) AcceptOffer(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	offer, application, err := h.OfferService.AcceptOffer(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writeOfferError(w, err, "Failed to accept offer")
+		return
+	}
+	h.publishOfferEvent(kafka.OfferEventAccepted, offer)
+	h.publishApplicationEvent(kafka.ApplicationEventStageChanged, application)
+	writeOffer(w, http.StatusOK, offer)
+}
+
+// declineRequest is the optional body of POST /offers/{id}/decline
+type declineRequest struct {
+	Reason string `json:"reason"`
+}
+
+// DeclineOffer lets the candidate decline an extended offer, withdrawing the application
+func (h *OfferHandler) DeclineOffer(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var request declineRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	offer, application, err := h.OfferService.DeclineOffer(userInfo, mux.Vars(r)["id"], strings.TrimSpace(request.Reason))
+	if err != nil {
+		writeOfferError(w, err, "Failed to decline offer")
+		return
+	}
+	h.publishOfferEvent(kafka.OfferEventDeclined, offer)
+	h.publishApplicationEvent(kafka.ApplicationEventWithdrawn, application)
+	writeOffer(w, http.StatusOK, offer)
+}
+
+// ExpireOffers expires the extended offers past their expiry at now and publishes an
+// event for each. The offer sweeper calls it periodically.
+func (h *OfferHandler) ExpireOffers(now time.Time) {
+	offers, err := h.OfferService.ExpireOffers(now)
+	if err != nil {
+		log.Printf("Failed to expire offers: %v", err)
+	}
+	for i := range offers {
+		h.publishOfferEvent(kafka.OfferEventExpired, &offers[i])
+	}
+	if len(offers) > 0 {
+		log.Printf("Expired %d offers", len(offers))
+	}
+}
+
+func (h *OfferHandler) publishOfferEvent(eventType string, offer *models.Offer) {
+	if err := h.KafkaPublisher.PublishOfferEvent(eventType, offer); err != nil {
+		log.Printf("Failed to publish %s for offer %s to Kafka: %v", eventType, offer.ID, err)
+	}
+}
+
+// publishApplicationEvent publishes an event for an application an offer moved. It
+// does nothing when application is nil because the application did not change stage.
+func (h *OfferHandler) publishApplicationEvent(eventType string, application *models.Application) {
+	if application == nil {
+		return
+	}
+	if err := h.KafkaPublisher.PublishApplicationEvent(eventType, application); err != nil {
+		log.Printf("Failed to publish %s for %s to Kafka: %v", eventType, application.ApplicationID, err)
+	}
+}
+
+func writeOffer(w http.ResponseWriter, status int, offer *models.Offer) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(offer); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// writeOfferError maps offer service errors to HTTP responses
+func writeOfferError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrOfferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrOfferForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrOfferExists), errors.Is(err, services.ErrOfferNotEditable),
+		errors.Is(err, services.ErrOfferExpired), errors.Is(err, services.ErrInvalidOfferTransition),
+		errors.Is(err, repos.ErrOfferConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeApplicationError(w, err, fallback)
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"jobs-svc/internal/services"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type PipelineHandler struct {
+	PipelineService services.PipelineService
+}
+
+func (h *PipelineHandler) CreatePipeline(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var pipeline models.Pipeline
+	if err := json.NewDecoder(r.Body).Decode(&pipeline); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.PipelineService.CreatePipeline(userInfo, &pipeline); err != nil {
+		writePipelineError(w, err, "Failed to create pipeline")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(pipeline); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetPipelines lists the pipelines of the caller's company
+func (h *PipelineHandler) GetPipelines(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	pipelines, err := h.PipelineService.GetPipelines(userInfo)
+	if err != nil {
+		writePipelineError(w, err, "Failed to fetch pipelines")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(pipelines); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetDefaultPipeline returns the pipeline used by jobs that do not reference one
+func (h *PipelineHandler) GetDefaultPipeline(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(models.DefaultPipeline); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *PipelineHandler) GetPipelineByID(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	pipeline, err := h.PipelineService.GetPipeline(userInfo, mux.Vars(r)["id"])
+	if err != nil {
+		writePipelineError(w, err, "Failed to fetch pipeline")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(pipeline); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *PipelineHandler) UpdatePipeline(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var pipeline models.Pipeline
+	if err := json.NewDecoder(r.Body).Decode(&pipeline); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.PipelineService.UpdatePipeline(userInfo, mux.Vars(r)["id"], &pipeline)
+	if err != nil {
+		writePipelineError(w, err, "Failed to update pipeline")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *PipelineHandler) DeletePipeline(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.PipelineService.DeletePipeline(userInfo, mux.Vars(r)["id"]); err != nil {
+		writePipelineError(w, err, "Failed to delete pipeline")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Pipeline deleted successfully"))
+}
+
+// writePipelineError maps pipeline service errors to HTTP responses
+func writePipelineError(w http.ResponseWriter, err error, fallback string) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrPipelineNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrPipelineForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrPipelineInUse), errors.Is(err, repos.ErrDuplicatePipeline):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("%s: %v", fallback, err)
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"jobs-svc/internal/services"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type RejectionReasonHandler struct {
+	RejectionReasonService services.RejectionReasonService
+}
+
+// GetReasons lists the rejection reason catalog of the caller's company
+func (h *RejectionReasonHandler) GetReasons(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	reasons, err := h.RejectionReasonService.GetReasons(userInfo)
+	if err != nil {
+		writeRejectionReasonError(w, err, "Failed to fetch rejection reasons")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(reasons); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *RejectionReasonHandler) CreateReason(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var reason models.RejectionReason
+	if err := json.NewDecoder(r.Body).Decode(&reason); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.RejectionReasonService.CreateReason(userInfo, &reason); err != nil {
+		writeRejectionReasonError(w, err, "Failed to create rejection reason")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(reason); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// UpdateReason relabels or archives a rejection reason. The code in the path is kept.
+func (h *RejectionReasonHandler) UpdateReason(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	var update models.RejectionReason
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	reason, err := h.RejectionReasonService.UpdateReason(userInfo, mux.Vars(r)["code"], &update)
+	if err != nil {
+		writeRejectionReasonError(w, err, "Failed to update rejection reason")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(reason); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func writeRejectionReasonError(w http.ResponseWriter, err error, fallback string) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrRejectionReasonNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrRejectionReasonForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, repos.ErrDuplicateRejectionReason):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("%s: %v", fallback, err)
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
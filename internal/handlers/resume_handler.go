@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"jobs-svc/internal/kafka"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// maxResumeRequestSize leaves room for the multipart framing around a resume of MaxResumeSize
+const maxResumeRequestSize = models.MaxResumeSize + 1<<20
+
+type ResumeHandler struct {
+	ResumeService  services.ResumeService
+	KafkaPublisher kafka.PublisherInterface
+}
+
+// UploadResume takes a resume as the "resume" file of a multipart form and attaches it
+// to the candidate's application
+func (h *ResumeHandler) UploadResume(w http.ResponseWriter, r *http.Request) {
+	userInfo, ok := userFromContext(r)
+	if !ok {
+		http.Error(w, "User information not found", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxResumeRequestSize)
+	file, header, err := r.FormFile("resume")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, services.ErrResumeTooLarge.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Request must be a multipart form with a resume file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// one byte past the limit is enough to tell the file is too large
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxResumeSize+1))
+	if err != nil {
+		http.Error(w, "Failed to read resume", http.StatusBadRequest)
+		return
+	}
+
+	application, changed, err := h.ResumeService.UploadResume(userInfo, mux.Vars(r)["id"], header.Filename, data)
+	if err != nil {
+		writeResumeError(w, err, "Failed to upload resume")
+		return
+	}
+	if changed {
+		if err := h.KafkaPublisher.PublishApplicationEvent(kafka.ApplicationEventResumeUploaded, application); err != nil {
+			log.Printf("Failed to publish resume upload to Kafka: %v", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(application.CandidateView()); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// DownloadResume serves an uploaded resume to anyone holding a valid signed link
+func (h *ResumeHandler) DownloadResume(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	file, resume, err := h.ResumeService.OpenResume(mux.Vars(r)["id"], query.Get("expires"), query.Get("signature"))
+	if err != nil {
+		writeResumeError(w, err, "Failed to download resume")
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", resume.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(resume.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resume.FileName))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("Failed to send resume: %v", err)
+	}
+}
+
+func writeResumeError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrResumeTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, services.ErrResumeLinkInvalid):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrResumeLinkExpired):
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		writeApplicationError(w, err, fallback)
+	}
+}
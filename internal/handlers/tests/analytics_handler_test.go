@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"jobs-svc/internal/tests"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestAnalyticsRouter(handler *handlers.AnalyticsHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/analytics/jobs/{id}/funnel", handler.GetJobFunnel).Methods("GET")
+	router.HandleFunc("/analytics/companies/{id}/funnel", handler.GetCompanyFunnel).Methods("GET")
+	return router
+}
+
+func TestAnalyticsHandler_Funnel(t *testing.T) {
+	appRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open})
+	jobRepo.CreateJob(&models.Job{Title: "Designer", CompanyID: 1, Status: models.Open})
+	handler := handlers.AnalyticsHandler{
+		AnalyticsService: services.AnalyticsService{
+			AnalyticsRepo: NewMockAnalyticsRepo(appRepo),
+			JobRepo:       jobRepo,
+		},
+	}
+	router := setupTestAnalyticsRouter(&handler)
+
+	// seed applies at a date and moves through stages, each entered the given hours after applying
+	type move struct {
+		stage string
+		hours int
+	}
+	candidates := 0
+	companyID := models.NumericID(1)
+	seed := func(id string, jobID uint, appliedAt time.Time, moves ...move) {
+		candidates++
+		app := &models.Application{ApplicationID: id, JobID: models.NumericID(jobID), CandidateID: models.NumericID(candidates), CompanyID: companyID, Active: true, AppliedAt: appliedAt}
+		from := ""
+		for _, m := range moves {
+			app.StageHistory = append(app.StageHistory, models.StageChange{From: from, To: m.stage, ChangedAt: appliedAt.Add(time.Duration(m.hours) * time.Hour)})
+			from = m.stage
+		}
+		app.Status = models.Status{CurrentStage: from}
+		appRepo.CreateApplication(app)
+	}
+	may := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	seed("a1", 1, may, move{"Applied", 0}, move{"Screening", 24}, move{"Interview", 72}, move{"Offer", 120}, move{"Hired", 144})
+	seed("a2", 1, may, move{"Applied", 0}, move{"Screening", 48}, move{"Rejected", 96})
+	seed("a3", 1, may, move{"Applied", 0}, move{"Withdrawn", 12})
+	// a4 predates applications being stamped with their job's company
+	companyID = 0
+	seed("a4", 1, may, move{"Applied", 0})
+	companyID = 1
+	seed("a5", 2, may.AddDate(0, 1, 0), move{"Applied", 0}, move{"Screening", 24})
+
+	get := func(t *testing.T, url string, orgID int) (int, models.FunnelReport) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", url, nil), orgID))
+		var report models.FunnelReport
+		if rr.Code == http.StatusOK {
+			if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+				t.Fatalf("Failed to decode report: %v", err)
+			}
+		}
+		return rr.Code, report
+	}
+	// describe flattens a stage to entered/current/advanced/droppedOff/median hours
+	describe := func(stage models.FunnelStage) string {
+		median := "-"
+		if stage.MedianHoursInStage != nil {
+			median = fmt.Sprint(*stage.MedianHoursInStage)
+		}
+		return fmt.Sprintf("%s %d/%d/%d/%d %s", stage.Stage, stage.Entered, stage.Current, stage.Advanced, stage.DroppedOff, median)
+	}
+
+	code, report := get(t, "/analytics/jobs/1/funnel", 1)
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v", code)
+	}
+	want := []string{
+		"Applied 4/1/2/1 24",
+		"Screening 2/0/1/1 48",
+		"Interview 1/0/1/0 48",
+		"Offer 1/0/1/0 24",
+		"Hired 1/1/0/0 -",
+		"Rejected 1/1/0/0 -",
+		"Withdrawn 1/1/0/0 -",
+		"Job Closed 0/0/0/0 -",
+	}
+	if len(report.Stages) != len(want) {
+		t.Fatalf("Expected %d stages, got %d", len(want), len(report.Stages))
+	}
+	for i, stage := range report.Stages {
+		if got := describe(stage); got != want[i] {
+			t.Errorf("stage %d: got %q want %q", i, got, want[i])
+		}
+	}
+	if report.Stages[0].ConversionRate != 0.5 || report.Stages[0].DropOffRate != 0.25 {
+		t.Errorf("Unexpected Applied rates: %v, %v", report.Stages[0].ConversionRate, report.Stages[0].DropOffRate)
+	}
+	if report.Applications != 4 || report.Hired != 1 || report.HireRate != 0.25 {
+		t.Errorf("Unexpected totals: %d applications, %d hired, rate %v", report.Applications, report.Hired, report.HireRate)
+	}
+	if report.MedianTimeToHireHours == nil || *report.MedianTimeToHireHours != 144 {
+		t.Errorf("Expected time to hire of 144 hours, got %v", report.MedianTimeToHireHours)
+	}
+
+	code, report = get(t, "/analytics/companies/1/funnel", 1)
+	if code != http.StatusOK || report.Applications != 4 || report.Stages[1].Entered != 3 {
+		t.Errorf("Expected the company report to cover both jobs, got %v: %+v", code, report)
+	}
+	code, report = get(t, "/analytics/companies/1/funnel?appliedAfter=2024-06-01", 1)
+	if code != http.StatusOK || report.Applications != 1 || report.Hired != 0 || report.MedianTimeToHireHours != nil {
+		t.Errorf("Expected only June applications, got %v: %+v", code, report)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		url    string
+		orgID  int
+		status int
+	}{
+		{"job of another company", "/analytics/jobs/1/funnel", 2, http.StatusForbidden},
+		{"unknown job", "/analytics/jobs/99/funnel", 1, http.StatusNotFound},
+		{"another company", "/analytics/companies/1/funnel", 2, http.StatusForbidden},
+		{"invalid date", "/analytics/jobs/1/funnel?appliedBefore=yesterday", 1, http.StatusBadRequest},
+		{"inverted range", "/analytics/jobs/1/funnel?appliedAfter=2024-06-01&appliedBefore=2024-05-01", 1, http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _ := get(t, tt.url, tt.orgID); code != tt.status {
+				t.Errorf("Expected %v, got %v", tt.status, code)
+			}
+		})
+	}
+}
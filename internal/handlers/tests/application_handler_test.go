@@ -2,11 +2,20 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"jobs-svc/internal/clients"
 	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/kafka"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
 	"jobs-svc/internal/services"
+	"jobs-svc/internal/tests"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,8 +26,14 @@ import (
 func setupTestApplicationRouter(handler *handlers.ApplicationHandler) *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/applications", handler.CreateApplication).Methods("POST")
+	router.HandleFunc("/applications/bulk", handler.BulkUpdateApplications).Methods("POST")
+	router.HandleFunc("/jobs/{id}/board", handler.GetJobBoard).Methods("GET")
 	router.HandleFunc("/applications/job/{id}", handler.GetApplicationsByJobID).Methods("GET")
 	router.HandleFunc("/applications/{id}", handler.GetApplicationByID).Methods("GET")
+	router.HandleFunc("/applications/{id}/stage", handler.UpdateApplicationStage).Methods("PATCH")
+	router.HandleFunc("/applications/{id}/withdraw", handler.WithdrawApplication).Methods("POST")
+	router.HandleFunc("/applications/{id}/scorecards", handler.SubmitScorecard).Methods("POST")
+	router.HandleFunc("/applications/{id}/scorecards", handler.GetScorecards).Methods("GET")
 	router.HandleFunc("/applications/candidate/{id}", handler.GetApplicationByCandidateID).Methods("GET")
 	return router
 }
@@ -26,13 +41,16 @@ func setupTestApplicationRouter(handler *handlers.ApplicationHandler) *mux.Route
 func TestApplicationHandler_CreateApplication(t *testing.T) {
 	mockRepo := NewMockApplicationRepo()
 	mockKafka := NewMockKafkaPublisher()
-	service := services.ApplicationsService{AppRepo: mockRepo}
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Open Job", CompanyID: 1, Status: models.Open})
+	jobRepo.CreateJob(&models.Job{Title: "Closed Job", CompanyID: 1, Status: models.Closed})
+	service := services.ApplicationsService{AppRepo: mockRepo, JobRepo: jobRepo}
 	handler := handlers.ApplicationHandler{
 		ApplicationService: service,
 		KafkaPublisher:     mockKafka,
 	}
 
-	tests := []struct {
+	cases := []struct {
 		name           string
 		payload        bson.M
 		expectedStatus int
@@ -58,6 +76,9 @@ func TestApplicationHandler_CreateApplication(t *testing.T) {
 				if response["candidateId"] != float64(1) {
 					t.Errorf("Expected candidateId 1, got %v", response["candidateId"])
 				}
+				if response["companyId"] != float64(1) {
+					t.Errorf("Expected companyId of the job's company, got %v", response["companyId"])
+				}
 			},
 		},
 		{
@@ -86,9 +107,72 @@ func TestApplicationHandler_CreateApplication(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "closed job",
+			payload: bson.M{
+				"jobId":       2,
+				"candidateId": 1,
+				"resumeUrl":   "http://example.com/resume.pdf",
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "unknown job",
+			payload: bson.M{
+				"jobId":       99,
+				"candidateId": 1,
+				"resumeUrl":   "http://example.com/resume.pdf",
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "invalid job ID",
+			payload: bson.M{
+				"jobId":       "abc",
+				"candidateId": 1,
+				"resumeUrl":   "http://example.com/resume.pdf",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "string IDs",
+			payload: bson.M{
+				"jobId":       "1",
+				"candidateId": "7",
+				"resumeUrl":   "http://example.com/resume.pdf",
+			},
+			expectedStatus: http.StatusCreated,
+			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var response bson.M
+				if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+					t.Errorf("Failed to decode response: %v", err)
+				}
+				if response["jobId"] != float64(1) || response["candidateId"] != float64(7) {
+					t.Errorf("Expected numeric IDs in response, got %v and %v", response["jobId"], response["candidateId"])
+				}
+			},
+		},
+		{
+			name: "invalid resume URL",
+			payload: bson.M{
+				"jobId":       1,
+				"candidateId": 8,
+				"resumeUrl":   "not a url",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid email",
+			payload: bson.M{
+				"jobId":       1,
+				"candidateId": 9,
+				"email":       "nobody",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
-	for _, tt := range tests {
+	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
 			payload, _ := json.Marshal(tt.payload)
 			req := httptest.NewRequest("POST", "/applications", bytes.NewBuffer(payload))
@@ -109,35 +193,249 @@ func TestApplicationHandler_CreateApplication(t *testing.T) {
 	}
 }
 
+func TestApplication_DecodesLegacyDocuments(t *testing.T) {
+	lastUpdated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	documents := []bson.M{
+		{"application_id": "int", "job_id": int32(3), "candidate_id": int64(5), "resume_url": "http://example.com/a.pdf"},
+		{"application_id": "float", "job_id": float64(3), "candidate_id": float64(5), "resume_url": "http://example.com/a.pdf"},
+		{"application_id": "string", "job_id": "3", "candidate_id": "5", "resume_url": "http://example.com/a.pdf"},
+	}
+
+	for _, document := range documents {
+		document["status"] = bson.M{"current_stage": "Applied", "last_updated": lastUpdated}
+		raw, err := bson.Marshal(document)
+		if err != nil {
+			t.Fatalf("Failed to marshal document: %v", err)
+		}
+
+		var app models.Application
+		if err := bson.Unmarshal(raw, &app); err != nil {
+			t.Errorf("%s: failed to decode: %v", document["application_id"], err)
+			continue
+		}
+		if app.JobID != 3 || app.CandidateID != 5 {
+			t.Errorf("%s: expected job 3 and candidate 5, got %d and %d", app.ApplicationID, app.JobID, app.CandidateID)
+		}
+		if app.ResumeURL != "http://example.com/a.pdf" || !app.Status.LastUpdated.Equal(lastUpdated) {
+			t.Errorf("%s: unexpected fields %+v", app.ApplicationID, app)
+		}
+	}
+
+	raw, _ := bson.Marshal(bson.M{"job_id": 1.5})
+	var app models.Application
+	if err := bson.Unmarshal(raw, &app); err == nil {
+		t.Error("Expected a fractional ID to be rejected")
+	}
+}
+
+func TestApplicationHandler_UpdateApplicationStage(t *testing.T) {
+	mockRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Open Job", CompanyID: 1, Status: models.Open})
+	service := services.ApplicationsService{AppRepo: mockRepo, JobRepo: jobRepo}
+	mockKafka := NewMockKafkaPublisher()
+	handler := handlers.ApplicationHandler{
+		ApplicationService: service,
+		KafkaPublisher:     mockKafka,
+	}
+	router := setupTestApplicationRouter(&handler)
+
+	app := &models.Application{ApplicationID: "app1", JobID: 1, CandidateID: 5}
+	if err := service.CreateApplication(app); err != nil {
+		t.Fatalf("CreateApplication failed: %v", err)
+	}
+
+	cases := []struct {
+		name          string
+		body          string
+		orgID         int
+		applicationID string
+		wantCode      int
+		wantStage     string
+	}{
+		{"move to screening", `{"stage":"screening","reason":"Strong resume"}`, 1, "app1", http.StatusOK, models.StageScreening},
+		{"skip ahead to hired", `{"stage":"Hired"}`, 1, "app1", http.StatusConflict, ""},
+		{"unknown stage", `{"stage":"Banana"}`, 1, "app1", http.StatusBadRequest, ""},
+		{"withdraw for the candidate", `{"stage":"Withdrawn"}`, 1, "app1", http.StatusBadRequest, ""},
+		{"missing stage", `{}`, 1, "app1", http.StatusBadRequest, ""},
+		{"other company", `{"stage":"Interview"}`, 2, "app1", http.StatusForbidden, ""},
+		{"unknown application", `{"stage":"Interview"}`, 1, "missing", http.StatusNotFound, ""},
+		{"move to interview", `{"stage":"Interview"}`, 1, "app1", http.StatusOK, models.StageInterview},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/applications/"+tt.applicationID+"/stage", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, withUserInfo(req, tt.orgID))
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got %v want %v: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if tt.wantStage != "" {
+				var response models.Application
+				if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if response.Status.CurrentStage != tt.wantStage {
+					t.Errorf("Expected stage %s, got %s", tt.wantStage, response.Status.CurrentStage)
+				}
+			}
+		})
+	}
+
+	stored, _ := mockRepo.GetApplicationByID("app1")
+	history := stored.StageHistory
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 history entries, got %+v", history)
+	}
+	if history[0].To != models.StageApplied || history[0].ChangedBy != 5 {
+		t.Errorf("Expected the candidate's application as the first entry, got %+v", history[0])
+	}
+	if history[1].From != models.StageApplied || history[1].To != models.StageScreening || history[1].ChangedBy != 10 || history[1].Reason != "Strong resume" {
+		t.Errorf("Unexpected screening entry: %+v", history[1])
+	}
+	if history[2].From != models.StageScreening || history[2].To != models.StageInterview {
+		t.Errorf("Unexpected interview entry: %+v", history[2])
+	}
+
+	events := mockKafka.(*MockKafkaPublisher).GetPublishedApplicationEvents()
+	if len(events) != 2 {
+		t.Fatalf("Expected an event for each successful move, got %+v", events)
+	}
+	for i, want := range []string{models.StageScreening, models.StageInterview} {
+		if events[i].Event != kafka.ApplicationEventStageChanged || events[i].Application.Status.CurrentStage != want {
+			t.Errorf("Expected a stage_changed event for the move to %s, got %s to %s", want, events[i].Event, events[i].Application.Status.CurrentStage)
+		}
+	}
+}
+
+func TestDefaultPipeline_CanTransition(t *testing.T) {
+	pipeline := models.DefaultPipeline
+	allowed := [][2]string{
+		{models.StageApplied, models.StageScreening},
+		{"applied", models.StageRejected},
+		{models.StageOffer, models.StageHired},
+		{models.StageInterview, models.StageWithdrawn},
+	}
+	for _, move := range allowed {
+		if !pipeline.CanTransition(move[0], move[1]) {
+			t.Errorf("Expected %s -> %s to be allowed", move[0], move[1])
+		}
+	}
+
+	denied := [][2]string{
+		{models.StageApplied, models.StageOffer},
+		{models.StageHired, models.StageRejected},
+		{models.StageJobClosed, models.StageApplied},
+		{"Reviewed", models.StageScreening},
+	}
+	for _, move := range denied {
+		if pipeline.CanTransition(move[0], move[1]) {
+			t.Errorf("Expected %s -> %s to be rejected", move[0], move[1])
+		}
+	}
+}
+
+// withCandidate adds the user the candidate auth middleware would set for a candidate without an organization
+func withCandidate(req *http.Request, candidateID int) *http.Request {
+	userInfo := &clients.UserResponse{ID: candidateID, Email: "candidate@example.com"}
+	return req.WithContext(context.WithValue(req.Context(), "userInfo", userInfo))
+}
+
+func TestApplicationHandler_WithdrawApplication(t *testing.T) {
+	mockRepo := NewMockApplicationRepo()
+	mockKafka := NewMockKafkaPublisher()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Open Job", CompanyID: 1, Status: models.Open})
+	service := services.ApplicationsService{AppRepo: mockRepo, JobRepo: jobRepo, ReapplyCooldown: 24 * time.Hour}
+	handler := handlers.ApplicationHandler{
+		ApplicationService: service,
+		KafkaPublisher:     mockKafka,
+	}
+	router := setupTestApplicationRouter(&handler)
+
+	if err := service.CreateApplication(&models.Application{ApplicationID: "app1", JobID: 1, CandidateID: 5}); err != nil {
+		t.Fatalf("CreateApplication failed: %v", err)
+	}
+	if err := service.CreateApplication(&models.Application{ApplicationID: "again", JobID: 1, CandidateID: 5}); !errors.Is(err, repos.ErrDuplicateApplication) {
+		t.Errorf("Expected a second active application to be rejected, got %v", err)
+	}
+
+	cases := []struct {
+		name          string
+		applicationID string
+		candidateID   int
+		body          string
+		wantCode      int
+	}{
+		{"another candidate", "app1", 6, "", http.StatusForbidden},
+		{"unknown application", "missing", 5, "", http.StatusNotFound},
+		{"invalid body", "app1", 5, "{", http.StatusBadRequest},
+		{"owning candidate", "app1", 5, `{"reason":"Accepted another offer"}`, http.StatusOK},
+		{"already withdrawn", "app1", 5, "", http.StatusConflict},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/applications/"+tt.applicationID+"/withdraw", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, withCandidate(req, tt.candidateID))
+			if rr.Code != tt.wantCode {
+				t.Errorf("got %v want %v: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+		})
+	}
+
+	stored, _ := mockRepo.GetApplicationByID("app1")
+	if stored.Status.CurrentStage != models.StageWithdrawn || stored.Active || stored.WithdrawnAt == nil {
+		t.Errorf("Expected application to be withdrawn and inactive, got %+v", stored)
+	}
+	last := stored.StageHistory[len(stored.StageHistory)-1]
+	if last.From != models.StageApplied || last.ChangedBy != 5 || last.Reason != "Accepted another offer" {
+		t.Errorf("Unexpected withdrawal history entry: %+v", last)
+	}
+
+	events := mockKafka.(*MockKafkaPublisher).GetPublishedApplicationEvents()
+	if len(events) != 1 || events[0].Event != kafka.ApplicationEventWithdrawn || events[0].Application.ApplicationID != "app1" {
+		t.Errorf("Expected one withdrawal event, got %+v", events)
+	}
+
+	// reapplying waits out the cooldown
+	reapply := &models.Application{ApplicationID: "app2", JobID: 1, CandidateID: 5}
+	if err := service.CreateApplication(reapply); !errors.Is(err, services.ErrReapplyCooldown) {
+		t.Errorf("Expected ErrReapplyCooldown, got %v", err)
+	}
+	service.ReapplyCooldown = 0
+	if err := service.CreateApplication(reapply); err != nil {
+		t.Errorf("Expected reapplication after the cooldown to succeed, got %v", err)
+	}
+}
+
 func TestApplicationHandler_GetApplicationsByJobID(t *testing.T) {
 	mockRepo := NewMockApplicationRepo()
 	mockKafka := NewMockKafkaPublisher()
-	service := services.ApplicationsService{AppRepo: mockRepo}
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Job 1", CompanyID: 1})
+	jobRepo.CreateJob(&models.Job{Title: "Job 2", CompanyID: 2})
+	service := services.ApplicationsService{AppRepo: mockRepo, JobRepo: jobRepo}
 	handler := handlers.ApplicationHandler{
 		ApplicationService: service,
 		KafkaPublisher:     mockKafka,
 	}
 
 	// Create test applications
-	app1 := bson.M{
-		"application_id": "1",
-		"job_id":         uint(1),
-		"candidate_id":   uint(1),
-		"resume_url":     "http://example.com/resume1.pdf",
-		"status": bson.M{
-			"current_stage": "Applied",
-			"last_updated":  time.Now(),
-		},
+	app1 := &models.Application{
+		ApplicationID: "1",
+		JobID:         1,
+		CandidateID:   1,
+		ResumeURL:     "http://example.com/resume1.pdf",
+		Status:        models.Status{CurrentStage: models.StageApplied, LastUpdated: time.Now()},
 	}
-	app2 := bson.M{
-		"application_id": "2",
-		"job_id":         uint(1),
-		"candidate_id":   uint(2),
-		"resume_url":     "http://example.com/resume2.pdf",
-		"status": bson.M{
-			"current_stage": "Applied",
-			"last_updated":  time.Now(),
-		},
+	app2 := &models.Application{
+		ApplicationID: "2",
+		JobID:         1,
+		CandidateID:   2,
+		ResumeURL:     "http://example.com/resume2.pdf",
+		Status:        models.Status{CurrentStage: models.StageApplied, LastUpdated: time.Now()},
 	}
 
 	mockRepo.CreateApplication(app1)
@@ -164,14 +462,20 @@ func TestApplicationHandler_GetApplicationsByJobID(t *testing.T) {
 		{
 			name:           "non-existent job ID",
 			jobID:          "999",
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusNotFound,
+			expectedCount:  0,
+		},
+		{
+			name:           "job owned by another company",
+			jobID:          "2",
+			expectedStatus: http.StatusForbidden,
 			expectedCount:  0,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest("GET", "/applications/job/"+tt.jobID, nil)
+			req := withUserInfo(httptest.NewRequest("GET", "/applications/job/"+tt.jobID, nil), 1)
 			rr := httptest.NewRecorder()
 
 			router := setupTestApplicationRouter(&handler)
@@ -182,18 +486,298 @@ func TestApplicationHandler_GetApplicationsByJobID(t *testing.T) {
 			}
 
 			if tt.expectedStatus == http.StatusOK {
-				var response []bson.M
+				var response models.ApplicationPage
 				if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
 					t.Errorf("Failed to decode response: %v", err)
 				}
-				if len(response) != tt.expectedCount {
-					t.Errorf("Expected %d applications, got %d", tt.expectedCount, len(response))
+				if len(response.Items) != tt.expectedCount {
+					t.Errorf("Expected %d applications, got %d", tt.expectedCount, len(response.Items))
 				}
 			}
 		})
 	}
 }
 
+func TestApplicationHandler_GetApplicationsByJobID_Paging(t *testing.T) {
+	mockRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Popular Job", CompanyID: 1, Status: models.Open})
+	handler := handlers.ApplicationHandler{
+		ApplicationService: services.ApplicationsService{AppRepo: mockRepo, JobRepo: jobRepo},
+		KafkaPublisher:     NewMockKafkaPublisher(),
+	}
+	router := setupTestApplicationRouter(&handler)
+
+	rating := func(r float64) *float64 { return &r }
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	seed := []models.Application{
+		{ApplicationID: "a1", CandidateID: 1, Status: models.Status{CurrentStage: models.StageApplied}, Tags: []string{"referral"}},
+		{ApplicationID: "a2", CandidateID: 2, Status: models.Status{CurrentStage: models.StageScreening}, Rating: rating(4.5)},
+		{ApplicationID: "a3", CandidateID: 3, Status: models.Status{CurrentStage: models.StageScreening}, Tags: []string{"referral", "senior"}, Rating: rating(3)},
+		{ApplicationID: "a4", CandidateID: 4, Status: models.Status{CurrentStage: models.StageInterview}, Rating: rating(4.5)},
+		{ApplicationID: "a5", CandidateID: 5, Status: models.Status{CurrentStage: models.StageApplied}},
+	}
+	for i := range seed {
+		seed[i].JobID = 1
+		seed[i].Active = true
+		seed[i].AppliedAt = base.Add(time.Duration(i) * 24 * time.Hour)
+		mockRepo.CreateApplication(&seed[i])
+	}
+
+	// fetch follows nextCursor until the last page and returns the IDs in order
+	fetch := func(t *testing.T, query string) []string {
+		t.Helper()
+		var ids []string
+		cursor := ""
+		for pages := 0; pages < 10; pages++ {
+			url := "/applications/job/1?" + query
+			if cursor != "" {
+				url += "&cursor=" + cursor
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", url, nil), 1))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("got %v for %s: %s", rr.Code, url, rr.Body.String())
+			}
+			var page models.ApplicationPage
+			if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+				t.Fatalf("Failed to decode page: %v", err)
+			}
+			for _, app := range page.Items {
+				ids = append(ids, app.ApplicationID)
+			}
+			if page.NextCursor == "" {
+				return ids
+			}
+			cursor = page.NextCursor
+		}
+		t.Fatalf("Paging through %s did not end", query)
+		return nil
+	}
+
+	cases := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"newest first by default", "limit=2", []string{"a5", "a4", "a3", "a2", "a1"}},
+		{"oldest first", "limit=2&sort=appliedAt", []string{"a1", "a2", "a3", "a4", "a5"}},
+		{"by stage", "limit=1&stage=Screening,Interview", []string{"a4", "a3", "a2"}},
+		{"by tags", "tag=referral&tag=senior", []string{"a3"}},
+		{"by applied date", "appliedAfter=2024-05-02&appliedBefore=2024-05-04T12:00:00Z", []string{"a4", "a3", "a2"}},
+		{"by rating", "minRating=4", []string{"a4", "a2"}},
+		{"highest rated first, unrated last", "limit=2&sort=-rating", []string{"a4", "a2", "a3", "a5", "a1"}},
+		{"lowest rated first, unrated first", "limit=2&sort=rating", []string{"a1", "a5", "a3", "a2", "a4"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fetch(t, tt.query)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+
+	// a cursor only works with the ordering it was issued for
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/applications/job/1?limit=1", nil), 1))
+	var page models.ApplicationPage
+	json.NewDecoder(rr.Body).Decode(&page)
+	for _, query := range []string{"sort=rating&cursor=" + page.NextCursor, "cursor=not-a-cursor", "sort=salary", "minRating=high"} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/applications/job/1?"+query, nil), 1))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %v", query, rr.Code)
+		}
+	}
+}
+
+func TestApplicationHandler_GetJobBoard(t *testing.T) {
+	mockRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Board Job", CompanyID: 1, Status: models.Open})
+	handler := handlers.ApplicationHandler{
+		ApplicationService: services.ApplicationsService{AppRepo: mockRepo, JobRepo: jobRepo},
+		KafkaPublisher:     NewMockKafkaPublisher(),
+	}
+	router := setupTestApplicationRouter(&handler)
+
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	stages := []string{models.StageApplied, models.StageScreening, models.StageScreening, models.StageInterview, models.StageApplied, models.StageApplied, models.StageJobClosed}
+	for i, stage := range stages {
+		mockRepo.CreateApplication(&models.Application{
+			ApplicationID: fmt.Sprintf("a%d", i+1),
+			JobID:         1,
+			CandidateID:   models.NumericID(i + 1),
+			Active:        true,
+			AppliedAt:     base.Add(time.Duration(i) * time.Hour),
+			Status:        models.Status{CurrentStage: stage},
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/jobs/1/board?limit=2", nil), 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var board models.JobBoard
+	if err := json.NewDecoder(rr.Body).Decode(&board); err != nil {
+		t.Fatalf("Failed to decode board: %v", err)
+	}
+	if board.Total != int64(len(stages)) {
+		t.Errorf("Expected total %d, got %d", len(stages), board.Total)
+	}
+
+	// columns follow the pipeline, then stages outside it
+	var layout []string
+	for _, column := range board.Columns {
+		var ids []string
+		for _, card := range column.Cards {
+			ids = append(ids, card.ApplicationID)
+		}
+		layout = append(layout, fmt.Sprintf("%s:%d:%s", column.Stage, column.Count, strings.Join(ids, ",")))
+	}
+	want := []string{"Applied:3:a6,a5", "Screening:2:a3,a2", "Interview:1:a4", "Offer:0:", "Hired:0:", "Rejected:0:", "Withdrawn:0:", "Job Closed:1:a7"}
+	if strings.Join(layout, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v want %v", layout, want)
+	}
+	if !board.Columns[len(board.Columns)-1].Terminal || board.Columns[0].Terminal {
+		t.Errorf("Expected only closed stages to be terminal")
+	}
+
+	// a full column continues through the stage-filtered listing
+	applied := board.Columns[0]
+	if applied.NextCursor == "" || board.Columns[1].NextCursor != "" {
+		t.Fatalf("Expected a cursor on the Applied column only")
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/applications/job/1?stage=Applied&cursor="+applied.NextCursor, nil), 1))
+	var page models.ApplicationPage
+	json.NewDecoder(rr.Body).Decode(&page)
+	if rr.Code != http.StatusOK || len(page.Items) != 1 || page.Items[0].ApplicationID != "a1" {
+		t.Errorf("Expected the rest of the Applied column, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	for _, tt := range []struct {
+		name   string
+		url    string
+		orgID  int
+		status int
+	}{
+		{"other company", "/jobs/1/board", 2, http.StatusForbidden},
+		{"unknown job", "/jobs/99/board", 1, http.StatusNotFound},
+		{"invalid limit", "/jobs/1/board?limit=0", 1, http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", tt.url, nil), tt.orgID))
+			if rr.Code != tt.status {
+				t.Errorf("Expected %v, got %v", tt.status, rr.Code)
+			}
+		})
+	}
+}
+
+func TestApplicationHandler_Scorecards(t *testing.T) {
+	mockRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open, Scorecard: &models.ScorecardTemplate{
+		Competencies: []models.Competency{
+			{Name: "Communication", Scale: models.RatingScale{Min: 1, Max: 5}},
+			{Name: "Coding", Scale: models.RatingScale{Min: 0, Max: 10}, Weight: 2},
+		},
+	}})
+	jobRepo.CreateJob(&models.Job{Title: "Unscored", CompanyID: 1, Status: models.Open})
+	mockRepo.CreateApplication(&models.Application{ApplicationID: "rated", JobID: 1, CandidateID: 1, Active: true, AppliedAt: time.Now()})
+	mockRepo.CreateApplication(&models.Application{ApplicationID: "unrated", JobID: 1, CandidateID: 2, Active: true, AppliedAt: time.Now()})
+	mockRepo.CreateApplication(&models.Application{ApplicationID: "other", JobID: 2, CandidateID: 3, Active: true})
+	handler := handlers.ApplicationHandler{
+		ApplicationService: services.ApplicationsService{AppRepo: mockRepo, JobRepo: jobRepo},
+		KafkaPublisher:     NewMockKafkaPublisher(),
+	}
+	router := setupTestApplicationRouter(&handler)
+
+	// submit posts a scorecard as user userID of org 1
+	submit := func(t *testing.T, applicationID string, userID int, body string) (int, models.ScorecardSummary) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/applications/"+applicationID+"/scorecards", strings.NewReader(body))
+		router.ServeHTTP(rr, withOrgMember(req, 1, userID))
+		var summary models.ScorecardSummary
+		if rr.Code == http.StatusCreated {
+			json.NewDecoder(rr.Body).Decode(&summary)
+		}
+		return rr.Code, summary
+	}
+
+	// Communication 4 of 1-5 and Coding 5 of 0-10 at twice the weight average out at 7/12 of the range
+	code, summary := submit(t, "rated", 10, `{"scores":[{"competency":"communication","score":4},{"competency":"Coding","score":5,"comment":"$O(n^2)$ at first"}]}`)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %v", code)
+	}
+	if len(summary.Scorecards) != 1 || summary.Scorecards[0].Overall != 3.33 || summary.Scorecards[0].Scores[0].Competency != "Communication" {
+		t.Errorf("Unexpected scorecard: %+v", summary.Scorecards)
+	}
+
+	// a second interviewer adds a scorecard and the first one replaces theirs
+	if code, _ := submit(t, "rated", 11, `{"scores":[{"competency":"Coding","score":6}]}`); code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %v", code)
+	}
+	if code, _ := submit(t, "rated", 10, `{"scores":[{"competency":"Communication","score":5}],"summary":"Hire"}`); code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %v", code)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/applications/rated/scorecards", nil), 1))
+	json.NewDecoder(rr.Body).Decode(&summary)
+	if rr.Code != http.StatusOK || len(summary.Scorecards) != 2 || summary.Rating == nil || *summary.Rating != 4.2 {
+		t.Errorf("Expected a rating of 4.2 from two scorecards, got %v: %+v", rr.Code, summary)
+	}
+
+	// the public application view has neither the scorecards nor the rating
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/applications/rated", nil))
+	var public bson.M
+	json.NewDecoder(rr.Body).Decode(&public)
+	if _, ok := public["scorecards"]; ok || public["rating"] != nil {
+		t.Errorf("Expected scorecards and rating to be left out of the public view, got %v", public)
+	}
+
+	// the rating feeds the application listing
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/applications/job/1?minRating=4", nil), 1))
+	var page models.ApplicationPage
+	json.NewDecoder(rr.Body).Decode(&page)
+	if len(page.Items) != 1 || page.Items[0].ApplicationID != "rated" {
+		t.Errorf("Expected only the rated application, got %+v", page.Items)
+	}
+
+	cases := []struct {
+		name          string
+		applicationID string
+		orgID         int
+		body          string
+		status        int
+	}{
+		{"unknown competency", "unrated", 1, `{"scores":[{"competency":"Design","score":3}]}`, http.StatusBadRequest},
+		{"score out of range", "unrated", 1, `{"scores":[{"competency":"Coding","score":11}]}`, http.StatusBadRequest},
+		{"competency scored twice", "unrated", 1, `{"scores":[{"competency":"Coding","score":1},{"competency":"coding","score":2}]}`, http.StatusBadRequest},
+		{"no scores", "unrated", 1, `{"scores":[]}`, http.StatusBadRequest},
+		{"job without template", "other", 1, `{"scores":[{"competency":"Coding","score":1}]}`, http.StatusConflict},
+		{"another company", "unrated", 2, `{"scores":[{"competency":"Coding","score":1}]}`, http.StatusForbidden},
+		{"unknown application", "missing", 1, `{"scores":[{"competency":"Coding","score":1}]}`, http.StatusNotFound},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/applications/"+tt.applicationID+"/scorecards", strings.NewReader(tt.body))
+			router.ServeHTTP(rr, withUserInfo(req, tt.orgID))
+			if rr.Code != tt.status {
+				t.Errorf("Expected %v, got %v: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
 func TestApplicationHandler_GetApplicationByID(t *testing.T) {
 	mockRepo := NewMockApplicationRepo()
 	mockKafka := NewMockKafkaPublisher()
@@ -204,15 +788,12 @@ func TestApplicationHandler_GetApplicationByID(t *testing.T) {
 	}
 
 	// Create test application
-	app := bson.M{
-		"application_id": "1",
-		"job_id":         uint(1),
-		"candidate_id":   uint(1),
-		"resume_url":     "http://example.com/resume.pdf",
-		"status": bson.M{
-			"current_stage": "Applied",
-			"last_updated":  time.Now(),
-		},
+	app := &models.Application{
+		ApplicationID: "1",
+		JobID:         1,
+		CandidateID:   1,
+		ResumeURL:     "http://example.com/resume.pdf",
+		Status:        models.Status{CurrentStage: models.StageApplied, LastUpdated: time.Now()},
 	}
 
 	mockRepo.CreateApplication(app)
@@ -272,25 +853,19 @@ func TestApplicationHandler_GetApplicationsByCandidateID(t *testing.T) {
 	}
 
 	// Create test applications
-	app1 := bson.M{
-		"application_id": "1",
-		"job_id":         uint(1),
-		"candidate_id":   uint(1),
-		"resume_url":     "http://example.com/resume1.pdf",
-		"status": bson.M{
-			"current_stage": "Applied",
-			"last_updated":  time.Now(),
-		},
+	app1 := &models.Application{
+		ApplicationID: "1",
+		JobID:         1,
+		CandidateID:   1,
+		ResumeURL:     "http://example.com/resume1.pdf",
+		Status:        models.Status{CurrentStage: models.StageApplied, LastUpdated: time.Now()},
 	}
-	app2 := bson.M{
-		"application_id": "2",
-		"job_id":         uint(2),
-		"candidate_id":   uint(1),
-		"resume_url":     "http://example.com/resume2.pdf",
-		"status": bson.M{
-			"current_stage": "Applied",
-			"last_updated":  time.Now(),
-		},
+	app2 := &models.Application{
+		ApplicationID: "2",
+		JobID:         2,
+		CandidateID:   1,
+		ResumeURL:     "http://example.com/resume2.pdf",
+		Status:        models.Status{CurrentStage: models.StageApplied, LastUpdated: time.Now()},
 	}
 
 	mockRepo.CreateApplication(app1)
@@ -346,3 +921,143 @@ func TestApplicationHandler_GetApplicationsByCandidateID(t *testing.T) {
 		})
 	}
 }
+
+func TestApplicationHandler_BulkUpdateApplications(t *testing.T) {
+	appRepo := NewMockApplicationRepo()
+	mockKafka := NewMockKafkaPublisher()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open})
+	jobRepo.CreateJob(&models.Job{Title: "Other company", CompanyID: 2, Status: models.Open})
+	service := services.ApplicationsService{AppRepo: appRepo, JobRepo: jobRepo, ReasonRepo: tests.NewMockRejectionReasonRepo()}
+	router := setupTestApplicationRouter(&handlers.ApplicationHandler{ApplicationService: service, KafkaPublisher: mockKafka})
+
+	for i, jobID := range []models.NumericID{1, 1, 1, 2} {
+		app := &models.Application{ApplicationID: fmt.Sprintf("a%d", i+1), JobID: jobID, CandidateID: models.NumericID(i + 1)}
+		if err := service.CreateApplication(app); err != nil {
+			t.Fatalf("CreateApplication failed: %v", err)
+		}
+	}
+	if _, err := service.MoveStage(&clients.UserResponse{ID: 10, Org: &clients.Org{ID: 1}}, "a2", models.StageScreening, "", nil); err != nil {
+		t.Fatalf("MoveStage failed: %v", err)
+	}
+
+	// bulk posts an action as a member of company 1 and returns the status of each application
+	bulk := func(t *testing.T, body string, wantCode int) map[string]string {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("POST", "/applications/bulk", bytes.NewBufferString(body)), 1))
+		if rr.Code != wantCode {
+			t.Fatalf("Expected %v, got %v: %s", wantCode, rr.Code, rr.Body.String())
+		}
+		statuses := make(map[string]string)
+		if rr.Code == http.StatusOK {
+			var result models.BulkActionResult
+			if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+				t.Fatalf("Failed to decode result: %v", err)
+			}
+			for _, item := range result.Results {
+				statuses[item.ApplicationID] = item.Status
+			}
+		}
+		return statuses
+	}
+	publishedEvents := func() []PublishedApplicationEvent {
+		return mockKafka.(*MockKafkaPublisher).GetPublishedApplicationEvents()
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		bulk(t, `{"applicationIds":["a1"],"action":"archive"}`, http.StatusBadRequest)
+		bulk(t, `{"applicationIds":[],"action":"add_tags","tags":["x"]}`, http.StatusBadRequest)
+		bulk(t, `{"applicationIds":["a1"],"action":"move_stage"}`, http.StatusBadRequest)
+		bulk(t, `{"applicationIds":["a1"],"action":"reject"}`, http.StatusBadRequest)
+		bulk(t, `{"applicationIds":["a1"],"action":"reject","disposition":{"reasonCode":"bad_vibes"}}`, http.StatusBadRequest)
+		if len(publishedEvents()) != 0 {
+			t.Errorf("Expected no events for invalid requests")
+		}
+	})
+
+	t.Run("move stage", func(t *testing.T) {
+		statuses := bulk(t, `{"applicationIds":["a1","a2","a4","missing","a1"],"action":"move_stage","stage":"Screening","reason":"Triage"}`, http.StatusOK)
+		want := map[string]string{"a1": "updated", "a2": "failed", "a4": "failed", "missing": "failed"}
+		for id, status := range want {
+			if statuses[id] != status {
+				t.Errorf("%s: got %q want %q", id, statuses[id], status)
+			}
+		}
+		if len(statuses) != len(want) {
+			t.Errorf("Expected repeated IDs to be reported once, got %v", statuses)
+		}
+		stored, _ := appRepo.GetApplicationByID("a1")
+		if stored.Status.CurrentStage != models.StageScreening || stored.Status.Reason != "Triage" {
+			t.Errorf("Expected a1 to move to Screening, got %+v", stored.Status)
+		}
+		events := publishedEvents()
+		if len(events) != 1 || events[0].Event != kafka.ApplicationEventStageChanged || events[0].Application.ApplicationID != "a1" {
+			t.Errorf("Expected one stage changed event for a1, got %+v", events)
+		}
+	})
+
+	t.Run("withdrawn stage is left to the candidate", func(t *testing.T) {
+		before := len(publishedEvents())
+		statuses := bulk(t, `{"applicationIds":["a3"],"action":"move_stage","stage":"Withdrawn"}`, http.StatusOK)
+		if statuses["a3"] != "failed" {
+			t.Errorf("Expected the move to a withdrawn stage to fail, got %v", statuses)
+		}
+		if len(publishedEvents()) != before {
+			t.Errorf("Expected no events for a failed move")
+		}
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		before := len(publishedEvents())
+		statuses := bulk(t, `{"applicationIds":["a1","a2"],"action":"add_tags","tags":["strong"," referral ","strong"]}`, http.StatusOK)
+		if statuses["a1"] != "updated" || statuses["a2"] != "updated" {
+			t.Errorf("Expected both applications to be tagged, got %v", statuses)
+		}
+		statuses = bulk(t, `{"applicationIds":["a1","a3"],"action":"remove_tags","tags":["referral"]}`, http.StatusOK)
+		if statuses["a1"] != "updated" || statuses["a3"] != "unchanged" {
+			t.Errorf("Expected only a1 to lose the tag, got %v", statuses)
+		}
+		stored, _ := appRepo.GetApplicationByID("a1")
+		if strings.Join(stored.Tags, ",") != "strong" {
+			t.Errorf("Expected a1 to be tagged strong, got %v", stored.Tags)
+		}
+		stored, _ = appRepo.GetApplicationByID("a2")
+		if strings.Join(stored.Tags, ",") != "strong,referral" {
+			t.Errorf("Expected a2 to keep both tags, got %v", stored.Tags)
+		}
+		events := publishedEvents()[before:]
+		if len(events) != 3 || events[0].Event != kafka.ApplicationEventTagsChanged {
+			t.Errorf("Expected a tags changed event per changed application, got %+v", events)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		before := len(publishedEvents())
+		statuses := bulk(t, `{"applicationIds":["a1","a2","a3"],"action":"reject","disposition":{"reasonCode":"position_filled","candidateMessage":"The role has been filled."}}`, http.StatusOK)
+		for _, id := range []string{"a1", "a2", "a3"} {
+			if statuses[id] != "updated" {
+				t.Errorf("%s: expected to be rejected, got %q", id, statuses[id])
+			}
+		}
+		stored, _ := appRepo.GetApplicationByID("a3")
+		if stored.Status.CurrentStage != models.StageRejected || stored.Disposition == nil || stored.Disposition.ReasonLabel != "Position filled by another candidate" {
+			t.Errorf("Expected a3 to be rejected with a disposition, got %+v", stored)
+		}
+		events := publishedEvents()[before:]
+		if len(events) != 3 || events[0].Event != kafka.ApplicationEventRejected {
+			t.Errorf("Expected a rejected event per application, got %+v", events)
+		}
+
+		statuses = bulk(t, `{"applicationIds":["a1"],"action":"reject","disposition":{"reasonCode":"other"}}`, http.StatusOK)
+		if statuses["a1"] != "failed" {
+			t.Errorf("Expected rejecting a closed application to fail, got %v", statuses)
+		}
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withCandidate(httptest.NewRequest("POST", "/applications/bulk", bytes.NewBufferString(`{"applicationIds":["a1"],"action":"add_tags","tags":["x"]}`)), 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected a user without an organization to be forbidden, got %v", rr.Code)
+	}
+}
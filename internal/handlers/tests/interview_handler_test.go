@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"jobs-svc/internal/tests"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestInterviewRouter(handler *handlers.InterviewHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/applications/{id}/interviews", handler.ScheduleInterview).Methods("POST")
+	router.HandleFunc("/applications/{id}/interviews", handler.GetInterviews).Methods("GET")
+	router.HandleFunc("/interviews/{id}", handler.GetInterview).Methods("GET")
+	router.HandleFunc("/interviews/{id}", handler.RescheduleInterview).Methods("PUT")
+	router.HandleFunc("/interviews/{id}/cancel", handler.CancelInterview).Methods("POST")
+	router.HandleFunc("/interviews/{id}/invite.ics", handler.GetInterviewCalendar).Methods("GET")
+	return router
+}
+
+func TestInterviewHandler_Interviews(t *testing.T) {
+	appRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Senior Backend Engineer, Payments; Platform", CompanyID: 1, Status: models.Open})
+	interviewing := models.Status{CurrentStage: models.StageInterview}
+	appRepo.CreateApplication(&models.Application{ApplicationID: "app1", JobID: 1, CandidateID: 5, CompanyID: 1, Email: "candidate@example.com", Status: interviewing, Active: true})
+	appRepo.CreateApplication(&models.Application{ApplicationID: "app2", JobID: 1, CandidateID: 6, CompanyID: 1, Status: interviewing, Active: true})
+	appRepo.CreateApplication(&models.Application{ApplicationID: "withdrawn", JobID: 1, CandidateID: 7, CompanyID: 1, Status: interviewing, Active: false})
+	appRepo.CreateApplication(&models.Application{ApplicationID: "screening", JobID: 1, CandidateID: 8, CompanyID: 1, Status: models.Status{CurrentStage: models.StageScreening}, Active: true})
+	appRepo.CreateApplication(&models.Application{
+		ApplicationID: "offer", JobID: 1, CandidateID: 9, CompanyID: 1, Status: models.Status{CurrentStage: models.StageOffer}, Active: true,
+		StageHistory: []models.StageChange{{From: models.StageScreening, To: models.StageInterview}, {From: models.StageInterview, To: models.StageOffer}},
+	})
+	handler := handlers.InterviewHandler{
+		InterviewService: services.InterviewService{
+			InterviewRepo: tests.NewMockInterviewRepo(),
+			AppRepo:       appRepo,
+			JobRepo:       jobRepo,
+			PipelineRepo:  tests.NewMockPipelineRepo(),
+		},
+	}
+	router := setupTestInterviewRouter(&handler)
+
+	// send makes a request as a recruiter of orgID and decodes an interview from successful responses
+	send := func(t *testing.T, method, url string, body interface{}, orgID int) (int, models.Interview) {
+		t.Helper()
+		var payload bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&payload).Encode(body)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withUserInfo(httptest.NewRequest(method, url, &payload), orgID))
+		var interview models.Interview
+		if rr.Code == http.StatusOK || rr.Code == http.StatusCreated {
+			json.NewDecoder(rr.Body).Decode(&interview)
+		}
+		return rr.Code, interview
+	}
+	slot := func(hour, minutes int, interviewers ...uint) map[string]interface{} {
+		start := time.Date(2030, 3, 4, hour, 0, 0, 0, time.UTC)
+		return map[string]interface{}{
+			"interviewerIds": interviewers,
+			"startsAt":       start,
+			"endsAt":         start.Add(time.Duration(minutes) * time.Minute),
+			"videoLink":      "https://meet.example.com/abc-defg-hij",
+		}
+	}
+
+	code, first := send(t, "POST", "/applications/app1/interviews", slot(10, 60, 11, 12, 11), 1)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %v", code)
+	}
+	if first.Status != models.InterviewStatusScheduled || first.CandidateID != 5 || first.CompanyID != 1 || len(first.InterviewerIDs) != 2 {
+		t.Errorf("Unexpected interview: %+v", first)
+	}
+
+	// interviewer 12 is booked 10:00-11:00, so 10:30 clashes but 11:00 does not
+	if code, _ := send(t, "POST", "/applications/app2/interviews", slot(10, 90, 12), 1); code != http.StatusConflict {
+		t.Errorf("Expected 409 for an overlapping interviewer, got %v", code)
+	}
+	code, second := send(t, "POST", "/applications/app2/interviews", slot(11, 30, 12), 1)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected 201 for a back-to-back interview, got %v", code)
+	}
+
+	// rescheduling an interview does not clash with itself
+	code, moved := send(t, "PUT", "/interviews/"+first.ID, slot(9, 90, 11, 12), 1)
+	if code != http.StatusOK || moved.Sequence != 1 || moved.StartsAt.Hour() != 9 {
+		t.Fatalf("Expected the interview to move to 9:00, got %v: %+v", code, moved)
+	}
+	if code, _ := send(t, "PUT", "/interviews/"+second.ID, slot(10, 60, 12), 1); code != http.StatusConflict {
+		t.Errorf("Expected 409 for rescheduling into a booked slot, got %v", code)
+	}
+
+	// cancelling frees the interviewers' time
+	if code, cancelled := send(t, "POST", "/interviews/"+first.ID+"/cancel", nil, 1); code != http.StatusOK || cancelled.Status != models.InterviewStatusCancelled {
+		t.Fatalf("Expected the interview to be cancelled, got %v: %+v", code, cancelled)
+	}
+	if code, _ := send(t, "PUT", "/interviews/"+second.ID, slot(10, 60, 12), 1); code != http.StatusOK {
+		t.Errorf("Expected the freed slot to be bookable, got %v", code)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/applications/app1/interviews", nil), 1))
+	var interviews []models.Interview
+	if err := json.NewDecoder(rr.Body).Decode(&interviews); err != nil || len(interviews) != 1 {
+		t.Errorf("Expected one interview for app1, got %v: %+v", err, interviews)
+	}
+
+	t.Run("calendar", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withCandidate(httptest.NewRequest("GET", "/interviews/"+first.ID+"/invite.ics", nil), 5))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if contentType := rr.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/calendar") {
+			t.Errorf("Expected a calendar content type, got %q", contentType)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			"BEGIN:VCALENDAR\r\n",
+			"UID:" + first.ID + "@jobs-svc\r\n",
+			"DTSTART:20300304T090000Z\r\n",
+			"DTEND:20300304T103000Z\r\n",
+			"SEQUENCE:2\r\n",
+			"STATUS:CANCELLED\r\n",
+			`SUMMARY:Interview: Senior Backend Engineer\, Payments\; Platform`,
+			"ATTENDEE;ROLE=REQ-PARTICIPANT:mailto:candidate@example.com\r\n",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Expected calendar to contain %q, got:\n%s", want, body)
+			}
+		}
+		for _, line := range strings.Split(body, "\r\n") {
+			if len(line) > 75 {
+				t.Errorf("Expected lines to be folded at 75 octets, got %q", line)
+			}
+		}
+	})
+
+	cases := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"interviewer downloads invite", withOrgMember(httptest.NewRequest("GET", "/interviews/"+second.ID+"/invite.ics", nil), 3, 12), http.StatusOK},
+		{"other candidate downloads invite", withCandidate(httptest.NewRequest("GET", "/interviews/"+second.ID+"/invite.ics", nil), 5), http.StatusForbidden},
+		{"another company reads interview", withUserInfo(httptest.NewRequest("GET", "/interviews/"+second.ID, nil), 2), http.StatusForbidden},
+		{"unknown interview", withUserInfo(httptest.NewRequest("GET", "/interviews/nope", nil), 1), http.StatusNotFound},
+		{"cancelling twice", withUserInfo(httptest.NewRequest("POST", "/interviews/"+first.ID+"/cancel", nil), 1), http.StatusConflict},
+		{"withdrawn application", withUserInfo(httptest.NewRequest("POST", "/applications/withdrawn/interviews", jsonBody(slot(14, 60, 13))), 1), http.StatusConflict},
+		{"application before the interview stage", withUserInfo(httptest.NewRequest("POST", "/applications/screening/interviews", jsonBody(slot(14, 60, 13))), 1), http.StatusConflict},
+		{"application past the interview stage", withUserInfo(httptest.NewRequest("POST", "/applications/offer/interviews", jsonBody(slot(14, 60, 13))), 1), http.StatusCreated},
+		{"no interviewers", withUserInfo(httptest.NewRequest("POST", "/applications/app1/interviews", jsonBody(slot(14, 60))), 1), http.StatusBadRequest},
+		{"ends before start", withUserInfo(httptest.NewRequest("POST", "/applications/app1/interviews", jsonBody(slot(14, -30, 13))), 1), http.StatusBadRequest},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, tt.req)
+			if rr.Code != tt.status {
+				t.Errorf("Expected %v, got %v: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("concurrent bookings", func(t *testing.T) {
+		// every request books interviewers 14 and 15 for the same hour, so exactly one can win
+		var wg sync.WaitGroup
+		codes := make([]int, 8)
+		for i := range codes {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				interviewers := []uint{14, 15}
+				if i%2 == 1 {
+					interviewers = []uint{15, 14}
+				}
+				codes[i], _ = send(t, "POST", "/applications/app2/interviews", slot(16, 60, interviewers...), 1)
+			}(i)
+		}
+		wg.Wait()
+
+		created := 0
+		for _, code := range codes {
+			if code == http.StatusCreated {
+				created++
+			} else if code != http.StatusConflict {
+				t.Errorf("Expected 201 or 409, got %v", code)
+			}
+		}
+		if created != 1 {
+			t.Errorf("Expected exactly one booking to succeed, got %d", created)
+		}
+	})
+}
+
+func jsonBody(body interface{}) *bytes.Buffer {
+	var payload bytes.Buffer
+	json.NewEncoder(&payload).Encode(body)
+	return &payload
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"jobs-svc/internal/clients"
 	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/kafka"
 	"jobs-svc/internal/models"
 	"jobs-svc/internal/services"
 	"jobs-svc/internal/tests"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,11 +23,21 @@ import (
 func setupTestRouter(handler *handlers.JobHandler) *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/jobs", handler.GetJobs).Methods("GET")
+	router.HandleFunc("/jobs/search", handler.SearchJobs).Methods("GET")
+	router.HandleFunc("/jobs/deleted", handler.GetDeletedJobs).Methods("GET")
 	router.HandleFunc("/jobs/{id}", handler.GetJobByID).Methods("GET")
 	router.HandleFunc("/jobs/recruiter/{id}", handler.GetJobsByRecruiterID).Methods("GET")
 	router.HandleFunc("/jobs", handler.CreateJob).Methods("POST")
 	router.HandleFunc("/jobs/{id}", handler.UpdateJob).Methods("PUT")
+	router.HandleFunc("/jobs/{id}", handler.PatchJob).Methods("PATCH")
 	router.HandleFunc("/jobs/{id}", handler.DeleteJob).Methods("DELETE")
+	router.HandleFunc("/jobs/{id}/restore", handler.RestoreJob).Methods("POST")
+	router.HandleFunc("/admin/jobs/purge", handler.PurgeDeletedJobs).Methods("POST")
+	router.HandleFunc("/jobs/{id}/publish", handler.PublishJob).Methods("POST")
+	router.HandleFunc("/jobs/{id}/pause", handler.PauseJob).Methods("POST")
+	router.HandleFunc("/jobs/{id}/close", handler.CloseJob).Methods("POST")
+	router.HandleFunc("/jobs/{id}/revisions", handler.GetJobRevisions).Methods("GET")
+	router.HandleFunc("/jobs/{id}/revisions/{rev}/diff", handler.GetJobRevisionDiff).Methods("GET")
 	router.HandleFunc("/jobs/summary", handler.GetJobsByIDs).Methods("POST")
 	return router
 }
@@ -63,13 +76,246 @@ func TestJobHandler_GetJobs(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var jobs []models.Job
-	if err := json.NewDecoder(rr.Body).Decode(&jobs); err != nil {
+	var page models.JobPage
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
 		t.Errorf("Failed to decode response: %v", err)
 	}
 
-	if len(jobs) != 2 {
-		t.Errorf("Expected 2 jobs, got %d", len(jobs))
+	if len(page.Items) != 2 {
+		t.Errorf("Expected 2 jobs, got %d", len(page.Items))
+	}
+	if page.Total != 2 {
+		t.Errorf("Expected total of 2, got %d", page.Total)
+	}
+}
+
+func TestJobHandler_GetJobs_QueryParams(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	mockKafka := tests.NewMockKafkaPublisher()
+	service := services.JobService{JobRepo: mockRepo}
+	handler := handlers.JobHandler{
+		JobService:     service,
+		KafkaPublisher: mockKafka,
+	}
+
+	mockRepo.CreateJob(&models.Job{Title: "B Job", Company: "Acme", RecruiterId: 1})
+	mockRepo.CreateJob(&models.Job{Title: "A Job", Company: "Acme", RecruiterId: 1})
+	mockRepo.CreateJob(&models.Job{Title: "C Job", Company: "Globex", RecruiterId: 2})
+
+	router := setupTestRouter(&handler)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedTitles []string
+	}{
+		{
+			name:           "filter by company and sort by title",
+			query:          "?company=acme&sort=title",
+			expectedStatus: http.StatusOK,
+			expectedTitles: []string{"A Job", "B Job"},
+		},
+		{
+			name:           "filter by recruiter with limit",
+			query:          "?recruiterId=1&sort=-title&limit=1",
+			expectedStatus: http.StatusOK,
+			expectedTitles: []string{"B Job"},
+		},
+		{
+			name:           "invalid sort field",
+			query:          "?sort=salary",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid page",
+			query:          "?page=zero",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid posted date",
+			query:          "?postedAfter=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/jobs"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var page models.JobPage
+			if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(page.Items) != len(tt.expectedTitles) {
+				t.Fatalf("Expected %d jobs, got %d", len(tt.expectedTitles), len(page.Items))
+			}
+			for i, title := range tt.expectedTitles {
+				if page.Items[i].Title != title {
+					t.Errorf("Expected job %d to be %q, got %q", i, title, page.Items[i].Title)
+				}
+			}
+		})
+	}
+}
+
+func TestJobHandler_SearchJobs(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	mockKafka := tests.NewMockKafkaPublisher()
+	service := services.JobService{JobRepo: mockRepo}
+	handler := handlers.JobHandler{
+		JobService:     service,
+		KafkaPublisher: mockKafka,
+	}
+
+	mockRepo.CreateJob(&models.Job{
+		Title:       "Backend Engineer",
+		Overview:    "Build APIs in Go",
+		Description: "Work on our Go services",
+		Skills:      "Go, Postgres",
+	})
+	mockRepo.CreateJob(&models.Job{
+		Title:       "Go Developer",
+		Overview:    "Write Go code",
+		Description: "Maintain Go services",
+		Skills:      "Go, Kafka",
+	})
+	mockRepo.CreateJob(&models.Job{
+		Title:       "Frontend Engineer",
+		Overview:    "Build UIs",
+		Description: "React work",
+		Skills:      "React, TypeScript",
+	})
+
+	router := setupTestRouter(&handler)
+
+	t.Run("ranks and highlights matches", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/jobs/search?q=go", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		var page models.JobSearchPage
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if page.Total != 2 {
+			t.Fatalf("Expected 2 matching jobs, got %d", page.Total)
+		}
+		if page.Items[0].Title != "Go Developer" {
+			t.Errorf("Expected title match to rank first, got %q", page.Items[0].Title)
+		}
+		if !strings.Contains(page.Items[0].Snippet, "<mark>") {
+			t.Errorf("Expected highlighted snippet, got %q", page.Items[0].Snippet)
+		}
+	})
+
+	t.Run("all terms must match", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/jobs/search?q=go+kafka", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var page models.JobSearchPage
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if page.Total != 1 {
+			t.Errorf("Expected 1 matching job, got %d", page.Total)
+		}
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/jobs/search?q=", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestJobHandler_PublicRoutesHideUnlistedJobs(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	handler := handlers.JobHandler{
+		JobService:     services.JobService{JobRepo: mockRepo},
+		KafkaPublisher: tests.NewMockKafkaPublisher(),
+	}
+	router := setupTestRouter(&handler)
+
+	jobs := map[models.JobStatus]*models.Job{}
+	for _, status := range []models.JobStatus{models.Open, models.Draft, models.Paused, models.Archived} {
+		job := &models.Job{Title: status.String() + " Go job", Skills: "Go", Status: status}
+		mockRepo.CreateJob(job)
+		jobs[status] = job
+	}
+
+	t.Run("listing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/jobs", nil))
+		var page models.JobPage
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if page.Total != 2 {
+			t.Fatalf("Expected the open and paused jobs only, got %d: %+v", page.Total, page.Items)
+		}
+		for _, job := range page.Items {
+			if job.Status == models.Draft || job.Status == models.Archived {
+				t.Errorf("Expected %s job %q not to be listed", job.Status, job.Title)
+			}
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/jobs/search?q=go", nil))
+		var page models.JobSearchPage
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if page.Total != 2 {
+			t.Fatalf("Expected the open and paused jobs only, got %d", page.Total)
+		}
+		for _, job := range page.Items {
+			if job.Status == models.Draft || job.Status == models.Archived {
+				t.Errorf("Expected %s job %q not to be found", job.Status, job.Title)
+			}
+		}
+	})
+
+	cases := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"open job", fmt.Sprintf("/jobs/%d", jobs[models.Open].ID), http.StatusOK},
+		{"draft job", fmt.Sprintf("/jobs/%d", jobs[models.Draft].ID), http.StatusNotFound},
+		{"archived job", fmt.Sprintf("/jobs/%d", jobs[models.Archived].ID), http.StatusNotFound},
+		{"listing drafts", "/jobs?status=draft", http.StatusBadRequest},
+		{"searching archived jobs", "/jobs/search?q=go&status=archived", http.StatusBadRequest},
+		{"listing paused jobs", "/jobs?status=paused", http.StatusOK},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest("GET", tt.url, nil))
+			if rr.Code != tt.status {
+				t.Errorf("Expected %v, got %v: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
 	}
 }
 
@@ -186,9 +432,10 @@ func TestJobHandler_UpdateJob(t *testing.T) {
 
 	// Create a test job
 	job := &models.Job{
-		Title:    "Original Title",
-		Overview: "Original Overview",
-		Company:  "Original Company",
+		Title:     "Original Title",
+		Overview:  "Original Overview",
+		Company:   "Original Company",
+		CompanyID: 1,
 	}
 	mockRepo.CreateJob(job)
 
@@ -203,6 +450,8 @@ func TestJobHandler_UpdateJob(t *testing.T) {
 	router := setupTestRouter(&handler)
 	req := httptest.NewRequest("PUT", "/jobs/1", bytes.NewBuffer(jobJSON))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
+	req = withUserInfo(req, 1)
 	rr := httptest.NewRecorder()
 
 	router.ServeHTTP(rr, req)
@@ -221,6 +470,419 @@ func TestJobHandler_UpdateJob(t *testing.T) {
 	}
 }
 
+func TestJobHandler_PatchJob(t *testing.T) {
+	postedDate := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name           string
+		contentType    string
+		patch          string
+		expectedStatus int
+		expectPublish  bool
+		check          func(*testing.T, models.Job)
+	}{
+		{
+			name:           "updates only the fields sent",
+			contentType:    "application/merge-patch+json",
+			patch:          `{"title":"Senior Engineer","salaryRange":"$150,000 - $180,000"}`,
+			expectedStatus: http.StatusOK,
+			expectPublish:  true,
+			check: func(t *testing.T, job models.Job) {
+				if job.Title != "Senior Engineer" || job.SalaryRange != "$150,000 - $180,000" {
+					t.Errorf("Expected patched fields to change, got %q and %q", job.Title, job.SalaryRange)
+				}
+				if job.Overview != "Original Overview" || job.RecruiterId != 7 || job.CompanyID != 1 {
+					t.Errorf("Expected untouched fields to be preserved, got %+v", job)
+				}
+				if !job.PostedDate.Equal(postedDate) {
+					t.Errorf("Expected posted date to be preserved, got %v", job.PostedDate)
+				}
+			},
+		},
+		{
+			name:           "null removes a field",
+			contentType:    "application/merge-patch+json",
+			patch:          `{"location":null}`,
+			expectedStatus: http.StatusOK,
+			expectPublish:  true,
+			check: func(t *testing.T, job models.Job) {
+				if job.Location != "" {
+					t.Errorf("Expected location to be cleared, got %q", job.Location)
+				}
+			},
+		},
+		{
+			name:           "unchanged job is not republished",
+			contentType:    "application/json",
+			patch:          `{"title":"Original Title","companyId":1}`,
+			expectedStatus: http.StatusOK,
+			expectPublish:  false,
+		},
+		{
+			name:           "company is immutable",
+			contentType:    "application/merge-patch+json",
+			patch:          `{"companyId":2}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "id is immutable regardless of case",
+			contentType:    "application/merge-patch+json",
+			patch:          `{"id":5}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "creation time is immutable",
+			contentType:    "application/merge-patch+json",
+			patch:          `{"CreatedAt":"2020-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid status transition",
+			contentType:    "application/merge-patch+json",
+			patch:          `{"status":"archived"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "patch must be an object",
+			contentType:    "application/merge-patch+json",
+			patch:          `["title"]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported content type",
+			contentType:    "text/plain",
+			patch:          `{"title":"Text"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := tests.NewMockJobRepo()
+			mockKafka := tests.NewMockKafkaPublisher()
+			service := services.JobService{JobRepo: mockRepo}
+			handler := handlers.JobHandler{
+				JobService:     service,
+				KafkaPublisher: mockKafka,
+			}
+
+			mockRepo.CreateJob(&models.Job{
+				Title:       "Original Title",
+				Overview:    "Original Overview",
+				Location:    "Remote",
+				CompanyID:   1,
+				RecruiterId: 7,
+				PostedDate:  postedDate,
+				SalaryRange: "$120,000 - $160,000",
+			})
+
+			router := setupTestRouter(&handler)
+			req := httptest.NewRequest("PATCH", "/jobs/1", bytes.NewBufferString(tt.patch))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("If-Match", `"1"`)
+			req = withUserInfo(req, 1)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v (%s)", rr.Code, tt.expectedStatus, rr.Body.String())
+			}
+
+			published := len(mockKafka.(*tests.MockKafkaPublisher).GetPublishedJobs())
+			if tt.expectPublish && published != 1 {
+				t.Errorf("Expected patched job to be published to Kafka, got %d messages", published)
+			}
+			if !tt.expectPublish && published != 0 {
+				t.Errorf("Expected no Kafka messages, got %d", published)
+			}
+
+			if tt.check != nil {
+				var job models.Job
+				if err := json.NewDecoder(rr.Body).Decode(&job); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				tt.check(t, job)
+			}
+		})
+	}
+}
+
+func TestJobHandler_ETags(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	mockKafka := tests.NewMockKafkaPublisher()
+	service := services.JobService{JobRepo: mockRepo}
+	handler := handlers.JobHandler{
+		JobService:     service,
+		KafkaPublisher: mockKafka,
+	}
+
+	mockRepo.CreateJob(&models.Job{Title: "Versioned Job", CompanyID: 1})
+	router := setupTestRouter(&handler)
+
+	// GET returns the current version as an ETag
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/jobs/1", nil))
+	etag := rr.Header().Get("ETag")
+	if etag != `"1"` {
+		t.Fatalf("Expected ETag \"1\", got %q", etag)
+	}
+
+	// a matching If-None-Match answers 304 without a body
+	req := httptest.NewRequest("GET", "/jobs/1", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("Expected 304 with empty body, got %v with %q", rr.Code, rr.Body.String())
+	}
+
+	// writes without If-Match are rejected
+	req = withUserInfo(httptest.NewRequest("PATCH", "/jobs/1", bytes.NewBufferString(`{"title":"No precondition"}`)), 1)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected 428 without If-Match, got %v", rr.Code)
+	}
+
+	// the first writer wins and gets the new ETag
+	req = withUserInfo(httptest.NewRequest("PATCH", "/jobs/1", bytes.NewBufferString(`{"title":"First"}`)), 1)
+	req.Header.Set("If-Match", etag)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected first patch to succeed, got %v", rr.Code)
+	}
+	if newETag := rr.Header().Get("ETag"); newETag != `"2"` {
+		t.Errorf("Expected ETag \"2\" after update, got %q", newETag)
+	}
+
+	// a second writer holding the old ETag gets 412
+	updatedJSON, _ := json.Marshal(models.Job{Title: "Second"})
+	req = withUserInfo(httptest.NewRequest("PUT", "/jobs/1", bytes.NewBuffer(updatedJSON)), 1)
+	req.Header.Set("If-Match", etag)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for stale If-Match, got %v", rr.Code)
+	}
+
+	// the stale ETag no longer matches If-None-Match
+	req = httptest.NewRequest("GET", "/jobs/1", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected 200 for stale If-None-Match, got %v", rr.Code)
+	}
+}
+
+func TestJobHandler_StatusTransitions(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	mockKafka := tests.NewMockKafkaPublisher()
+	service := services.JobService{JobRepo: mockRepo}
+	handler := handlers.JobHandler{
+		JobService:     service,
+		KafkaPublisher: mockKafka,
+	}
+
+	mockRepo.CreateJob(&models.Job{Title: "Draft Job", Status: models.Draft, CompanyID: 1})
+	router := setupTestRouter(&handler)
+
+	steps := []struct {
+		action         string
+		expectedStatus int
+		expectedState  string
+	}{
+		{action: "pause", expectedStatus: http.StatusConflict},
+		{action: "publish", expectedStatus: http.StatusOK, expectedState: "open"},
+		{action: "pause", expectedStatus: http.StatusOK, expectedState: "paused"},
+		{action: "close", expectedStatus: http.StatusOK, expectedState: "closed"},
+		{action: "publish", expectedStatus: http.StatusConflict},
+	}
+
+	for _, step := range steps {
+		req := withUserInfo(httptest.NewRequest("POST", "/jobs/1/"+step.action, nil), 1)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != step.expectedStatus {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v", step.action, rr.Code, step.expectedStatus)
+		}
+		if step.expectedStatus != http.StatusOK {
+			continue
+		}
+
+		var response map[string]interface{}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response["status"] != step.expectedState {
+			t.Errorf("%s: expected status %q, got %v", step.action, step.expectedState, response["status"])
+		}
+	}
+
+	mockPub := mockKafka.(*tests.MockKafkaPublisher)
+	if published := len(mockPub.GetPublishedJobs()); published != 3 {
+		t.Errorf("Expected 3 status changes published to Kafka, got %d", published)
+	}
+
+	req := withUserInfo(httptest.NewRequest("POST", "/jobs/99/publish", nil), 1)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown job, got %v", rr.Code)
+	}
+}
+
+func TestJobHandler_Revisions(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	mockKafka := tests.NewMockKafkaPublisher()
+	service := services.JobService{JobRepo: mockRepo, RevisionRepo: tests.NewMockJobRevisionRepo()}
+	handler := handlers.JobHandler{
+		JobService:     service,
+		KafkaPublisher: mockKafka,
+	}
+	router := setupTestRouter(&handler)
+
+	createJSON, _ := json.Marshal(models.Job{Title: "Data Engineer", SalaryRange: "$90,000"})
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("POST", "/jobs", bytes.NewBuffer(createJSON)), 1))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected job to be created, got %v", rr.Code)
+	}
+
+	req := withUserInfo(httptest.NewRequest("PATCH", "/jobs/1", bytes.NewBufferString(`{"salaryRange":"$110,000"}`)), 1)
+	req.Header.Set("If-Match", `"1"`)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected patch to succeed, got %v", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/jobs/1/revisions", nil), 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 listing revisions, got %v", rr.Code)
+	}
+	var revisions []models.JobRevision
+	if err := json.NewDecoder(rr.Body).Decode(&revisions); err != nil {
+		t.Fatalf("Failed to decode revisions: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Revision != 2 || revisions[0].EditorID != 10 {
+		t.Errorf("Unexpected revisions: %+v", revisions)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/jobs/1/revisions/2/diff", nil), 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for diff, got %v", rr.Code)
+	}
+	var diff models.JobRevisionDiff
+	if err := json.NewDecoder(rr.Body).Decode(&diff); err != nil {
+		t.Fatalf("Failed to decode diff: %v", err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Field != "salaryRange" || diff.Changes[0].New != "$110,000" {
+		t.Errorf("Unexpected diff: %+v", diff)
+	}
+
+	cases := []struct {
+		name     string
+		path     string
+		orgID    int
+		wantCode int
+	}{
+		{"unknown revision", "/jobs/1/revisions/9/diff", 1, http.StatusNotFound},
+		{"invalid revision", "/jobs/1/revisions/abc/diff", 1, http.StatusBadRequest},
+		{"unknown job", "/jobs/99/revisions", 1, http.StatusNotFound},
+		{"other company's job", "/jobs/1/revisions", 2, http.StatusForbidden},
+		{"missing user", "/jobs/1/revisions", 0, http.StatusUnauthorized},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.orgID != 0 {
+				req = withUserInfo(req, tt.orgID)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Errorf("got %v want %v", rr.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestJobHandler_ClosingCascadesToApplications(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	appRepo := NewMockApplicationRepo()
+	service := services.JobService{JobRepo: mockRepo, AppRepo: appRepo}
+	handler := handlers.JobHandler{
+		JobService:     service,
+		KafkaPublisher: tests.NewMockKafkaPublisher(),
+	}
+	router := setupTestRouter(&handler)
+
+	mockRepo.CreateJob(&models.Job{Title: "Closing Job", CompanyID: 1, Status: models.Open})
+	mockRepo.CreateJob(&models.Job{Title: "Deleted Job", CompanyID: 1, Status: models.Open})
+	applications := []*models.Application{
+		{ApplicationID: "a1", JobID: 1, CandidateID: 1, Status: models.Status{CurrentStage: "Applied"}},
+		{ApplicationID: "a2", JobID: 1, CandidateID: 2, Status: models.Status{CurrentStage: "Hired"}},
+		{ApplicationID: "a3", JobID: 2, CandidateID: 1, Status: models.Status{CurrentStage: "Interview"}},
+	}
+	for _, app := range applications {
+		appRepo.CreateApplication(app)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("POST", "/jobs/1/close", nil), 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected job to close, got %v", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("DELETE", "/jobs/2", nil), 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected job to be deleted, got %v", rr.Code)
+	}
+
+	expected := map[string][2]string{
+		"a1": {models.StageJobClosed, "job closed"},
+		"a2": {"Hired", ""},
+		"a3": {models.StageJobClosed, "job deleted"},
+	}
+	for id, want := range expected {
+		app, _ := appRepo.GetApplicationByID(id)
+		if app.Status.CurrentStage != want[0] || app.Status.Reason != want[1] {
+			t.Errorf("Application %s: expected stage %q with reason %q, got %+v", id, want[0], want[1], app.Status)
+		}
+	}
+}
+
+func TestJobStatus_JSON(t *testing.T) {
+	var job models.Job
+	if err := json.Unmarshal([]byte(`{"title":"Legacy","status":1}`), &job); err != nil {
+		t.Fatalf("Failed to decode legacy numeric status: %v", err)
+	}
+	if job.Status != models.Closed {
+		t.Errorf("Expected legacy status 1 to decode as closed, got %s", job.Status)
+	}
+
+	if err := json.Unmarshal([]byte(`{"title":"New","status":"Paused"}`), &job); err != nil {
+		t.Fatalf("Failed to decode string status: %v", err)
+	}
+	if job.Status != models.Paused {
+		t.Errorf("Expected paused status, got %s", job.Status)
+	}
+
+	if err := json.Unmarshal([]byte(`{"status":"deleted"}`), &job); err == nil {
+		t.Error("Expected unknown status to be rejected")
+	}
+
+	encoded, _ := json.Marshal(models.Job{Status: models.Archived})
+	if !strings.Contains(string(encoded), `"status":"archived"`) {
+		t.Errorf("Expected status to serialize as a string, got %s", encoded)
+	}
+}
+
 func TestJobHandler_DeleteJob(t *testing.T) {
 	mockRepo := tests.NewMockJobRepo()
 	mockKafka := tests.NewMockKafkaPublisher()
@@ -232,13 +894,14 @@ func TestJobHandler_DeleteJob(t *testing.T) {
 
 	// Create a test job
 	job := &models.Job{
-		Title:    "Test Job",
-		Overview: "Test Overview",
+		Title:     "Test Job",
+		Overview:  "Test Overview",
+		CompanyID: 1,
 	}
 	mockRepo.CreateJob(job)
 
 	router := setupTestRouter(&handler)
-	req := httptest.NewRequest("DELETE", "/jobs/1", nil)
+	req := withUserInfo(httptest.NewRequest("DELETE", "/jobs/1", nil), 1)
 	rr := httptest.NewRecorder()
 
 	router.ServeHTTP(rr, req)
@@ -252,6 +915,140 @@ func TestJobHandler_DeleteJob(t *testing.T) {
 	if deletedJob != nil {
 		t.Error("Expected job to be deleted but it still exists")
 	}
+
+	events := mockKafka.(*tests.MockKafkaPublisher).GetPublishedJobEvents()
+	if len(events) != 1 || events[0].Event != kafka.JobEventDeleted || events[0].Job.ID != 1 {
+		t.Errorf("Expected a job.deleted event, got %+v", events)
+	}
+}
+
+func TestJobHandler_DeletedJobs(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	mockKafka := tests.NewMockKafkaPublisher()
+	service := services.JobService{JobRepo: mockRepo}
+	handler := handlers.JobHandler{
+		JobService:     service,
+		KafkaPublisher: mockKafka,
+	}
+	router := setupTestRouter(&handler)
+
+	mockRepo.CreateJob(&models.Job{Title: "Deleted Job", CompanyID: 1})
+	mockRepo.CreateJob(&models.Job{Title: "Expired Job", CompanyID: 1})
+	mockRepo.DeleteJob(1)
+	mockRepo.DeleteJob(2)
+	mockRepo.(*tests.MockJobRepo).SetDeletedAt(2, time.Now().AddDate(0, 0, -60))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/jobs/deleted", nil), 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 listing deleted jobs, got %v", rr.Code)
+	}
+	var deleted []models.Job
+	if err := json.NewDecoder(rr.Body).Decode(&deleted); err != nil {
+		t.Fatalf("Failed to decode deleted jobs: %v", err)
+	}
+	if len(deleted) != 2 {
+		t.Errorf("Expected 2 deleted jobs, got %d", len(deleted))
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/jobs/deleted", nil), 2))
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Errorf("Expected no deleted jobs for another company, got %v %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("POST", "/jobs/1/restore", nil), 2))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 restoring another company's job, got %v", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("POST", "/jobs/1/restore", nil), 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 restoring job, got %v", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/jobs/1", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected restored job to be readable, got %v", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("POST", "/jobs/1/restore", nil), 1))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 restoring a job that is not deleted, got %v", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/admin/jobs/purge", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 purging jobs, got %v", rr.Code)
+	}
+	var purge struct {
+		Purged int    `json:"purged"`
+		JobIDs []uint `json:"jobIds"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&purge); err != nil {
+		t.Fatalf("Failed to decode purge response: %v", err)
+	}
+	if purge.Purged != 1 || len(purge.JobIDs) != 1 || purge.JobIDs[0] != 2 {
+		t.Errorf("Expected only job 2 to be purged, got %+v", purge)
+	}
+
+	events := mockKafka.(*tests.MockKafkaPublisher).GetPublishedJobEvents()
+	if len(events) != 2 || events[0].Event != kafka.JobEventRestored || events[1].Event != kafka.JobEventPurged {
+		t.Errorf("Expected restored and purged events, got %+v", events)
+	}
+}
+
+func TestJobHandler_OwnershipChecks(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	mockKafka := tests.NewMockKafkaPublisher()
+	service := services.JobService{JobRepo: mockRepo}
+	handler := handlers.JobHandler{
+		JobService:     service,
+		KafkaPublisher: mockKafka,
+	}
+
+	mockRepo.CreateJob(&models.Job{Title: "Owned Job", CompanyID: 1})
+	router := setupTestRouter(&handler)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		orgID          int
+		expectedStatus int
+	}{
+		{name: "update without auth", method: "PUT", path: "/jobs/1", expectedStatus: http.StatusUnauthorized},
+		{name: "update other company's job", method: "PUT", path: "/jobs/1", orgID: 2, expectedStatus: http.StatusForbidden},
+		{name: "delete other company's job", method: "DELETE", path: "/jobs/1", orgID: 2, expectedStatus: http.StatusForbidden},
+		{name: "delete missing job", method: "DELETE", path: "/jobs/42", orgID: 1, expectedStatus: http.StatusNotFound},
+		{name: "close other company's job", method: "POST", path: "/jobs/1/close", orgID: 2, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(`{"title":"Changed"}`))
+			req.Header.Set("If-Match", `"1"`)
+			if tt.orgID != 0 {
+				req = withUserInfo(req, tt.orgID)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tt.expectedStatus)
+			}
+		})
+	}
+
+	job, _ := service.GetJobByID(1)
+	if job == nil || job.Title != "Owned Job" {
+		t.Errorf("Expected job to be untouched, got %+v", job)
+	}
 }
 
 func TestJobHandler_GetJobsByIDs(t *testing.T) {
@@ -305,3 +1102,16 @@ func TestJobHandler_GetJobsByIDs(t *testing.T) {
 		t.Errorf("Expected 2 job summaries, got %d", len(response))
 	}
 }
+
+// withUserInfo adds the user the auth middleware would set for a member of orgID
+func withUserInfo(req *http.Request, orgID int) *http.Request {
+	userInfo := &clients.UserResponse{
+		ID:    orgID * 10,
+		Email: "recruiter@example.com",
+		Org: &clients.Org{
+			ID:   orgID,
+			Name: "Test Company",
+		},
+	}
+	return req.WithContext(context.WithValue(req.Context(), "userInfo", userInfo))
+}
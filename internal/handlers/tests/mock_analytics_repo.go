@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"sort"
+	"time"
+)
+
+// MockAnalyticsRepo is an in-memory stand-in for the analytics aggregations, reading
+// the applications held by a MockApplicationRepo
+type MockAnalyticsRepo struct {
+	apps *MockApplicationRepo
+}
+
+func NewMockAnalyticsRepo(appRepo repos.ApplicationRepoInterface) repos.AnalyticsRepoInterface {
+	return &MockAnalyticsRepo{apps: appRepo.(*MockApplicationRepo)}
+}
+
+// GetStageVisits replays stage histories the way the Mongo aggregation does
+func (m *MockAnalyticsRepo) GetStageVisits(filter models.FunnelFilter) ([]models.StageVisitGroup, error) {
+	m.apps.mu.RLock()
+	defer m.apps.mu.RUnlock()
+
+	type visitKey struct{ stage, next string }
+	groups := make(map[visitKey]*models.StageVisitGroup)
+	for _, app := range m.apps.applications {
+		if !matchesFunnelFilter(app, filter) {
+			continue
+		}
+
+		history := app.StageHistory
+		if len(history) == 0 {
+			history = []models.StageChange{{To: app.Status.CurrentStage, ChangedAt: app.AppliedAt}}
+		}
+		for i, visit := range history {
+			key := visitKey{stage: visit.To}
+			if i+1 < len(history) {
+				key.next = history[i+1].To
+			}
+			group, ok := groups[key]
+			if !ok {
+				group = &models.StageVisitGroup{Stage: key.stage, Next: key.next}
+				groups[key] = group
+			}
+			group.Count++
+			if i+1 < len(history) {
+				group.Durations = append(group.Durations, history[i+1].ChangedAt.Sub(visit.ChangedAt).Milliseconds())
+			}
+			group.SinceApplied = append(group.SinceApplied, visit.ChangedAt.Sub(app.AppliedAt).Milliseconds())
+		}
+	}
+
+	result := make([]models.StageVisitGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Stage != result[j].Stage {
+			return result[i].Stage < result[j].Stage
+		}
+		return result[i].Next < result[j].Next
+	})
+	return result, nil
+}
+
+func (m *MockAnalyticsRepo) CreateIndexes() error {
+	return nil
+}
+
+// GetRejectionReasons counts rejected applications by reason the way the Mongo aggregation does
+func (m *MockAnalyticsRepo) GetRejectionReasons(filter models.FunnelFilter) ([]models.RejectionReasonCount, error) {
+	m.apps.mu.RLock()
+	defer m.apps.mu.RUnlock()
+
+	counts := make(map[string]*models.RejectionReasonCount)
+	latest := make(map[string]time.Time)
+	for _, app := range m.apps.applications {
+		if app.Disposition == nil || !matchesFunnelFilter(app, filter) {
+			continue
+		}
+		code := app.Disposition.ReasonCode
+		count, ok := counts[code]
+		if !ok {
+			count = &models.RejectionReasonCount{Code: code}
+			counts[code] = count
+		}
+		count.Count++
+		if !app.Disposition.DecidedAt.Before(latest[code]) {
+			count.Label = app.Disposition.ReasonLabel
+			latest[code] = app.Disposition.DecidedAt
+		}
+	}
+
+	result := make([]models.RejectionReasonCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	return result, nil
+}
+
+func matchesFunnelFilter(app *models.Application, filter models.FunnelFilter) bool {
+	if filter.JobID != 0 && uint(app.JobID) != filter.JobID {
+		return false
+	}
+	if filter.JobID == 0 && filter.CompanyID != 0 && uint(app.CompanyID) != filter.CompanyID {
+		return false
+	}
+	if filter.AppliedAfter != nil && app.AppliedAt.Before(*filter.AppliedAfter) {
+		return false
+	}
+	if filter.AppliedBefore != nil && app.AppliedAt.After(*filter.AppliedBefore) {
+		return false
+	}
+	return true
+}
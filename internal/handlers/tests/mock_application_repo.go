@@ -1,89 +1,423 @@
 package tests
 
 import (
+	"cmp"
+	"jobs-svc/internal/models"
 	"jobs-svc/internal/repos"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type MockApplicationRepo struct {
-	applications map[string]bson.M
+	applications map[string]*models.Application
 	mu           sync.RWMutex
 }
 
 func NewMockApplicationRepo() repos.ApplicationRepoInterface {
 	return &MockApplicationRepo{
-		applications: make(map[string]bson.M),
+		applications: make(map[string]*models.Application),
 	}
 }
 
-func (m *MockApplicationRepo) CreateApplication(application bson.M) error {
+func (m *MockApplicationRepo) CreateApplication(application *models.Application) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Check for duplicate application
-	jobID := application["job_id"]
-	candidateID := application["candidate_id"]
+	// Check for duplicate application, like the partial unique index on active applications
 	for _, app := range m.applications {
-		if app["job_id"] == jobID && app["candidate_id"] == candidateID {
+		if app.Active && app.JobID == application.JobID && app.CandidateID == application.CandidateID {
 			return repos.ErrDuplicateApplication
 		}
 	}
 
-	appID := application["application_id"].(string)
-	m.applications[appID] = application
+	stored := *application
+	m.applications[application.ApplicationID] = &stored
 	return nil
 }
 
-func (m *MockApplicationRepo) GetApplicationsByJobID(jobID uint) ([]bson.M, error) {
+// GetApplicationsByJobID is an in-memory stand-in for the paged Mongo query: it filters,
+// sorts by the sort field and application ID, and skips past the cursor
+func (m *MockApplicationRepo) GetApplicationsByJobID(jobID uint, opts models.ApplicationQueryOptions) ([]models.Application, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	var applications []bson.M
+	applications := make([]models.Application, 0)
 	for _, app := range m.applications {
-		if app["job_id"] == jobID {
-			applications = append(applications, app)
+		if uint(app.JobID) == jobID && matchesApplicationQuery(app, opts) {
+			applications = append(applications, *app)
 		}
 	}
+
+	sort.Slice(applications, func(i, j int) bool {
+		return compareApplications(applications[i], applications[j], opts) < 0
+	})
+	if opts.After != nil {
+		cursor := models.Application{ApplicationID: opts.After.ApplicationID}
+		start := len(applications)
+		for i, app := range applications {
+			if compareApplications(app, cursorApplication(cursor, opts), opts) > 0 {
+				start = i
+				break
+			}
+		}
+		applications = applications[start:]
+	}
+	if opts.Limit > 0 && len(applications) > opts.Limit+1 {
+		applications = applications[:opts.Limit+1]
+	}
 	return applications, nil
 }
 
-func (m *MockApplicationRepo) GetApplicationByID(applicationID string) (bson.M, error) {
+// GetJobBoard groups a job's applications by stage the way the Mongo aggregation does,
+// newest first, keeping up to cardsPerStage+1 cards per stage
+func (m *MockApplicationRepo) GetJobBoard(jobID uint, cardsPerStage int) ([]models.BoardColumn, error) {
+	applications, err := m.GetApplicationsByJobID(jobID, models.ApplicationQueryOptions{SortBy: "appliedAt", SortDesc: true})
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]models.BoardColumn, 0)
+	index := make(map[string]int)
+	for _, app := range applications {
+		i, ok := index[app.Status.CurrentStage]
+		if !ok {
+			i = len(columns)
+			index[app.Status.CurrentStage] = i
+			columns = append(columns, models.BoardColumn{Stage: app.Status.CurrentStage})
+		}
+		columns[i].Count++
+		if len(columns[i].Cards) <= cardsPerStage {
+			columns[i].Cards = append(columns[i].Cards, models.ApplicationCard{
+				ApplicationID: app.ApplicationID,
+				CandidateID:   app.CandidateID,
+				Email:         app.Email,
+				AppliedAt:     app.AppliedAt,
+				LastUpdated:   app.Status.LastUpdated,
+				Tags:          app.Tags,
+				Rating:        app.Rating,
+			})
+		}
+	}
+	return columns, nil
+}
+
+func matchesApplicationQuery(app *models.Application, opts models.ApplicationQueryOptions) bool {
+	if len(opts.Stages) > 0 && !slices.Contains(opts.Stages, app.Status.CurrentStage) {
+		return false
+	}
+	for _, tag := range opts.Tags {
+		if !slices.Contains(app.Tags, tag) {
+			return false
+		}
+	}
+	if opts.AppliedAfter != nil && app.AppliedAt.Before(*opts.AppliedAfter) {
+		return false
+	}
+	if opts.AppliedBefore != nil && app.AppliedAt.After(*opts.AppliedBefore) {
+		return false
+	}
+	if (opts.MinRating != nil || opts.MaxRating != nil) && app.Rating == nil {
+		return false
+	}
+	if opts.MinRating != nil && *app.Rating < *opts.MinRating {
+		return false
+	}
+	if opts.MaxRating != nil && *app.Rating > *opts.MaxRating {
+		return false
+	}
+	return true
+}
+
+// cursorApplication builds an application that sorts exactly where the cursor points
+func cursorApplication(app models.Application, opts models.ApplicationQueryOptions) models.Application {
+	switch value := opts.After.Value.(type) {
+	case time.Time:
+		app.AppliedAt = value
+		app.Status.LastUpdated = value
+	case float64:
+		app.Rating = &value
+		app.Match = &models.ResumeMatch{Score: value}
+	}
+	return app
+}
+
+// compareApplications orders applications the way MongoDB sorts them, with missing
+// ratings and match scores before any other value, then breaks ties on the application ID
+func compareApplications(a, b models.Application, opts models.ApplicationQueryOptions) int {
+	result := 0
+	switch av, bv := a.SortValue(opts.SortBy), b.SortValue(opts.SortBy); {
+	case av == nil && bv == nil:
+	case av == nil:
+		result = -1
+	case bv == nil:
+		result = 1
+	default:
+		switch av := av.(type) {
+		case time.Time:
+			result = av.Compare(bv.(time.Time))
+		case float64:
+			result = cmp.Compare(av, bv.(float64))
+		}
+	}
+	if result == 0 {
+		result = strings.Compare(a.ApplicationID, b.ApplicationID)
+	}
+	if opts.SortDesc {
+		return -result
+	}
+	return result
+}
+
+func (m *MockApplicationRepo) GetApplicationByID(applicationID string) (*models.Application, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	if app, exists := m.applications[applicationID]; exists {
-		return app, nil
+		found := *app
+		return &found, nil
 	}
 	return nil, nil
 }
 
-func (m *MockApplicationRepo) GetApplicationsByCandidateID(candidateID uint) ([]bson.M, error) {
+func (m *MockApplicationRepo) GetApplicationsByIDs(applicationIDs []string) ([]models.Application, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	applications := make([]models.Application, 0, len(applicationIDs))
+	for _, id := range applicationIDs {
+		if app, exists := m.applications[id]; exists {
+			applications = append(applications, *app)
+		}
+	}
+	return applications, nil
+}
+
+func (m *MockApplicationRepo) GetApplicationsByCandidateID(candidateID uint) ([]models.Application, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	var applications []bson.M
+	applications := make([]models.Application, 0)
 	for _, app := range m.applications {
-		if app["candidate_id"] == candidateID {
-			applications = append(applications, app)
+		if uint(app.CandidateID) == candidateID {
+			applications = append(applications, *app)
 		}
 	}
 	return applications, nil
 }
 
-func (m *MockApplicationRepo) GetApplicationByCandidateID(candidateID uint) (bson.M, error) {
+func (m *MockApplicationRepo) GetApplicationByCandidateID(candidateID uint) (*models.Application, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	for _, app := range m.applications {
-		if app["candidate_id"] == candidateID {
-			return app, nil
+		if uint(app.CandidateID) == candidateID {
+			found := *app
+			return &found, nil
 		}
 	}
 	return nil, nil
 }
 
+func (m *MockApplicationRepo) GetLatestApplication(candidateID uint, jobID uint) (*models.Application, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// an active application wins, otherwise the most recently withdrawn one
+	var latest *models.Application
+	for _, app := range m.applications {
+		if uint(app.CandidateID) != candidateID || uint(app.JobID) != jobID {
+			continue
+		}
+		if app.Active {
+			latest = app
+			break
+		}
+		if latest == nil || (app.WithdrawnAt != nil && (latest.WithdrawnAt == nil || app.WithdrawnAt.After(*latest.WithdrawnAt))) {
+			latest = app
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	found := *latest
+	return &found, nil
+}
+
+func (m *MockApplicationRepo) UpdateStage(applicationID string, change models.StageChange) (*models.Application, error) {
+	return m.updateStage(applicationID, change, nil)
+}
+
+func (m *MockApplicationRepo) WithdrawApplication(applicationID string, change models.StageChange) (*models.Application, error) {
+	return m.updateStage(applicationID, change, func(app *models.Application) {
+		withdrawnAt := change.ChangedAt
+		app.Active = false
+		app.WithdrawnAt = &withdrawnAt
+	})
+}
+
+func (m *MockApplicationRepo) RejectApplication(applicationID string, change models.StageChange, disposition models.Disposition) (*models.Application, error) {
+	return m.updateStage(applicationID, change, func(app *models.Application) {
+		app.Disposition = &disposition
+	})
+}
+
+func (m *MockApplicationRepo) RevertStage(previous *models.Application, change models.StageChange) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	app, exists := m.applications[previous.ApplicationID]
+	if !exists || app.Status.CurrentStage != change.To {
+		return repos.ErrStageConflict
+	}
+	app.Status = previous.Status
+	app.Active = previous.Active
+	app.WithdrawnAt = previous.WithdrawnAt
+	if len(app.StageHistory) > 0 {
+		app.StageHistory = app.StageHistory[:len(app.StageHistory)-1]
+	}
+	return nil
+}
+
+// updateStage applies a stage change guarded by the expected from-stage, letting apply set any other fields with it
+func (m *MockApplicationRepo) updateStage(applicationID string, change models.StageChange, apply func(*models.Application)) (*models.Application, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	app, exists := m.applications[applicationID]
+	if !exists || app.Status.CurrentStage != change.From {
+		return nil, repos.ErrStageConflict
+	}
+	app.Status.CurrentStage = change.To
+	app.Status.Reason = change.Reason
+	app.Status.LastUpdated = change.ChangedAt
+	app.StageHistory = append(app.StageHistory, change)
+	if apply != nil {
+		apply(app)
+	}
+	updated := *app
+	return &updated, nil
+}
+
+func (m *MockApplicationRepo) SetResume(applicationID string, resume models.ResumeFile) (*models.Application, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	app, exists := m.applications[applicationID]
+	if !exists {
+		return nil, mongo.ErrNoDocuments
+	}
+	app.Resume = &resume
+	app.ResumeURL = ""
+	updated := *app
+	return &updated, nil
+}
+
+func (m *MockApplicationRepo) SetMatch(applicationID string, match models.ResumeMatch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	app, exists := m.applications[applicationID]
+	if !exists {
+		return mongo.ErrNoDocuments
+	}
+	if (app.Resume == nil) != (match.ResumeSHA256 == "") || app.Resume != nil && app.Resume.SHA256 != match.ResumeSHA256 {
+		return mongo.ErrNoDocuments
+	}
+	app.Match = &match
+	return nil
+}
+
+// BulkUpdateApplications applies each update on its own, leaving out stage changes
+// whose guard no longer matches, like the unordered Mongo bulk write
+func (m *MockApplicationRepo) BulkUpdateApplications(updates []models.ApplicationUpdate) (map[string]*models.Application, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	applied := make(map[string]*models.Application, len(updates))
+	for _, update := range updates {
+		app, exists := m.applications[update.ApplicationID]
+		if !exists || (update.Change != nil && app.Status.CurrentStage != update.Change.From) {
+			continue
+		}
+		if change := update.Change; change != nil {
+			app.Status.CurrentStage = change.To
+			app.Status.Reason = change.Reason
+			app.Status.LastUpdated = change.ChangedAt
+			app.StageHistory = append(app.StageHistory, *change)
+		}
+		if update.Disposition != nil {
+			disposition := *update.Disposition
+			app.Disposition = &disposition
+		}
+		for _, tag := range update.AddTags {
+			if !slices.Contains(app.Tags, tag) {
+				app.Tags = append(app.Tags, tag)
+			}
+		}
+		if len(update.RemoveTags) > 0 {
+			app.Tags = slices.DeleteFunc(slices.Clone(app.Tags), func(tag string) bool {
+				return slices.Contains(update.RemoveTags, tag)
+			})
+		}
+		updated := *app
+		applied[app.ApplicationID] = &updated
+	}
+	return applied, nil
+}
+
+func (m *MockApplicationRepo) SubmitScorecard(applicationID string, scorecard models.Scorecard) (*models.Application, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	app, exists := m.applications[applicationID]
+	if !exists {
+		return nil, mongo.ErrNoDocuments
+	}
+	scorecards := make([]models.Scorecard, 0, len(app.Scorecards)+1)
+	for _, existing := range app.Scorecards {
+		if existing.InterviewerID != scorecard.InterviewerID {
+			scorecards = append(scorecards, existing)
+		}
+	}
+	app.Scorecards = append(scorecards, scorecard)
+	app.Rating = models.AverageRating(app.Scorecards)
+	updated := *app
+	return &updated, nil
+}
+
+func (m *MockApplicationRepo) CloseApplicationsForJob(jobID uint, terminalStages []string, reason string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var closed int64
+	for _, app := range m.applications {
+		if uint(app.JobID) != jobID || slices.Contains(terminalStages, app.Status.CurrentStage) {
+			continue
+		}
+		now := time.Now()
+		app.StageHistory = append(app.StageHistory, models.StageChange{
+			From:      app.Status.CurrentStage,
+			To:        models.StageJobClosed,
+			ChangedAt: now,
+			Reason:    reason,
+		})
+		app.Status.CurrentStage = models.StageJobClosed
+		app.Status.Reason = reason
+		app.Status.LastUpdated = now
+		closed++
+	}
+	return closed, nil
+}
+
 func (m *MockApplicationRepo) CreateUniqueIndex() error {
 	return nil
 }
+
+func (m *MockApplicationRepo) CreateListingIndexes() error {
+	return nil
+}
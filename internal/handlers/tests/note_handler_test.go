@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"jobs-svc/internal/tests"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestNoteRouter(handler *handlers.NoteHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/applications/{id}/notes", handler.CreateNote).Methods("POST")
+	router.HandleFunc("/applications/{id}/notes", handler.GetNotes).Methods("GET")
+	router.HandleFunc("/applications/{id}/notes/{noteId}", handler.UpdateNote).Methods("PUT")
+	router.HandleFunc("/applications/{id}/notes/{noteId}", handler.DeleteNote).Methods("DELETE")
+	return router
+}
+
+// withOrgMember adds the user the auth middleware would set for user userID of orgID
+func withOrgMember(req *http.Request, orgID int, userID int) *http.Request {
+	userInfo := &clients.UserResponse{
+		ID:    userID,
+		Email: "recruiter@example.com",
+		Org:   &clients.Org{ID: orgID, Name: "Test Company"},
+	}
+	return req.WithContext(context.WithValue(req.Context(), "userInfo", userInfo))
+}
+
+func TestNoteHandler_Notes(t *testing.T) {
+	appRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open})
+	appRepo.CreateApplication(&models.Application{ApplicationID: "app1", JobID: 1, CandidateID: 5, CompanyID: 1, Active: true})
+	mockKafka := NewMockKafkaPublisher()
+	handler := handlers.NoteHandler{
+		NoteService:    services.NoteService{NoteRepo: tests.NewMockNoteRepo(), AppRepo: appRepo, JobRepo: jobRepo},
+		KafkaPublisher: mockKafka,
+	}
+	router := setupTestNoteRouter(&handler)
+
+	// send makes a request as user userID of orgID and decodes a note from successful responses
+	send := func(t *testing.T, method, url string, body interface{}, orgID, userID int) (int, models.Note) {
+		t.Helper()
+		var payload bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&payload).Encode(body)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withOrgMember(httptest.NewRequest(method, url, &payload), orgID, userID))
+		var note models.Note
+		if method != "DELETE" && (rr.Code == http.StatusOK || rr.Code == http.StatusCreated) {
+			json.NewDecoder(rr.Body).Decode(&note)
+		}
+		return rr.Code, note
+	}
+	list := func(t *testing.T, userID int) []models.Note {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withOrgMember(httptest.NewRequest("GET", "/applications/app1/notes", nil), 1, userID))
+		var notes []models.Note
+		if err := json.NewDecoder(rr.Body).Decode(&notes); err != nil {
+			t.Fatalf("Failed to decode notes: %v", err)
+		}
+		return notes
+	}
+
+	code, team := send(t, "POST", "/applications/app1/notes", map[string]string{"body": "Strong systems design, cc @11 and @12 (@11 again, and me @10)"}, 1, 10)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %v", code)
+	}
+	if team.Visibility != models.NoteVisibilityTeam || team.AuthorID != 10 || team.JobID != 1 || team.CompanyID != 1 {
+		t.Errorf("Unexpected note: %+v", team)
+	}
+	mentions := mockKafka.(*MockKafkaPublisher).GetPublishedNoteMentions()
+	if len(mentions) != 2 || mentions[0].UserID != 11 || mentions[1].UserID != 12 {
+		t.Errorf("Expected mentions of users 11 and 12, got %+v", mentions)
+	}
+
+	code, private := send(t, "POST", "/applications/app1/notes", map[string]string{"body": "Salary expectations look high", "visibility": "private"}, 1, 10)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %v", code)
+	}
+	if notes := list(t, 10); len(notes) != 2 {
+		t.Errorf("Expected the author to see both notes, got %d", len(notes))
+	}
+	if notes := list(t, 11); len(notes) != 1 || notes[0].ID != team.ID {
+		t.Errorf("Expected a teammate to see only the team note, got %+v", notes)
+	}
+
+	// editing only notifies users the note did not mention before
+	code, updated := send(t, "PUT", "/applications/app1/notes/"+team.ID, map[string]string{"body": "Strong systems design, cc @12 and @13"}, 1, 10)
+	if code != http.StatusOK || len(updated.Mentions) != 2 {
+		t.Fatalf("Expected the note to be updated, got %v: %+v", code, updated)
+	}
+	mentions = mockKafka.(*MockKafkaPublisher).GetPublishedNoteMentions()
+	if len(mentions) != 3 || mentions[2].UserID != 13 {
+		t.Errorf("Expected a single new mention of user 13, got %+v", mentions)
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		body   interface{}
+		orgID  int
+		userID int
+		status int
+	}{
+		{"empty body", "POST", "/applications/app1/notes", map[string]string{"body": "  "}, 1, 10, http.StatusBadRequest},
+		{"unknown visibility", "POST", "/applications/app1/notes", map[string]string{"body": "ok", "visibility": "public"}, 1, 10, http.StatusBadRequest},
+		{"private note with mention", "POST", "/applications/app1/notes", map[string]string{"body": "ask @11", "visibility": "private"}, 1, 10, http.StatusBadRequest},
+		{"unknown application", "POST", "/applications/nope/notes", map[string]string{"body": "ok"}, 1, 10, http.StatusNotFound},
+		{"another company", "GET", "/applications/app1/notes", nil, 2, 20, http.StatusForbidden},
+		{"teammate edits", "PUT", "/applications/app1/notes/" + team.ID, map[string]string{"body": "mine now"}, 1, 11, http.StatusForbidden},
+		{"teammate edits private note", "PUT", "/applications/app1/notes/" + private.ID, map[string]string{"body": "mine now"}, 1, 11, http.StatusNotFound},
+		{"teammate deletes", "DELETE", "/applications/app1/notes/" + team.ID, nil, 1, 11, http.StatusForbidden},
+		{"author deletes", "DELETE", "/applications/app1/notes/" + private.ID, nil, 1, 10, http.StatusOK},
+		{"deleted note", "DELETE", "/applications/app1/notes/" + private.ID, nil, 1, 10, http.StatusNotFound},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _ := send(t, tt.method, tt.url, tt.body, tt.orgID, tt.userID); code != tt.status {
+				t.Errorf("Expected %v, got %v", tt.status, code)
+			}
+		})
+	}
+}
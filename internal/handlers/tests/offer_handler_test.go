@@ -0,0 +1,313 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/kafka"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"jobs-svc/internal/services"
+	"jobs-svc/internal/tests"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestOfferRouter(handler *handlers.OfferHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/applications/{id}/offers", handler.CreateOffer).Methods("POST")
+	router.HandleFunc("/applications/{id}/offers", handler.GetOffers).Methods("GET")
+	router.HandleFunc("/offers/{id}", handler.GetOffer).Methods("GET")
+	router.HandleFunc("/offers/{id}", handler.UpdateOffer).Methods("PUT")
+	router.HandleFunc("/offers/{id}/submit", handler.SubmitOffer).Methods("POST")
+	router.HandleFunc("/offers/{id}/extend", handler.ExtendOffer).Methods("POST")
+	router.HandleFunc("/offers/{id}/accept", handler.AcceptOffer).Methods("POST")
+	router.HandleFunc("/offers/{id}/decline", handler.DeclineOffer).Methods("POST")
+	return router
+}
+
+// failingOfferRepo fails the next offer update to status failOn, as if another request
+// had changed the offer first
+type failingOfferRepo struct {
+	repos.OfferRepoInterface
+	failOn string
+}
+
+func (r *failingOfferRepo) UpdateOffer(offer *models.Offer, from string) error {
+	if r.failOn != "" && offer.Status == r.failOn {
+		r.failOn = ""
+		return repos.ErrOfferConflict
+	}
+	return r.OfferRepoInterface.UpdateOffer(offer, from)
+}
+
+func TestOfferHandler_Offers(t *testing.T) {
+	appRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open})
+	for i, id := range []string{"app1", "app2", "app3", "app4", "app5"} {
+		appRepo.CreateApplication(&models.Application{
+			ApplicationID: id, JobID: 1, CandidateID: models.NumericID(5 + i), CompanyID: 1, Active: true,
+			Status: models.Status{CurrentStage: models.StageInterview},
+		})
+	}
+	mockKafka := NewMockKafkaPublisher()
+	offerRepo := &failingOfferRepo{OfferRepoInterface: tests.NewMockOfferRepo()}
+	handler := handlers.OfferHandler{
+		OfferService: services.OfferService{
+			OfferRepo:    offerRepo,
+			AppRepo:      appRepo,
+			JobRepo:      jobRepo,
+			PipelineRepo: tests.NewMockPipelineRepo(),
+		},
+		KafkaPublisher: mockKafka,
+	}
+	router := setupTestOfferRouter(&handler)
+
+	// send makes a request as the given user and decodes an offer from successful responses
+	send := func(t *testing.T, method, url string, body interface{}, withUser func(*http.Request) *http.Request) (int, models.Offer) {
+		t.Helper()
+		var payload bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&payload).Encode(body)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withUser(httptest.NewRequest(method, url, &payload)))
+		var offer models.Offer
+		if rr.Code == http.StatusOK || rr.Code == http.StatusCreated {
+			json.NewDecoder(rr.Body).Decode(&offer)
+		}
+		return rr.Code, offer
+	}
+	recruiter := func(r *http.Request) *http.Request { return withUserInfo(r, 1) }
+	candidate := func(id int) func(*http.Request) *http.Request {
+		return func(r *http.Request) *http.Request { return withCandidate(r, id) }
+	}
+	terms := func(salary float64, expiresIn time.Duration) map[string]interface{} {
+		expiresAt := time.Now().Add(expiresIn).UTC()
+		return map[string]interface{}{
+			"salary":    salary,
+			"currency":  "eur",
+			"expiresAt": expiresAt,
+			"startDate": expiresAt.Add(30 * 24 * time.Hour),
+		}
+	}
+	// applicationEvents lists the application events published so far as "event app stage"
+	applicationEvents := func() []string {
+		var events []string
+		for _, event := range mockKafka.(*MockKafkaPublisher).GetPublishedApplicationEvents() {
+			events = append(events, event.Event+" "+event.Application.ApplicationID+" "+event.Application.Status.CurrentStage)
+		}
+		return events
+	}
+	stage := func(t *testing.T, applicationID string) *models.Application {
+		t.Helper()
+		app, _ := appRepo.GetApplicationByID(applicationID)
+		return app
+	}
+	// extend takes an offer for applicationID from draft to extended
+	extend := func(t *testing.T, applicationID string, expiresIn time.Duration) models.Offer {
+		t.Helper()
+		code, offer := send(t, "POST", "/applications/"+applicationID+"/offers", terms(90000, expiresIn), recruiter)
+		if code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %v", code)
+		}
+		send(t, "POST", "/offers/"+offer.ID+"/submit", nil, recruiter)
+		if code, offer = send(t, "POST", "/offers/"+offer.ID+"/extend", nil, recruiter); code != http.StatusOK {
+			t.Fatalf("Expected the offer to be extended, got %v", code)
+		}
+		return offer
+	}
+
+	t.Run("accept", func(t *testing.T) {
+		code, offer := send(t, "POST", "/applications/app1/offers", terms(90000, 72*time.Hour), recruiter)
+		if code != http.StatusCreated || offer.Status != models.OfferStatusDraft || offer.Currency != "EUR" || offer.CandidateID != 5 {
+			t.Fatalf("Expected a draft offer, got %v: %+v", code, offer)
+		}
+		if code, _ := send(t, "POST", "/applications/app1/offers", terms(95000, 72*time.Hour), recruiter); code != http.StatusConflict {
+			t.Errorf("Expected 409 for a second open offer, got %v", code)
+		}
+		if code, _ := send(t, "GET", "/offers/"+offer.ID, nil, candidate(5)); code != http.StatusNotFound {
+			t.Errorf("Expected the candidate not to see a draft, got %v", code)
+		}
+		if code, _ := send(t, "POST", "/offers/"+offer.ID+"/extend", nil, recruiter); code != http.StatusConflict {
+			t.Errorf("Expected 409 for extending an unapproved draft, got %v", code)
+		}
+
+		// changing a pending offer sends it back to draft
+		send(t, "POST", "/offers/"+offer.ID+"/submit", nil, recruiter)
+		code, offer = send(t, "PUT", "/offers/"+offer.ID, terms(95000, 72*time.Hour), recruiter)
+		if code != http.StatusOK || offer.Status != models.OfferStatusDraft || offer.Salary != 95000 {
+			t.Fatalf("Expected the offer to return to draft, got %v: %+v", code, offer)
+		}
+		send(t, "POST", "/offers/"+offer.ID+"/submit", nil, recruiter)
+		code, offer = send(t, "POST", "/offers/"+offer.ID+"/extend", nil, recruiter)
+		if code != http.StatusOK || offer.Status != models.OfferStatusExtended {
+			t.Fatalf("Expected the offer to be extended, got %v: %+v", code, offer)
+		}
+		if app := stage(t, "app1"); app.Status.CurrentStage != models.StageOffer {
+			t.Errorf("Expected the application to move to Offer, got %q", app.Status.CurrentStage)
+		}
+
+		if code, _ := send(t, "POST", "/offers/"+offer.ID+"/accept", nil, candidate(6)); code != http.StatusForbidden {
+			t.Errorf("Expected 403 for another candidate, got %v", code)
+		}
+		code, offer = send(t, "POST", "/offers/"+offer.ID+"/accept", nil, candidate(5))
+		if code != http.StatusOK || offer.Status != models.OfferStatusAccepted || len(offer.History) != 6 {
+			t.Fatalf("Expected the offer to be accepted, got %v: %+v", code, offer)
+		}
+		if app := stage(t, "app1"); app.Status.CurrentStage != models.StageHired {
+			t.Errorf("Expected the application to move to Hired, got %q", app.Status.CurrentStage)
+		}
+		if code, _ := send(t, "POST", "/offers/"+offer.ID+"/decline", nil, candidate(5)); code != http.StatusConflict {
+			t.Errorf("Expected 409 for declining an accepted offer, got %v", code)
+		}
+
+		var events []string
+		for _, event := range mockKafka.(*MockKafkaPublisher).GetPublishedOfferEvents() {
+			events = append(events, event.Event)
+		}
+		want := []string{kafka.OfferEventCreated, kafka.OfferEventSubmitted, kafka.OfferEventUpdated, kafka.OfferEventSubmitted, kafka.OfferEventExtended, kafka.OfferEventAccepted}
+		if len(events) != len(want) {
+			t.Fatalf("Expected events %v, got %v", want, events)
+		}
+		for i := range want {
+			if events[i] != want[i] {
+				t.Errorf("Expected events %v, got %v", want, events)
+				break
+			}
+		}
+
+		// extending and accepting moved the application, which consumers hear about
+		appEvents := applicationEvents()
+		wantApp := []string{
+			kafka.ApplicationEventStageChanged + " app1 " + models.StageOffer,
+			kafka.ApplicationEventStageChanged + " app1 " + models.StageHired,
+		}
+		if strings.Join(appEvents, ", ") != strings.Join(wantApp, ", ") {
+			t.Errorf("Expected application events %v, got %v", wantApp, appEvents)
+		}
+	})
+
+	t.Run("decline", func(t *testing.T) {
+		offer := extend(t, "app2", 72*time.Hour)
+		code, offer := send(t, "POST", "/offers/"+offer.ID+"/decline", map[string]string{"reason": "Accepted another offer"}, candidate(6))
+		if code != http.StatusOK || offer.Status != models.OfferStatusDeclined {
+			t.Fatalf("Expected the offer to be declined, got %v: %+v", code, offer)
+		}
+		if app := stage(t, "app2"); app.Status.CurrentStage != models.StageWithdrawn || app.Active {
+			t.Errorf("Expected the application to be withdrawn, got %+v", app.Status)
+		}
+		events := applicationEvents()
+		if last := events[len(events)-1]; last != kafka.ApplicationEventWithdrawn+" app2 "+models.StageWithdrawn {
+			t.Errorf("Expected an application.withdrawn event for app2, got %v", events)
+		}
+	})
+
+	t.Run("offer update fails after the application moved", func(t *testing.T) {
+		offer := extend(t, "app4", 72*time.Hour)
+		before := stage(t, "app4")
+		published := len(applicationEvents())
+
+		offerRepo.failOn = models.OfferStatusDeclined
+		if code, _ := send(t, "POST", "/offers/"+offer.ID+"/decline", nil, candidate(8)); code != http.StatusConflict {
+			t.Fatalf("Expected 409 when the offer cannot be saved, got %v", code)
+		}
+		app := stage(t, "app4")
+		if app.Status.CurrentStage != models.StageOffer || !app.Active || app.WithdrawnAt != nil || len(app.StageHistory) != len(before.StageHistory) {
+			t.Errorf("Expected the application to be moved back to Offer, got %+v", app)
+		}
+		if events := applicationEvents(); len(events) != published {
+			t.Errorf("Expected no event for the failed decline, got %v", events[published:])
+		}
+
+		// the offer is still extended, so the candidate can try again
+		code, offer := send(t, "POST", "/offers/"+offer.ID+"/accept", nil, candidate(8))
+		if code != http.StatusOK || offer.Status != models.OfferStatusAccepted {
+			t.Fatalf("Expected the offer to be accepted on retry, got %v: %+v", code, offer)
+		}
+		if app := stage(t, "app4"); app.Status.CurrentStage != models.StageHired {
+			t.Errorf("Expected the application to move to Hired, got %q", app.Status.CurrentStage)
+		}
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		offer := extend(t, "app3", time.Hour)
+		handler.ExpireOffers(time.Now().Add(2 * time.Hour))
+
+		events := mockKafka.(*MockKafkaPublisher).GetPublishedOfferEvents()
+		if last := events[len(events)-1]; last.Event != kafka.OfferEventExpired || last.Offer.ID != offer.ID {
+			t.Errorf("Expected an expiry event for the offer, got %+v", last)
+		}
+		if code, _ := send(t, "POST", "/offers/"+offer.ID+"/accept", nil, candidate(7)); code != http.StatusConflict {
+			t.Errorf("Expected 409 for accepting an expired offer, got %v", code)
+		}
+		if app := stage(t, "app3"); app.Status.CurrentStage != models.StageOffer {
+			t.Errorf("Expected the application to stay in Offer, got %q", app.Status.CurrentStage)
+		}
+		// a new offer can be made once the old one has expired, and extending it leaves
+		// the application in Offer without another stage change
+		published := len(applicationEvents())
+		extend(t, "app3", 72*time.Hour)
+		if events := applicationEvents(); len(events) != published {
+			t.Errorf("Expected no application event when the stage stays the same, got %v", events[published:])
+		}
+	})
+
+	t.Run("concurrent offers", func(t *testing.T) {
+		var wg sync.WaitGroup
+		codes := make([]int, 8)
+		for i := range codes {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				codes[i], _ = send(t, "POST", "/applications/app5/offers", terms(90000+float64(i), 72*time.Hour), recruiter)
+			}(i)
+		}
+		wg.Wait()
+
+		created := 0
+		for _, code := range codes {
+			if code == http.StatusCreated {
+				created++
+			} else if code != http.StatusConflict {
+				t.Errorf("Expected 201 or 409, got %v", code)
+			}
+		}
+		if created != 1 {
+			t.Errorf("Expected exactly one offer to be created, got %d", created)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		body   map[string]interface{}
+		status int
+	}{
+		{"bad currency", map[string]interface{}{"salary": 1, "currency": "euro", "expiresAt": time.Now().Add(time.Hour), "startDate": time.Now().Add(48 * time.Hour)}, http.StatusBadRequest},
+		{"no salary", map[string]interface{}{"currency": "EUR", "expiresAt": time.Now().Add(time.Hour), "startDate": time.Now().Add(48 * time.Hour)}, http.StatusBadRequest},
+		{"already expired", map[string]interface{}{"salary": 1, "currency": "EUR", "expiresAt": time.Now().Add(-time.Hour), "startDate": time.Now().Add(48 * time.Hour)}, http.StatusBadRequest},
+		{"starts before expiry", map[string]interface{}{"salary": 1, "currency": "EUR", "expiresAt": time.Now().Add(48 * time.Hour), "startDate": time.Now().Add(time.Hour)}, http.StatusBadRequest},
+	}
+	// app1 is hired, so terms are checked against a fresh application
+	appRepo.CreateApplication(&models.Application{ApplicationID: "fresh", JobID: 1, CandidateID: 99, CompanyID: 1, Active: true, Status: models.Status{CurrentStage: models.StageInterview}})
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _ := send(t, "POST", "/applications/fresh/offers", tt.body, recruiter); code != tt.status {
+				t.Errorf("Expected %v, got %v", tt.status, code)
+			}
+		})
+	}
+	if code, _ := send(t, "POST", "/applications/app1/offers", terms(1, time.Hour), recruiter); code != http.StatusConflict {
+		t.Errorf("Expected 409 for an offer on a hired application, got %v", code)
+	}
+	if code, _ := send(t, "GET", "/applications/app1/offers", nil, func(r *http.Request) *http.Request { return withUserInfo(r, 2) }); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for another company, got %v", code)
+	}
+}
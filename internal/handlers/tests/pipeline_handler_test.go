@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"jobs-svc/internal/tests"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+const interviewLoopPipeline = `{
+	"name": "Interview loop",
+	"stages": [
+		{"name": "New", "transitions": ["Phone Screen", "Declined"]},
+		{"name": "Phone Screen", "transitions": ["Onsite", "Declined"]},
+		{"name": "Onsite", "transitions": ["Hired", "Declined"]},
+		{"name": "Hired", "terminal": true, "kind": "hired"},
+		{"name": "Declined", "terminal": true, "kind": "rejected"}
+	]
+}`
+
+func setupTestPipelineRouter(handler *handlers.PipelineHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/pipelines", handler.CreatePipeline).Methods("POST")
+	router.HandleFunc("/pipelines", handler.GetPipelines).Methods("GET")
+	router.HandleFunc("/pipelines/default", handler.GetDefaultPipeline).Methods("GET")
+	router.HandleFunc("/pipelines/{id}", handler.GetPipelineByID).Methods("GET")
+	router.HandleFunc("/pipelines/{id}", handler.UpdatePipeline).Methods("PUT")
+	router.HandleFunc("/pipelines/{id}", handler.DeletePipeline).Methods("DELETE")
+	return router
+}
+
+// createPipeline creates a pipeline for orgID through the router and returns it
+func createPipeline(t *testing.T, router *mux.Router, orgID int, body string) models.Pipeline {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/pipelines", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(req, orgID))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected pipeline to be created, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var pipeline models.Pipeline
+	if err := json.NewDecoder(rr.Body).Decode(&pipeline); err != nil {
+		t.Fatalf("Failed to decode pipeline: %v", err)
+	}
+	return pipeline
+}
+
+func TestPipelineHandler_CRUD(t *testing.T) {
+	jobRepo := tests.NewMockJobRepo()
+	service := services.PipelineService{PipelineRepo: tests.NewMockPipelineRepo(), JobRepo: jobRepo}
+	router := setupTestPipelineRouter(&handlers.PipelineHandler{PipelineService: service})
+
+	pipeline := createPipeline(t, router, 1, interviewLoopPipeline)
+	if pipeline.ID == "" || pipeline.CompanyID != 1 || len(pipeline.Stages) != 5 {
+		t.Fatalf("Unexpected pipeline: %+v", pipeline)
+	}
+	unused := createPipeline(t, router, 1, `{"name":"Quick","stages":[{"name":"New","transitions":["Done"]},{"name":"Done","terminal":true}]}`)
+	createPipeline(t, router, 2, `{"name":"Other company","stages":[{"name":"New","transitions":["Done"]},{"name":"Done","terminal":true}]}`)
+	jobRepo.CreateJob(&models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open, PipelineID: pipeline.ID})
+
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		orgID    int
+		wantCode int
+	}{
+		{"duplicate name", "POST", "/pipelines", interviewLoopPipeline, 1, http.StatusConflict},
+		{"missing name", "POST", "/pipelines", `{"stages":[{"name":"New","terminal":true}]}`, 1, http.StatusBadRequest},
+		{"terminal stage with transitions", "POST", "/pipelines", `{"name":"Bad","stages":[{"name":"New","transitions":["Done"]},{"name":"Done","terminal":true,"transitions":["New"]}]}`, 1, http.StatusBadRequest},
+		{"transition to unknown stage", "POST", "/pipelines", `{"name":"Bad","stages":[{"name":"New","transitions":["Nowhere"]}]}`, 1, http.StatusBadRequest},
+		{"open stage without transitions", "POST", "/pipelines", `{"name":"Bad","stages":[{"name":"New","transitions":["Stuck"]},{"name":"Stuck"}]}`, 1, http.StatusBadRequest},
+		{"kind on open stage", "POST", "/pipelines", `{"name":"Bad","stages":[{"name":"New","kind":"hired","transitions":["Done"]},{"name":"Done","terminal":true}]}`, 1, http.StatusBadRequest},
+		{"get own pipeline", "GET", "/pipelines/" + pipeline.ID, "", 1, http.StatusOK},
+		{"get other company's pipeline", "GET", "/pipelines/" + pipeline.ID, "", 2, http.StatusForbidden},
+		{"get unknown pipeline", "GET", "/pipelines/missing", "", 1, http.StatusNotFound},
+		{"remove stage in use", "PUT", "/pipelines/" + pipeline.ID, `{"name":"Interview loop","stages":[{"name":"New","transitions":["Hired","Declined"]},{"name":"Hired","terminal":true},{"name":"Declined","terminal":true}]}`, 1, http.StatusConflict},
+		{"add stage in use", "PUT", "/pipelines/" + pipeline.ID, `{"name":"Interview loop","stages":[{"name":"New","transitions":["Phone Screen","Declined"]},{"name":"Phone Screen","transitions":["Take Home","Onsite","Declined"]},{"name":"Take Home","transitions":["Onsite","Declined"]},{"name":"Onsite","transitions":["Hired","Declined"]},{"name":"Hired","terminal":true,"kind":"hired"},{"name":"Declined","terminal":true,"kind":"rejected"}]}`, 1, http.StatusOK},
+		{"delete pipeline in use", "DELETE", "/pipelines/" + pipeline.ID, "", 1, http.StatusConflict},
+		{"delete other company's pipeline", "DELETE", "/pipelines/" + unused.ID, "", 2, http.StatusForbidden},
+		{"delete unused pipeline", "DELETE", "/pipelines/" + unused.ID, "", 1, http.StatusOK},
+		{"get deleted pipeline", "GET", "/pipelines/" + unused.ID, "", 1, http.StatusNotFound},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, withUserInfo(req, tt.orgID))
+			if rr.Code != tt.wantCode {
+				t.Errorf("got %v want %v: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/pipelines", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(req, 1))
+	var pipelines []models.Pipeline
+	if err := json.NewDecoder(rr.Body).Decode(&pipelines); err != nil {
+		t.Fatalf("Failed to decode pipelines: %v", err)
+	}
+	if len(pipelines) != 1 || pipelines[0].ID != pipeline.ID || len(pipelines[0].Stages) != 6 {
+		t.Errorf("Expected only the updated company pipeline, got %+v", pipelines)
+	}
+}
+
+func TestApplicationHandler_MoveStageFollowsJobPipeline(t *testing.T) {
+	pipelineRepo := tests.NewMockPipelineRepo()
+	jobRepo := tests.NewMockJobRepo()
+	appRepo := NewMockApplicationRepo()
+	pipelineRouter := setupTestPipelineRouter(&handlers.PipelineHandler{
+		PipelineService: services.PipelineService{PipelineRepo: pipelineRepo, JobRepo: jobRepo},
+	})
+	pipeline := createPipeline(t, pipelineRouter, 1, interviewLoopPipeline)
+
+	job := &models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open, PipelineID: pipeline.ID}
+	jobRepo.CreateJob(job)
+
+	service := services.ApplicationsService{AppRepo: appRepo, JobRepo: jobRepo, PipelineRepo: pipelineRepo}
+	router := setupTestApplicationRouter(&handlers.ApplicationHandler{
+		ApplicationService: service,
+		KafkaPublisher:     NewMockKafkaPublisher(),
+	})
+
+	declined := &models.Application{ApplicationID: "declined", JobID: models.NumericID(job.ID), CandidateID: 6}
+	app := &models.Application{ApplicationID: "app1", JobID: models.NumericID(job.ID), CandidateID: 5}
+	for _, a := range []*models.Application{declined, app} {
+		if err := service.CreateApplication(a); err != nil {
+			t.Fatalf("CreateApplication failed: %v", err)
+		}
+	}
+	if app.Status.CurrentStage != "New" || app.StageHistory[0].To != "New" {
+		t.Fatalf("Expected application to start in the pipeline's first stage, got %+v", app.Status)
+	}
+
+	cases := []struct {
+		name          string
+		applicationID string
+		body          string
+		wantCode      int
+	}{
+		{"default pipeline stage", "app1", `{"stage":"Screening"}`, http.StatusBadRequest},
+		{"skip a stage", "app1", `{"stage":"Onsite"}`, http.StatusConflict},
+		{"move to phone screen", "app1", `{"stage":"phone screen"}`, http.StatusOK},
+		{"decline without a rejection reason", "declined", `{"stage":"Declined","reason":"Not a fit"}`, http.StatusBadRequest},
+		{"decline", "declined", `{"stage":"Declined","reason":"Not a fit","disposition":{"reasonCode":"skills_mismatch"}}`, http.StatusOK},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/applications/"+tt.applicationID+"/stage", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, withUserInfo(req, 1))
+			if rr.Code != tt.wantCode {
+				t.Errorf("got %v want %v: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+		})
+	}
+
+	// closing the job leaves applications in the pipeline's own terminal stages alone
+	jobRouter := setupTestRouter(&handlers.JobHandler{
+		JobService:     services.JobService{JobRepo: jobRepo, AppRepo: appRepo, PipelineRepo: pipelineRepo},
+		KafkaPublisher: tests.NewMockKafkaPublisher(),
+	})
+	rr := httptest.NewRecorder()
+	jobRouter.ServeHTTP(rr, withUserInfo(httptest.NewRequest("POST", "/jobs/1/close", nil), 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected job to close, got %v", rr.Code)
+	}
+	stored, _ := appRepo.GetApplicationByID("app1")
+	if stored.Status.CurrentStage != models.StageJobClosed {
+		t.Errorf("Expected open application to be closed, got %s", stored.Status.CurrentStage)
+	}
+	stored, _ = appRepo.GetApplicationByID("declined")
+	if stored.Status.CurrentStage != "Declined" {
+		t.Errorf("Expected declined application to stay declined, got %s", stored.Status.CurrentStage)
+	}
+}
@@ -0,0 +1,244 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/kafka"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"jobs-svc/internal/tests"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestRejectionReasonRouter(handler *handlers.RejectionReasonHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/rejection-reasons", handler.GetReasons).Methods("GET")
+	router.HandleFunc("/rejection-reasons", handler.CreateReason).Methods("POST")
+	router.HandleFunc("/rejection-reasons/{code}", handler.UpdateReason).Methods("PUT")
+	return router
+}
+
+func TestRejectionReasonHandler_Catalog(t *testing.T) {
+	service := services.RejectionReasonService{ReasonRepo: tests.NewMockRejectionReasonRepo()}
+	router := setupTestRejectionReasonRouter(&handlers.RejectionReasonHandler{RejectionReasonService: service})
+
+	list := func(t *testing.T, orgID int) []models.RejectionReason {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/rejection-reasons", nil), orgID))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %v: %s", rr.Code, rr.Body.String())
+		}
+		var reasons []models.RejectionReason
+		if err := json.NewDecoder(rr.Body).Decode(&reasons); err != nil {
+			t.Fatalf("Failed to decode reasons: %v", err)
+		}
+		return reasons
+	}
+
+	if reasons := list(t, 1); len(reasons) != len(models.DefaultRejectionReasons) {
+		t.Fatalf("Expected the default catalog, got %d reasons", len(reasons))
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{"create", "POST", "/rejection-reasons", `{"code":"visa_sponsorship","label":"Needs visa sponsorship"}`, http.StatusCreated},
+		{"duplicate code", "POST", "/rejection-reasons", `{"code":"visa_sponsorship","label":"Visa"}`, http.StatusConflict},
+		{"duplicate of a default", "POST", "/rejection-reasons", `{"code":"other","label":"Other"}`, http.StatusConflict},
+		{"invalid code", "POST", "/rejection-reasons", `{"code":"Bad Code","label":"Bad"}`, http.StatusBadRequest},
+		{"missing label", "POST", "/rejection-reasons", `{"code":"no_label"}`, http.StatusBadRequest},
+		{"relabel", "PUT", "/rejection-reasons/skills_mismatch", `{"label":"Missing key skills"}`, http.StatusOK},
+		{"archive", "PUT", "/rejection-reasons/candidate_unresponsive", `{"label":"Candidate stopped responding","archived":true}`, http.StatusOK},
+		{"unknown code", "PUT", "/rejection-reasons/nope", `{"label":"Nope"}`, http.StatusNotFound},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, withUserInfo(req, 1))
+			if rr.Code != tt.wantCode {
+				t.Errorf("got %v want %v: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+		})
+	}
+
+	reasons := list(t, 1)
+	if len(reasons) != len(models.DefaultRejectionReasons)+1 {
+		t.Fatalf("Expected the defaults to be seeded alongside the new reason, got %d reasons", len(reasons))
+	}
+	byCode := make(map[string]models.RejectionReason)
+	for _, reason := range reasons {
+		byCode[reason.Code] = reason
+	}
+	if byCode["skills_mismatch"].Label != "Missing key skills" || !byCode["candidate_unresponsive"].Archived {
+		t.Errorf("Expected the relabel and archive to stick, got %+v", byCode)
+	}
+	if other := list(t, 2); len(other) != len(models.DefaultRejectionReasons) || other[0].Label != "Skills do not match the role" {
+		t.Errorf("Expected another company to keep the default catalog, got %+v", other)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withCandidate(httptest.NewRequest("GET", "/rejection-reasons", nil), 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected a user without an organization to be forbidden, got %v", rr.Code)
+	}
+}
+
+func TestApplicationHandler_RejectWithDisposition(t *testing.T) {
+	reasonRepo := tests.NewMockRejectionReasonRepo()
+	reasonRouter := setupTestRejectionReasonRouter(&handlers.RejectionReasonHandler{
+		RejectionReasonService: services.RejectionReasonService{ReasonRepo: reasonRepo},
+	})
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/rejection-reasons/candidate_unresponsive", bytes.NewBufferString(`{"label":"Ghosted","archived":true}`))
+	reasonRouter.ServeHTTP(rr, withUserInfo(req, 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected reason to be archived, got %v", rr.Code)
+	}
+
+	appRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open})
+	mockKafka := NewMockKafkaPublisher()
+	service := services.ApplicationsService{AppRepo: appRepo, JobRepo: jobRepo, ReasonRepo: reasonRepo}
+	router := setupTestApplicationRouter(&handlers.ApplicationHandler{ApplicationService: service, KafkaPublisher: mockKafka})
+	for _, app := range []*models.Application{
+		{ApplicationID: "a1", JobID: 1, CandidateID: 5},
+		{ApplicationID: "a2", JobID: 1, CandidateID: 6},
+	} {
+		if err := service.CreateApplication(app); err != nil {
+			t.Fatalf("CreateApplication failed: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name          string
+		applicationID string
+		body          string
+		wantCode      int
+	}{
+		{"reject without a reason", "a1", `{"stage":"Rejected"}`, http.StatusBadRequest},
+		{"unknown reason", "a1", `{"stage":"Rejected","disposition":{"reasonCode":"bad_vibes"}}`, http.StatusBadRequest},
+		{"archived reason", "a1", `{"stage":"Rejected","disposition":{"reasonCode":"candidate_unresponsive"}}`, http.StatusBadRequest},
+		{"disposition without rejecting", "a2", `{"stage":"Screening","disposition":{"reasonCode":"other"}}`, http.StatusBadRequest},
+		{"reject", "a1", `{"stage":"Rejected","reason":"Weak coding round","disposition":{"reasonCode":"skills_mismatch","internalNote":"No Go experience","candidateMessage":"Thanks for your time."}}`, http.StatusOK},
+		{"move on", "a2", `{"stage":"Screening"}`, http.StatusOK},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/applications/"+tt.applicationID+"/stage", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, withUserInfo(req, 1))
+			if rr.Code != tt.wantCode {
+				t.Errorf("got %v want %v: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+		})
+	}
+
+	stored, _ := appRepo.GetApplicationByID("a1")
+	disposition := stored.Disposition
+	if stored.Status.CurrentStage != models.StageRejected || disposition == nil {
+		t.Fatalf("Expected a1 to be rejected with a disposition, got %+v", stored)
+	}
+	if disposition.ReasonLabel != "Skills do not match the role" || disposition.DecidedBy != 10 || disposition.DecidedAt.IsZero() {
+		t.Errorf("Expected the disposition to record the label and decision, got %+v", disposition)
+	}
+
+	events := mockKafka.(*MockKafkaPublisher).GetPublishedApplicationEvents()
+	if len(events) != 2 || events[0].Event != kafka.ApplicationEventRejected || events[0].Application.ApplicationID != "a1" {
+		t.Errorf("Expected a rejected event for a1, got %+v", events)
+	}
+	if len(events) == 2 && (events[1].Event != kafka.ApplicationEventStageChanged || events[1].Application.ApplicationID != "a2") {
+		t.Errorf("Expected a stage_changed event for a2, got %+v", events[1])
+	}
+
+	// the candidate sees the message but not the reason or internal note
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/applications/candidate/5", nil))
+	var views []models.Application
+	if err := json.NewDecoder(rr.Body).Decode(&views); err != nil || len(views) != 1 {
+		t.Fatalf("Failed to decode candidate applications: %v", err)
+	}
+	if view := views[0].Disposition; view == nil || view.CandidateMessage != "Thanks for your time." || view.ReasonCode != "" || view.InternalNote != "" {
+		t.Errorf("Expected the candidate view to hide the rejection details, got %+v", view)
+	}
+	if views[0].Status.Reason != "" {
+		t.Errorf("Expected the candidate view to hide the recruiter's reason, got %q", views[0].Status.Reason)
+	}
+	for _, change := range views[0].StageHistory {
+		if change.Reason != "" || change.ChangedBy != 0 {
+			t.Errorf("Expected stage history without reasons or recruiters, got %+v", change)
+		}
+	}
+}
+
+func TestAnalyticsHandler_JobRejections(t *testing.T) {
+	appRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open})
+	reasonRepo := tests.NewMockRejectionReasonRepo()
+	reasonService := services.RejectionReasonService{ReasonRepo: reasonRepo}
+	recruiter := &clients.UserResponse{ID: 10, Org: &clients.Org{ID: 1, Name: "Test Company"}}
+	if _, err := reasonService.UpdateReason(recruiter, "skills_mismatch", &models.RejectionReason{Label: "Missing key skills"}); err != nil {
+		t.Fatalf("UpdateReason failed: %v", err)
+	}
+
+	applications := services.ApplicationsService{AppRepo: appRepo, JobRepo: jobRepo, ReasonRepo: reasonRepo}
+	// the last application stays open and is left out of the report
+	for i, code := range []string{"skills_mismatch", "skills_mismatch", "position_filled", ""} {
+		app := &models.Application{ApplicationID: fmt.Sprintf("a%d", i+1), JobID: 1, CandidateID: models.NumericID(i + 1)}
+		if err := applications.CreateApplication(app); err != nil {
+			t.Fatalf("CreateApplication failed: %v", err)
+		}
+		if code != "" {
+			if _, err := applications.MoveStage(recruiter, app.ApplicationID, models.StageRejected, "", &models.Disposition{ReasonCode: code}); err != nil {
+				t.Fatalf("MoveStage failed: %v", err)
+			}
+		}
+	}
+
+	handler := handlers.AnalyticsHandler{
+		AnalyticsService: services.AnalyticsService{
+			AnalyticsRepo: NewMockAnalyticsRepo(appRepo),
+			JobRepo:       jobRepo,
+			ReasonRepo:    reasonRepo,
+		},
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/analytics/jobs/{id}/rejections", handler.GetJobRejections).Methods("GET")
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/analytics/jobs/1/rejections", nil), 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var report models.RejectionReport
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Rejected != 3 || len(report.Reasons) != 2 {
+		t.Fatalf("Expected 3 rejections over 2 reasons, got %+v", report)
+	}
+	top := report.Reasons[0]
+	if top.Code != "skills_mismatch" || top.Label != "Missing key skills" || top.Count != 2 {
+		t.Errorf("Expected skills_mismatch to lead with the company's label, got %+v", top)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/analytics/jobs/1/rejections", nil), 2))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected another company to be forbidden, got %v", rr.Code)
+	}
+}
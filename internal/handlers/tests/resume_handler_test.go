@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/kafka"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"jobs-svc/internal/storage"
+	"jobs-svc/internal/tests"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// resumeUpload builds a multipart body with data as the resume file named fileName
+func resumeUpload(t *testing.T, fileName string, data []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("resume", fileName)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(data)
+	form.Close()
+	return &body, form.FormDataContentType()
+}
+
+// docxFile is the smallest zip that passes for a Word document with the given paragraphs
+func docxFile(t *testing.T, paragraphs ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	entry, err := archive.Create("word/document.xml")
+	if err != nil {
+		t.Fatalf("Failed to create docx: %v", err)
+	}
+	entry.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`))
+	for _, paragraph := range paragraphs {
+		fmt.Fprintf(entry, "<w:p><w:r><w:t>%s</w:t></w:r></w:p>", paragraph)
+	}
+	entry.Write([]byte("</w:body></w:document>"))
+	archive.Close()
+	return buf.Bytes()
+}
+
+func TestResumeHandler_UploadAndDownload(t *testing.T) {
+	appRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{Title: "Engineer", CompanyID: 1, Status: models.Open})
+	mockKafka := NewMockKafkaPublisher()
+	root := t.TempDir()
+	store, err := storage.NewLocalStore(root)
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+	links := &services.ResumeLinks{Secret: []byte("test-secret"), TTL: time.Minute, BaseURL: "http://jobs.test/"}
+
+	applications := services.ApplicationsService{AppRepo: appRepo, JobRepo: jobRepo, ResumeLinks: links}
+	for i, id := range []string{"a1", "a2"} {
+		if err := applications.CreateApplication(&models.Application{ApplicationID: id, JobID: 1, CandidateID: models.NumericID(i + 5)}); err != nil {
+			t.Fatalf("CreateApplication failed: %v", err)
+		}
+	}
+
+	handler := handlers.ResumeHandler{
+		ResumeService:  services.ResumeService{AppRepo: appRepo, JobRepo: jobRepo, Store: store, Links: links},
+		KafkaPublisher: mockKafka,
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/applications/{id}/resume", handler.UploadResume).Methods("POST")
+	router.HandleFunc("/applications/{id}/resume", handler.DownloadResume).Methods("GET")
+
+	pdf := []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n%%EOF")
+	upload := func(t *testing.T, applicationID string, candidateID int, fileName string, data []byte) *httptest.ResponseRecorder {
+		t.Helper()
+		body, contentType := resumeUpload(t, fileName, data)
+		req := httptest.NewRequest("POST", "/applications/"+applicationID+"/resume", body)
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withCandidate(req, candidateID))
+		return rr
+	}
+
+	cases := []struct {
+		name          string
+		applicationID string
+		candidateID   int
+		fileName      string
+		data          []byte
+		wantCode      int
+	}{
+		{"another candidate", "a1", 6, "cv.pdf", pdf, http.StatusForbidden},
+		{"unknown application", "missing", 5, "cv.pdf", pdf, http.StatusNotFound},
+		{"plain text", "a1", 5, "cv.pdf", []byte("just some text"), http.StatusBadRequest},
+		{"zip that is not a docx", "a1", 5, "cv.docx", []byte("PK\x03\x04not really a zip"), http.StatusBadRequest},
+		{"extension does not match", "a1", 5, "cv.docx", pdf, http.StatusBadRequest},
+		{"too large", "a1", 5, "cv.pdf", append(append([]byte{}, pdf...), make([]byte, models.MaxResumeSize)...), http.StatusRequestEntityTooLarge},
+		{"pdf", "a1", 5, "C:\\Users\\me\\cv.pdf", pdf, http.StatusOK},
+		{"same pdf again", "a1", 5, "cv.pdf", pdf, http.StatusOK},
+		{"same pdf on another application", "a2", 6, "resume.pdf", pdf, http.StatusOK},
+		{"docx", "a2", 6, "resume.docx", docxFile(t), http.StatusOK},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := upload(t, tt.applicationID, tt.candidateID, tt.fileName, tt.data)
+			if rr.Code != tt.wantCode {
+				t.Errorf("got %v want %v: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+		})
+	}
+
+	// identical files are stored once, under their content hash
+	var stored []string
+	filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			stored = append(stored, entry.Name())
+		}
+		return nil
+	})
+	if len(stored) != 2 || filepath.Ext(stored[0]) == filepath.Ext(stored[1]) {
+		t.Errorf("Expected one PDF and one DOCX to be stored, got %v", stored)
+	}
+	events := mockKafka.(*MockKafkaPublisher).GetPublishedApplicationEvents()
+	if len(events) != 3 || events[0].Event != kafka.ApplicationEventResumeUploaded {
+		t.Errorf("Expected a resume uploaded event per changed resume, got %+v", events)
+	}
+
+	app, _ := appRepo.GetApplicationByID("a1")
+	if app.Resume == nil || app.Resume.FileName != "cv.pdf" || app.Resume.ContentType != models.ResumeTypePDF || app.Resume.Size != int64(len(pdf)) {
+		t.Fatalf("Expected a1 to have the uploaded PDF, got %+v", app.Resume)
+	}
+
+	// the candidate's own upload response carries a signed link
+	var uploaded models.Application
+	if err := json.NewDecoder(upload(t, "a1", 5, "cv.pdf", pdf).Body).Decode(&uploaded); err != nil {
+		t.Fatalf("Failed to decode upload response: %v", err)
+	}
+	link, err := url.Parse(uploaded.ResumeURL)
+	if err != nil || link.Host != "jobs.test" || link.Path != "/applications/a1/resume" {
+		t.Fatalf("Expected a signed link served by this service, got %q", uploaded.ResumeURL)
+	}
+
+	download := func(query url.Values) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/applications/a1/resume?"+query.Encode(), nil))
+		return rr
+	}
+	rr := download(link.Query())
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected the signed link to download the resume, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if body, _ := io.ReadAll(rr.Body); !bytes.Equal(body, pdf) || rr.Header().Get("Content-Type") != models.ResumeTypePDF {
+		t.Errorf("Expected the PDF back, got %q (%s)", body, rr.Header().Get("Content-Type"))
+	}
+	if disposition := rr.Header().Get("Content-Disposition"); disposition != `attachment; filename="cv.pdf"` {
+		t.Errorf("Unexpected Content-Disposition %q", disposition)
+	}
+
+	tampered := link.Query()
+	tampered.Set("expires", "9999999999")
+	if rr := download(tampered); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected a tampered link to be refused, got %v", rr.Code)
+	}
+	links.Sign(app, time.Now().Add(-2*time.Minute))
+	expired, _ := url.Parse(app.ResumeURL)
+	if rr := download(expired.Query()); rr.Code != http.StatusGone {
+		t.Errorf("Expected an expired link to be refused, got %v", rr.Code)
+	}
+
+	// a new resume invalidates links to the old one
+	if rr := upload(t, "a1", 5, "cv.docx", docxFile(t)); rr.Code != http.StatusOK {
+		t.Fatalf("Expected the new resume to be uploaded, got %v", rr.Code)
+	}
+	if rr := download(link.Query()); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected the old link to stop working, got %v", rr.Code)
+	}
+
+	// the company's listing signs links, the unauthenticated routes never do
+	applicationRouter := setupTestApplicationRouter(&handlers.ApplicationHandler{ApplicationService: applications, KafkaPublisher: mockKafka})
+	var page models.ApplicationPage
+	rr = httptest.NewRecorder()
+	applicationRouter.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", "/applications/job/1", nil), 1))
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil || len(page.Items) != 2 || !strings.HasPrefix(page.Items[0].ResumeURL, "http://jobs.test/applications/") {
+		t.Errorf("Expected the job's listing to serve signed links, got %+v (%v)", page.Items, err)
+	}
+	var response map[string]interface{}
+	rr = httptest.NewRecorder()
+	applicationRouter.ServeHTTP(rr, httptest.NewRequest("GET", "/applications/a1", nil))
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || response["resumeUrl"] != "" || response["resume"] != nil {
+		t.Errorf("Expected GET /applications/{id} to leave the resume out, got %v (%v)", response, err)
+	}
+	var candidateApps []map[string]interface{}
+	rr = httptest.NewRecorder()
+	applicationRouter.ServeHTTP(rr, httptest.NewRequest("GET", "/applications/candidate/5", nil))
+	if err := json.NewDecoder(rr.Body).Decode(&candidateApps); err != nil || len(candidateApps) != 1 || candidateApps[0]["resumeUrl"] != "" || candidateApps[0]["resume"] != nil {
+		t.Errorf("Expected GET /applications/candidate/{id} to leave the resume out, got %v (%v)", candidateApps, err)
+	}
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/handlers"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/services"
+	"jobs-svc/internal/storage"
+	"jobs-svc/internal/tests"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pdfFile builds a PDF whose single page shows content, compressed the way most PDFs
+// store their pages, next to an image stream that holds no text
+func pdfFile(t *testing.T, content string) []byte {
+	t.Helper()
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write([]byte(content))
+	writer.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	fmt.Fprintf(&pdf, "4 0 obj\n<< /Type /XObject /Subtype /Image /Length 6 >>\nstream\n(Java)\nendstream\nendobj\n")
+	fmt.Fprintf(&pdf, "5 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+	pdf.Write(compressed.Bytes())
+	pdf.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF")
+	return pdf.Bytes()
+}
+
+func TestExtractResumeText(t *testing.T) {
+	pdf := pdfFile(t, `BT /F1 12 Tf 72 712 Td [(Senior)-300(Go)12( Dev\(eloper\))] TJ T* <4a617661536372697074> Tj
+(\124eam lead) ' ET`)
+	text, err := models.ExtractResumeText(models.ResumeTypePDF, pdf)
+	if err != nil {
+		t.Fatalf("Failed to extract PDF text: %v", err)
+	}
+	if got := strings.Join(strings.Fields(text), " "); got != "Senior Go Dev(eloper) JavaScript Team lead" {
+		t.Errorf("Unexpected PDF text %q", got)
+	}
+
+	text, err = models.ExtractResumeText(models.ResumeTypeDOCX, docxFile(t, "Jane Doe", "Go &amp; Kubernetes"))
+	if err != nil {
+		t.Fatalf("Failed to extract DOCX text: %v", err)
+	}
+	if text != "Jane Doe\nGo & Kubernetes\n" {
+		t.Errorf("Unexpected DOCX text %q", text)
+	}
+
+	if _, err := models.ExtractResumeText(models.ResumeTypeDOCX, docxFile(t)); err != models.ErrNoResumeText {
+		t.Errorf("Expected an empty document to have no text, got %v", err)
+	}
+}
+
+func TestResumeMatcher_ScoresAndSortsApplications(t *testing.T) {
+	appRepo := NewMockApplicationRepo()
+	jobRepo := tests.NewMockJobRepo()
+	jobRepo.CreateJob(&models.Job{
+		Title:      "Engineer",
+		CompanyID:  1,
+		Status:     models.Open,
+		Skills:     "React, Node.js, TypeScript, AWS, Java",
+		Experience: "5+ yrs React development, Team leadership",
+	})
+	jobRepo.CreateJob(&models.Job{Title: "Anything goes", CompanyID: 1, Status: models.Open})
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+	matcher := &services.ResumeMatcher{AppRepo: appRepo, JobRepo: jobRepo, Store: store, Queue: make(chan string, 10)}
+	applications := services.ApplicationsService{AppRepo: appRepo, JobRepo: jobRepo, Matcher: matcher}
+	resumes := services.ResumeService{AppRepo: appRepo, JobRepo: jobRepo, Store: store, Matcher: matcher}
+
+	// scoring happens once the queue is worked through, never while submitting
+	drain := func(t *testing.T) {
+		t.Helper()
+		for len(matcher.Queue) > 0 {
+			if _, err := matcher.Score(<-matcher.Queue); err != nil {
+				t.Fatalf("Score failed: %v", err)
+			}
+		}
+	}
+
+	submitted := []*models.Application{
+		{ApplicationID: "a1", JobID: 1, CandidateID: 5},
+		{ApplicationID: "a2", JobID: 1, CandidateID: 6, YearsOfExperience: 2},
+		{ApplicationID: "a3", JobID: 1, CandidateID: 7, Skills: []string{"aws"}},
+		{ApplicationID: "a4", JobID: 1, CandidateID: 8},
+		{ApplicationID: "b1", JobID: 2, CandidateID: 5, Skills: []string{"Go"}},
+	}
+	for _, app := range submitted {
+		if err := applications.CreateApplication(app); err != nil {
+			t.Fatalf("CreateApplication failed: %v", err)
+		}
+		if app.Match != nil {
+			t.Fatalf("Expected %s to be scored in the background", app.ApplicationID)
+		}
+	}
+	if len(matcher.Queue) != len(submitted) {
+		t.Fatalf("Expected every new application to be queued, got %d", len(matcher.Queue))
+	}
+
+	upload := func(applicationID string, candidateID int, fileName string, data []byte) {
+		t.Helper()
+		if _, _, err := resumes.UploadResume(&clients.UserResponse{ID: candidateID}, applicationID, fileName, data); err != nil {
+			t.Fatalf("UploadResume failed: %v", err)
+		}
+	}
+	upload("a1", 5, "cv.pdf", pdfFile(t, "BT (Seven years building JavaScript apps with React and Node.js.) Tj T* (Team leadership for 7 years) Tj ET"))
+	upload("a2", 6, "cv.docx", docxFile(t, "TypeScript, AWS, Java", "React and Node.js since 2022"))
+	drain(t)
+
+	cases := []struct {
+		id      string
+		score   float64
+		matched []string
+		missing []string
+	}{
+		{"a1", 57.1, []string{"React", "Node.js"}, []string{"TypeScript", "AWS", "Java"}},
+		{"a2", 71.4, []string{"React", "Node.js", "TypeScript", "AWS", "Java"}, []string{}},
+		{"a3", 14.3, []string{"AWS"}, []string{"React", "Node.js", "TypeScript", "Java"}},
+	}
+	for _, tt := range cases {
+		app, _ := appRepo.GetApplicationByID(tt.id)
+		if app.Match == nil {
+			t.Fatalf("Expected %s to be scored", tt.id)
+		}
+		if app.Match.Score != tt.score || fmt.Sprint(app.Match.MatchedSkills) != fmt.Sprint(tt.matched) || fmt.Sprint(app.Match.MissingSkills) != fmt.Sprint(tt.missing) {
+			t.Errorf("%s: got %+v, want score %v matching %v missing %v", tt.id, app.Match, tt.score, tt.matched, tt.missing)
+		}
+	}
+	a1, _ := appRepo.GetApplicationByID("a1")
+	if a1.Match.ResumeSHA256 != a1.Resume.SHA256 || fmt.Sprint(a1.Match.MatchedExperience) != "[5+ yrs React development Team leadership]" {
+		t.Errorf("Expected a1's resume to cover the experience, got %+v", a1.Match)
+	}
+	if b1, _ := appRepo.GetApplicationByID("b1"); b1.Match != nil {
+		t.Errorf("Expected no score for a job without requirements, got %+v", b1.Match)
+	}
+
+	handler := &handlers.ApplicationHandler{ApplicationService: applications, KafkaPublisher: NewMockKafkaPublisher()}
+	router := setupTestApplicationRouter(handler)
+	var ids []string
+	for url := "/applications/job/1?limit=2&sort=-matchScore"; ; {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withUserInfo(httptest.NewRequest("GET", url, nil), 1))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got %v: %s", rr.Code, rr.Body.String())
+		}
+		var page models.ApplicationPage
+		json.NewDecoder(rr.Body).Decode(&page)
+		for _, app := range page.Items {
+			ids = append(ids, app.ApplicationID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		url = "/applications/job/1?limit=2&sort=-matchScore&cursor=" + page.NextCursor
+	}
+	if got := strings.Join(ids, ","); got != "a2,a1,a3,a4" {
+		t.Errorf("Expected the best matches first, got %s", got)
+	}
+
+	// candidates do not see how they were scored
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withCandidate(httptest.NewRequest("GET", "/applications/candidate/5", nil), 5))
+	if rr.Code != http.StatusOK || strings.Contains(rr.Body.String(), `"match"`) || strings.Contains(rr.Body.String(), "matchedSkills") {
+		t.Errorf("Expected the candidate view to leave out the match, got %s", rr.Body.String())
+	}
+
+	// a score computed for a resume that has since been replaced is dropped
+	stale := *a1.Match
+	upload("a1", 5, "cv.docx", docxFile(t, "TypeScript"))
+	if err := appRepo.SetMatch("a1", stale); err == nil {
+		t.Errorf("Expected a stale score to be refused")
+	}
+	drain(t)
+	if a1, _ = appRepo.GetApplicationByID("a1"); a1.Match.Score != 14.3 || a1.Match.ResumeSHA256 != a1.Resume.SHA256 {
+		t.Errorf("Expected a1 to be rescored for its new resume, got %+v", a1.Match)
+	}
+}
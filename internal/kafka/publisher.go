@@ -12,16 +12,33 @@ import (
 	"jobs-svc/internal/models"
 
 	"github.com/IBM/sarama"
-	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Job lifecycle events published alongside the job payload
+const (
+	JobEventDeleted  = "job.deleted"
+	JobEventRestored = "job.restored"
+	JobEventPurged   = "job.purged"
+)
+
+// Application lifecycle events published to the application events topic
+const (
+	ApplicationEventWithdrawn      = "application.withdrawn"
+	ApplicationEventRejected       = "application.rejected"
+	ApplicationEventStageChanged   = "application.stage_changed"
+	ApplicationEventTagsChanged    = "application.tags_changed"
+	ApplicationEventResumeUploaded = "application.resume_uploaded"
 )
 
 type JobKafkaMessage struct {
-	JobID       uint     `json:"jobId"`
-	Title       string   `json:"title"`
-	Overview    string   `json:"overview"`
-	Description string   `json:"description"`
-	Skills      []string `json:"skills"`
-	Experience  string   `json:"experience"`
+	Event       string           `json:"event,omitempty"`
+	JobID       uint             `json:"jobId"`
+	Title       string           `json:"title"`
+	Overview    string           `json:"overview"`
+	Description string           `json:"description"`
+	Skills      []string         `json:"skills"`
+	Experience  string           `json:"experience"`
+	Status      models.JobStatus `json:"status"`
 }
 
 type ApplicationKafkaMessage struct {
@@ -31,6 +48,65 @@ type ApplicationKafkaMessage struct {
 	CandidateID   uint   `json:"candidateId"`
 }
 
+// ApplicationEventKafkaMessage describes something that happened to an application
+type ApplicationEventKafkaMessage struct {
+	Event            string    `json:"event"`
+	ApplicationID    string    `json:"applicationId"`
+	JobID            uint      `json:"jobId"`
+	CandidateID      uint      `json:"candidateId"`
+	CompanyID        uint      `json:"companyId"`
+	Stage            string    `json:"stage"`
+	Reason           string    `json:"reason,omitempty"`
+	CandidateMessage string    `json:"candidateMessage,omitempty"`
+	Tags             []string  `json:"tags,omitempty"`
+	ResumeURL        string    `json:"resumeUrl,omitempty"`
+	OccurredAt       time.Time `json:"occurredAt"`
+}
+
+// Offer events published to the application events topic, one per change to an offer
+const (
+	OfferEventCreated   = "offer.created"
+	OfferEventUpdated   = "offer.updated"
+	OfferEventSubmitted = "offer.submitted"
+	OfferEventExtended  = "offer.extended"
+	OfferEventAccepted  = "offer.accepted"
+	OfferEventDeclined  = "offer.declined"
+	OfferEventExpired   = "offer.expired"
+)
+
+// OfferKafkaMessage describes a change to an offer
+type OfferKafkaMessage struct {
+	Event         string    `json:"event"`
+	OfferID       string    `json:"offerId"`
+	ApplicationID string    `json:"applicationId"`
+	JobID         uint      `json:"jobId"`
+	CompanyID     uint      `json:"companyId"`
+	CandidateID   uint      `json:"candidateId"`
+	Status        string    `json:"status"`
+	Salary        float64   `json:"salary"`
+	Currency      string    `json:"currency"`
+	StartDate     time.Time `json:"startDate"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
+
+// NotificationEventNoteMention tells a user they were mentioned in a note
+const NotificationEventNoteMention = "note.mention"
+
+// NotificationKafkaMessage asks the notification service to notify a user. It carries
+// no note text: mentions are not checked against the company, so the recipient reads
+// the note through the authenticated notes endpoint.
+type NotificationKafkaMessage struct {
+	Event         string    `json:"event"`
+	UserID        uint      `json:"userId"`
+	NoteID        string    `json:"noteId"`
+	ApplicationID string    `json:"applicationId"`
+	JobID         uint      `json:"jobId"`
+	CompanyID     uint      `json:"companyId"`
+	AuthorID      uint      `json:"authorId"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
+
 type Config struct {
 	Brokers          []string
 	SecurityProtocol string
@@ -46,6 +122,10 @@ type Publisher struct {
 var (
 	KAFKA_CANDIDATE_TOPIC = getEnvOrDefault("KAFKA_CANDIDATE_TOPIC", "candidate_topic")
 	KAFKA_JOB_TOPIC       = getEnvOrDefault("KAFKA_JOBS_TOPIC", "jobs_topic")
+	// application lifecycle events get their own topic so candidate_topic consumers
+	// only see new applications
+	KAFKA_APPLICATION_EVENTS_TOPIC = getEnvOrDefault("KAFKA_APPLICATION_EVENTS_TOPIC", "application_events_topic")
+	KAFKA_NOTIFICATIONS_TOPIC      = getEnvOrDefault("KAFKA_NOTIFICATIONS_TOPIC", "notifications_topic")
 )
 
 func getEnvOrDefault(key, defaultValue string) string {
@@ -114,23 +194,36 @@ func (p *Publisher) Close() error {
 }
 
 func (p *Publisher) PublishJob(job *models.Job) error {
-	log.Printf("Attempting to publish job to Kafka topic: %s", KAFKA_JOB_TOPIC)
+	return p.publishJobMessage(newJobKafkaMessage(job))
+}
 
+// PublishJobEvent publishes a job lifecycle event such as JobEventDeleted to the jobs topic
+func (p *Publisher) PublishJobEvent(eventType string, job *models.Job) error {
+	kafkaMessage := newJobKafkaMessage(job)
+	kafkaMessage.Event = eventType
+	return p.publishJobMessage(kafkaMessage)
+}
+
+func newJobKafkaMessage(job *models.Job) JobKafkaMessage {
 	// Split skills string into array
 	skills := strings.Split(job.Skills, ",")
 	for i, skill := range skills {
 		skills[i] = strings.TrimSpace(skill)
 	}
 
-	kafkaMessage := JobKafkaMessage{
+	return JobKafkaMessage{
 		JobID:       job.ID,
 		Title:       job.Title,
 		Overview:    job.Overview,
 		Description: job.Description,
 		Skills:      skills,
 		Experience:  job.Experience,
+		Status:      job.Status,
 	}
+}
 
+func (p *Publisher) publishJobMessage(kafkaMessage JobKafkaMessage) error {
+	log.Printf("Attempting to publish job to Kafka topic: %s", KAFKA_JOB_TOPIC)
 	log.Printf("Created Kafka message: %+v", kafkaMessage)
 
 	jobBytes, err := json.Marshal(kafkaMessage)
@@ -153,52 +246,14 @@ func (p *Publisher) PublishJob(job *models.Job) error {
 	return nil
 }
 
-func (p *Publisher) PublishApplication(application bson.M) error {
+func (p *Publisher) PublishApplication(application *models.Application) error {
 	log.Printf("Publishing application to Kafka topic: %s", KAFKA_CANDIDATE_TOPIC)
 
-	applicationID, _ := application["applicationId"].(string)
-	resumeURL, _ := application["resumeUrl"].(string)
-
-	// Handle jobId as either string or number
-	var jobID uint
-	if strID, ok := application["jobId"].(string); ok {
-		if parsedID, err := strconv.ParseUint(strID, 10, 32); err == nil {
-			jobID = uint(parsedID)
-		}
-	} else if numID, ok := application["jobId"].(int); ok {
-		jobID = uint(numID)
-	} else if numID, ok := application["jobId"].(int64); ok {
-		jobID = uint(numID)
-	} else if numID, ok := application["jobId"].(float64); ok {
-		jobID = uint(numID)
-	}
-
-	// Handle candidateId as either string or number
-	var candidateID uint
-	if strID, ok := application["candidateId"].(string); ok {
-		if parsedID, err := strconv.ParseUint(strID, 10, 32); err == nil {
-			candidateID = uint(parsedID)
-		}
-	} else if numID, ok := application["candidateId"].(int); ok {
-		candidateID = uint(numID)
-	} else if numID, ok := application["candidateId"].(int64); ok {
-		candidateID = uint(numID)
-	} else if numID, ok := application["candidateId"].(float64); ok {
-		candidateID = uint(numID)
-	}
-
-	// if jobID == 0 {
-	// 	return fmt.Errorf("jobId is required")
-	// }
-	// if candidateID == 0 {
-	// 	return fmt.Errorf("candidateId is required")
-	// }
-
 	kafkaMessage := ApplicationKafkaMessage{
-		ApplicationID: applicationID,
-		JobID:         jobID,
-		ResumeURL:     resumeURL,
-		CandidateID:   candidateID,
+		ApplicationID: application.ApplicationID,
+		JobID:         uint(application.JobID),
+		ResumeURL:     application.ResumeURL,
+		CandidateID:   uint(application.CandidateID),
 	}
 
 	appBytes, err := json.Marshal(kafkaMessage)
@@ -219,3 +274,121 @@ func (p *Publisher) PublishApplication(application bson.M) error {
 	log.Printf("Application published successfully to partition %d at offset %d", partition, offset)
 	return nil
 }
+
+// PublishApplicationEvent publishes an application lifecycle event such as
+// ApplicationEventWithdrawn, with the application's current stage
+func (p *Publisher) PublishApplicationEvent(eventType string, application *models.Application) error {
+	kafkaMessage := ApplicationEventKafkaMessage{
+		Event:         eventType,
+		ApplicationID: application.ApplicationID,
+		JobID:         uint(application.JobID),
+		CandidateID:   uint(application.CandidateID),
+		CompanyID:     uint(application.CompanyID),
+		Stage:         application.Status.CurrentStage,
+		Reason:        application.Status.Reason,
+		OccurredAt:    application.Status.LastUpdated,
+	}
+	if application.Disposition != nil {
+		kafkaMessage.CandidateMessage = application.Disposition.CandidateMessage
+	}
+	switch eventType {
+	case ApplicationEventTagsChanged:
+		// tag changes leave the stage and its timestamp alone
+		kafkaMessage.Tags = application.Tags
+		kafkaMessage.OccurredAt = time.Now()
+	case ApplicationEventResumeUploaded:
+		kafkaMessage.ResumeURL = application.ResumeURL
+		kafkaMessage.OccurredAt = application.Resume.UploadedAt
+	}
+
+	eventBytes, err := json.Marshal(kafkaMessage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal application event: %v", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: KAFKA_APPLICATION_EVENTS_TOPIC,
+		Key:   sarama.StringEncoder(application.ApplicationID),
+		Value: sarama.ByteEncoder(eventBytes),
+	}
+
+	partition, offset, err := p.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send application event: %v", err)
+	}
+
+	log.Printf("Application event %s published to partition %d at offset %d", eventType, partition, offset)
+	return nil
+}
+
+// PublishOfferEvent publishes an offer event such as OfferEventExtended. Messages are
+// keyed by application so they stay in order with the application's other events.
+func (p *Publisher) PublishOfferEvent(eventType string, offer *models.Offer) error {
+	kafkaMessage := OfferKafkaMessage{
+		Event:         eventType,
+		OfferID:       offer.ID,
+		ApplicationID: offer.ApplicationID,
+		JobID:         offer.JobID,
+		CompanyID:     offer.CompanyID,
+		CandidateID:   offer.CandidateID,
+		Status:        offer.Status,
+		Salary:        offer.Salary,
+		Currency:      offer.Currency,
+		StartDate:     offer.StartDate,
+		ExpiresAt:     offer.ExpiresAt,
+		OccurredAt:    offer.UpdatedAt,
+	}
+
+	eventBytes, err := json.Marshal(kafkaMessage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal offer event: %v", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: KAFKA_APPLICATION_EVENTS_TOPIC,
+		Key:   sarama.StringEncoder(offer.ApplicationID),
+		Value: sarama.ByteEncoder(eventBytes),
+	}
+
+	partition, offset, err := p.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send offer event: %v", err)
+	}
+
+	log.Printf("Offer event %s published to partition %d at offset %d", eventType, partition, offset)
+	return nil
+}
+
+// PublishNoteMention notifies a user mentioned in a note. Messages are keyed by the
+// mentioned user so each user's notifications stay in order.
+func (p *Publisher) PublishNoteMention(note *models.Note, mentionedUserID uint) error {
+	kafkaMessage := NotificationKafkaMessage{
+		Event:         NotificationEventNoteMention,
+		UserID:        mentionedUserID,
+		NoteID:        note.ID,
+		ApplicationID: note.ApplicationID,
+		JobID:         note.JobID,
+		CompanyID:     note.CompanyID,
+		AuthorID:      note.AuthorID,
+		OccurredAt:    note.UpdatedAt,
+	}
+
+	notificationBytes, err := json.Marshal(kafkaMessage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %v", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: KAFKA_NOTIFICATIONS_TOPIC,
+		Key:   sarama.StringEncoder(strconv.FormatUint(uint64(mentionedUserID), 10)),
+		Value: sarama.ByteEncoder(notificationBytes),
+	}
+
+	partition, offset, err := p.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %v", err)
+	}
+
+	log.Printf("Mention notification for user %d published to partition %d at offset %d", mentionedUserID, partition, offset)
+	return nil
+}
@@ -2,12 +2,14 @@ package kafka
 
 import (
 	"jobs-svc/internal/models"
-
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 type PublisherInterface interface {
 	PublishJob(job *models.Job) error
-	PublishApplication(application bson.M) error
+	PublishJobEvent(eventType string, job *models.Job) error
+	PublishApplication(application *models.Application) error
+	PublishApplicationEvent(eventType string, application *models.Application) error
+	PublishNoteMention(note *models.Note, mentionedUserID uint) error
+	PublishOfferEvent(eventType string, offer *models.Offer) error
 	Close() error
 }
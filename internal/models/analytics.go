@@ -0,0 +1,227 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// FunnelFilter selects the applications a funnel report covers. Applications are
+// picked by the date they were submitted, so a report follows one cohort of candidates.
+type FunnelFilter struct {
+	JobID         uint
+	CompanyID     uint
+	AppliedAfter  *time.Time
+	AppliedBefore *time.Time
+}
+
+// StageVisitGroup counts the visits applications made to Stage that ended in a move to
+// Next. Next is empty for applications still in the stage. Durations holds how long each
+// finished visit lasted and SinceApplied how long after applying each visit began, both
+// in milliseconds.
+type StageVisitGroup struct {
+	Stage        string  `bson:"stage"`
+	Next         string  `bson:"next"`
+	Count        int64   `bson:"count"`
+	Durations    []int64 `bson:"durations"`
+	SinceApplied []int64 `bson:"since_applied"`
+}
+
+// FunnelStage reports how applications moved through one stage. Entered counts visits,
+// so an application that returns to a stage is counted each time. Advanced counts moves
+// to another open stage or a hired stage and DroppedOff moves to any other terminal
+// stage; the rates are relative to Entered.
+type FunnelStage struct {
+	Stage              string   `json:"stage"`
+	Terminal           bool     `json:"terminal"`
+	Entered            int64    `json:"entered"`
+	Current            int64    `json:"current"`
+	Advanced           int64    `json:"advanced"`
+	DroppedOff         int64    `json:"droppedOff"`
+	ConversionRate     float64  `json:"conversionRate"`
+	DropOffRate        float64  `json:"dropOffRate"`
+	MedianHoursInStage *float64 `json:"medianHoursInStage,omitempty"`
+}
+
+// FunnelReport is the hiring funnel of a job or a company
+type FunnelReport struct {
+	JobID                 uint          `json:"jobId,omitempty"`
+	CompanyID             uint          `json:"companyId"`
+	AppliedAfter          *time.Time    `json:"appliedAfter,omitempty"`
+	AppliedBefore         *time.Time    `json:"appliedBefore,omitempty"`
+	Applications          int64         `json:"applications"`
+	Hired                 int64         `json:"hired"`
+	HireRate              float64       `json:"hireRate"`
+	MedianTimeToHireHours *float64      `json:"medianTimeToHireHours,omitempty"`
+	Stages                []FunnelStage `json:"stages"`
+}
+
+// NewFunnelReport builds a report from stage visits. Stages are listed in the order of
+// the pipelines given, with the stages of earlier pipelines first, followed by stages
+// no pipeline names in name order. A stage means what the first pipeline naming it says.
+func NewFunnelReport(filter FunnelFilter, pipelines []Pipeline, groups []StageVisitGroup) *FunnelReport {
+	report := &FunnelReport{
+		JobID:         filter.JobID,
+		CompanyID:     filter.CompanyID,
+		AppliedAfter:  filter.AppliedAfter,
+		AppliedBefore: filter.AppliedBefore,
+		Stages:        make([]FunnelStage, 0),
+	}
+
+	index := make(map[string]int)
+	addStage := func(name string) int {
+		if i, ok := index[name]; ok {
+			return i
+		}
+		index[name] = len(report.Stages)
+		report.Stages = append(report.Stages, FunnelStage{Stage: name, Terminal: funnelStageTerminal(pipelines, name)})
+		return index[name]
+	}
+	for _, pipeline := range pipelines {
+		for _, stage := range pipeline.Stages {
+			addStage(stage.Name)
+		}
+	}
+	known := len(report.Stages)
+
+	durations := make(map[string][]int64)
+	var timesToHire []int64
+	for _, group := range groups {
+		i := addStage(group.Stage)
+		stage := &report.Stages[i]
+		stage.Entered += group.Count
+		durations[group.Stage] = append(durations[group.Stage], group.Durations...)
+
+		switch {
+		case group.Next == "":
+			stage.Current += group.Count
+			report.Applications += group.Count
+		case funnelStageTerminal(pipelines, group.Next) && !funnelStageHired(pipelines, group.Next):
+			stage.DroppedOff += group.Count
+		default:
+			stage.Advanced += group.Count
+		}
+
+		if funnelStageHired(pipelines, group.Stage) {
+			if group.Next == "" {
+				report.Hired += group.Count
+			}
+			timesToHire = append(timesToHire, group.SinceApplied...)
+		}
+	}
+
+	extra := report.Stages[known:]
+	sort.Slice(extra, func(i, j int) bool { return extra[i].Stage < extra[j].Stage })
+	for i := range report.Stages {
+		stage := &report.Stages[i]
+		stage.ConversionRate = rate(stage.Advanced, stage.Entered)
+		stage.DropOffRate = rate(stage.DroppedOff, stage.Entered)
+		stage.MedianHoursInStage = medianHours(durations[stage.Stage])
+	}
+	report.HireRate = rate(report.Hired, report.Applications)
+	report.MedianTimeToHireHours = medianHours(timesToHire)
+	return report
+}
+
+// funnelStageTerminal reports whether the first pipeline naming stage treats it as terminal
+func funnelStageTerminal(pipelines []Pipeline, name string) bool {
+	for _, pipeline := range pipelines {
+		if _, ok := pipeline.Stage(name); ok {
+			return pipeline.IsTerminal(name)
+		}
+	}
+	return DefaultPipeline.IsTerminal(name)
+}
+
+// funnelStageHired reports whether the first pipeline naming stage gives it the hired kind
+func funnelStageHired(pipelines []Pipeline, name string) bool {
+	for _, pipeline := range pipelines {
+		if stage, ok := pipeline.Stage(name); ok {
+			return stage.Kind == StageKindHired
+		}
+	}
+	return false
+}
+
+func rate(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
+
+// medianHours is the median of durations in milliseconds, in hours, or nil when there are none
+func medianHours(durations []int64) *float64 {
+	if len(durations) == 0 {
+		return nil
+	}
+	sorted := append([]int64(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	middle := len(sorted) / 2
+	median := float64(sorted[middle])
+	if len(sorted)%2 == 0 {
+		median = (float64(sorted[middle-1]) + median) / 2
+	}
+	hours := math.Round(median/float64(time.Hour/time.Millisecond)*100) / 100
+	return &hours
+}
+
+// RejectionReasonCount counts the applications of a report rejected with one reason
+type RejectionReasonCount struct {
+	Code  string `bson:"_id"`
+	Label string `bson:"label"`
+	Count int64  `bson:"count"`
+}
+
+// RejectionReasonShare is how many rejections gave a reason and their share of all rejections
+type RejectionReasonShare struct {
+	Code  string  `json:"code"`
+	Label string  `json:"label"`
+	Count int64   `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// RejectionReport breaks down why a job's applications were rejected
+type RejectionReport struct {
+	JobID         uint                   `json:"jobId"`
+	AppliedAfter  *time.Time             `json:"appliedAfter,omitempty"`
+	AppliedBefore *time.Time             `json:"appliedBefore,omitempty"`
+	Rejected      int64                  `json:"rejected"`
+	Reasons       []RejectionReasonShare `json:"reasons"`
+}
+
+// NewRejectionReport builds a report from per-reason counts, most common reason first.
+// Reasons are labelled as the catalog labels them now, falling back to the label
+// recorded at the time for codes no longer in it.
+func NewRejectionReport(filter FunnelFilter, catalog []RejectionReason, counts []RejectionReasonCount) *RejectionReport {
+	report := &RejectionReport{
+		JobID:         filter.JobID,
+		AppliedAfter:  filter.AppliedAfter,
+		AppliedBefore: filter.AppliedBefore,
+		Reasons:       make([]RejectionReasonShare, 0, len(counts)),
+	}
+	labels := make(map[string]string, len(catalog))
+	for _, reason := range catalog {
+		labels[reason.Code] = reason.Label
+	}
+
+	for _, count := range counts {
+		label, ok := labels[count.Code]
+		if !ok {
+			label = count.Label
+		}
+		report.Rejected += count.Count
+		report.Reasons = append(report.Reasons, RejectionReasonShare{Code: count.Code, Label: label, Count: count.Count})
+	}
+	sort.Slice(report.Reasons, func(i, j int) bool {
+		if report.Reasons[i].Count != report.Reasons[j].Count {
+			return report.Reasons[i].Count > report.Reasons[j].Count
+		}
+		return report.Reasons[i].Code < report.Reasons[j].Code
+	})
+	for i := range report.Reasons {
+		report.Reasons[i].Share = rate(report.Reasons[i].Count, report.Rejected)
+	}
+	return report
+}
@@ -2,29 +2,172 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net/mail"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// application model for MongoDB
+// Application is an application document in the applications collection
 type Application struct {
-	ApplicationID string `bson:"application_id" json:"applicationId"`
-	CandidateID   uint   `bson:"candidate_id" json:"candidateId"`
-	JobID         uint   `bson:"job_id" json:"jobId"`
-	ResumeURL     string `bson:"resumeUrl" json:"resumeUrl"`
-	Status        Status `bson:"status" json:"status"`
-	Email         string `bson:"email" json:"email"`
-	Phone         string `bson:"phone" json:"phone"`
+	ApplicationID     string    `bson:"application_id" json:"applicationId"`
+	CandidateID       NumericID `bson:"candidate_id" json:"candidateId"`
+	JobID             NumericID `bson:"job_id" json:"jobId"`
+	CompanyID         NumericID `bson:"company_id" json:"companyId"`
+	ResumeURL         string    `bson:"resume_url" json:"resumeUrl"`
+	Status            Status    `bson:"status" json:"status"`
+	Email             string    `bson:"email" json:"email"`
+	Phone             string    `bson:"phone" json:"phone"`
+	Skills            []string  `bson:"skills,omitempty" json:"skills,omitempty"`
+	YearsOfExperience float64   `bson:"years_of_experience,omitempty" json:"yearsOfExperience,omitempty"`
+	AppliedAt         time.Time `bson:"applied_at" json:"appliedAt"`
+	// Tags are labels recruiters put on applications
+	Tags []string `bson:"tags,omitempty" json:"tags,omitempty"`
+	// Rating is the mean overall rating of the application's scorecards; nil until it has been rated
+	Rating *float64 `bson:"rating,omitempty" json:"rating,omitempty"`
+	// Scorecards holds the latest scorecard of each interviewer
+	Scorecards []Scorecard `bson:"scorecards,omitempty" json:"scorecards,omitempty"`
+	// StageHistory is append-only; every stage change adds an entry
+	StageHistory []StageChange `bson:"stage_history,omitempty" json:"stageHistory,omitempty"`
+	// Active is false once the candidate withdraws. Only active applications count
+	// towards the one-application-per-job limit.
+	Active      bool       `bson:"active" json:"active"`
+	WithdrawnAt *time.Time `bson:"withdrawn_at,omitempty" json:"withdrawnAt,omitempty"`
+	// Disposition is set when the application is moved to a rejected stage
+	Disposition *Disposition `bson:"disposition,omitempty" json:"disposition,omitempty"`
+	// Resume is the resume uploaded through POST /applications/{id}/resume. Applications
+	// with one are served with a signed download link as their ResumeURL.
+	Resume *ResumeFile `bson:"resume,omitempty" json:"resume,omitempty"`
+	// Match scores the application against the job's skills and experience. It is
+	// computed in the background, so it is nil until the first scoring finishes.
+	Match *ResumeMatch `bson:"match,omitempty" json:"match,omitempty"`
 }
 
 type Status struct {
 	CurrentStage string    `bson:"current_stage" json:"currentStage"`
 	LastUpdated  time.Time `bson:"last_updated" json:"lastUpdated"`
+	Reason       string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// StageChange records one move of an application between stages.
+// ChangedBy is 0 for changes the service makes on its own, such as closing a job.
+type StageChange struct {
+	From      string    `bson:"from" json:"from"`
+	To        string    `bson:"to" json:"to"`
+	ChangedBy uint      `bson:"changed_by" json:"changedBy"`
+	ChangedAt time.Time `bson:"changed_at" json:"changedAt"`
+	Reason    string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// ValidationError reports a submitted field that failed validation
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Validate checks the fields a candidate submits with an application
+func (a *Application) Validate() error {
+	if a.JobID == 0 || a.CandidateID == 0 {
+		return &ValidationError{Message: "JobID and CandidateID are required"}
+	}
+	if a.Email != "" {
+		if _, err := mail.ParseAddress(a.Email); err != nil {
+			return &ValidationError{Message: "invalid email address"}
+		}
+	}
+	if a.ResumeURL != "" {
+		resumeURL, err := url.ParseRequestURI(a.ResumeURL)
+		if err != nil || (resumeURL.Scheme != "http" && resumeURL.Scheme != "https") || resumeURL.Host == "" {
+			return &ValidationError{Message: "resumeUrl must be an http or https URL"}
+		}
+	}
+	if a.YearsOfExperience < 0 {
+		return &ValidationError{Message: "yearsOfExperience cannot be negative"}
+	}
+	return nil
+}
+
+// NumericID is an ID stored as a number. Older application documents and clients
+// send IDs as strings or floats, so it decodes from any of those.
+type NumericID uint
+
+func (id *NumericID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = 0
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return id.parse(text)
+	}
+
+	var number float64
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("ID must be a number or string: %s", string(data))
+	}
+	return id.fromFloat(number)
+}
+
+func (id *NumericID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.Int32:
+		return id.fromInt(int64(value.Int32()))
+	case bsontype.Int64:
+		return id.fromInt(value.Int64())
+	case bsontype.Double:
+		return id.fromFloat(value.Double())
+	case bsontype.String:
+		return id.parse(value.StringValue())
+	case bsontype.Null, bsontype.Undefined:
+		*id = 0
+		return nil
+	}
+	return fmt.Errorf("cannot decode %s into an ID", t)
+}
+
+func (id *NumericID) parse(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		*id = 0
+		return nil
+	}
+	parsed, err := strconv.ParseUint(text, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid ID: %q", text)
+	}
+	*id = NumericID(parsed)
+	return nil
+}
+
+func (id *NumericID) fromInt(value int64) error {
+	if value < 0 || value > math.MaxUint32 {
+		return fmt.Errorf("invalid ID: %d", value)
+	}
+	*id = NumericID(value)
+	return nil
+}
+
+func (id *NumericID) fromFloat(value float64) error {
+	if value != math.Trunc(value) || value < 0 || value > math.MaxUint32 {
+		return fmt.Errorf("invalid ID: %v", value)
+	}
+	return id.fromInt(int64(value))
 }
 
 var MongoDB *mongo.Database
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	DefaultApplicationPageLimit = 20
+	MaxApplicationPageLimit     = 100
+)
+
+// ApplicationSortFields maps the sort keys accepted by application listings to their document fields
+var ApplicationSortFields = map[string]string{
+	"appliedAt":   "applied_at",
+	"lastUpdated": "status.last_updated",
+	"rating":      "rating",
+	"matchScore":  "match.score",
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ApplicationQueryOptions holds cursor paging, filters and sorting for application listings.
+// Results are ordered by the sort field, then by application ID to break ties.
+type ApplicationQueryOptions struct {
+	Limit         int
+	After         *ApplicationCursor
+	Stages        []string
+	AppliedAfter  *time.Time
+	AppliedBefore *time.Time
+	Tags          []string
+	MinRating     *float64
+	MaxRating     *float64
+	SortBy        string
+	SortDesc      bool
+}
+
+// ApplicationCursor is the position of the last application on a page: its sort value
+// and ID. Value is a time.Time, a float64, or nil for an application without a rating
+// or match score.
+type ApplicationCursor struct {
+	Value         interface{}
+	ApplicationID string
+}
+
+// applicationCursorToken is the encoded form of an ApplicationCursor. The sort key and
+// direction are included so a cursor cannot be reused with a different ordering.
+type applicationCursorToken struct {
+	SortBy   string   `json:"s"`
+	SortDesc bool     `json:"d,omitempty"`
+	Time     *int64   `json:"t,omitempty"`
+	Number   *float64 `json:"n,omitempty"`
+	ID       string   `json:"id"`
+}
+
+// Normalize fills in defaults for paging and sorting
+func (o *ApplicationQueryOptions) Normalize() {
+	if o.Limit < 1 {
+		o.Limit = DefaultApplicationPageLimit
+	}
+	if o.Limit > MaxApplicationPageLimit {
+		o.Limit = MaxApplicationPageLimit
+	}
+	if o.SortBy == "" {
+		o.SortBy = "appliedAt"
+		o.SortDesc = true
+	}
+}
+
+// SortValue is the application's value for one of the ApplicationSortFields keys
+func (a Application) SortValue(sortBy string) interface{} {
+	switch sortBy {
+	case "lastUpdated":
+		return a.Status.LastUpdated
+	case "rating":
+		if a.Rating == nil {
+			return nil
+		}
+		return *a.Rating
+	case "matchScore":
+		if a.Match == nil {
+			return nil
+		}
+		return a.Match.Score
+	default:
+		return a.AppliedAt
+	}
+}
+
+// EncodeApplicationCursor returns the cursor for the page that follows app
+func EncodeApplicationCursor(app Application, opts ApplicationQueryOptions) string {
+	token := applicationCursorToken{SortBy: opts.SortBy, SortDesc: opts.SortDesc, ID: app.ApplicationID}
+	switch value := app.SortValue(opts.SortBy).(type) {
+	case time.Time:
+		nanos := value.UnixNano()
+		token.Time = &nanos
+	case float64:
+		token.Number = &value
+	}
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeApplicationCursor parses a cursor returned with an earlier page. It must have
+// been issued for the same sort key and direction as opts.
+func DecodeApplicationCursor(cursor string, opts ApplicationQueryOptions) (*ApplicationCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var token applicationCursorToken
+	if err := json.Unmarshal(data, &token); err != nil || token.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if token.SortBy != opts.SortBy || token.SortDesc != opts.SortDesc {
+		return nil, ErrInvalidCursor
+	}
+
+	after := &ApplicationCursor{ApplicationID: token.ID}
+	numeric := opts.SortBy == "rating" || opts.SortBy == "matchScore"
+	switch {
+	case token.Time != nil && !numeric:
+		after.Value = time.Unix(0, *token.Time).UTC()
+	case token.Number != nil && numeric:
+		after.Value = *token.Number
+	case token.Time == nil && token.Number == nil && numeric:
+		after.Value = nil
+	default:
+		return nil, ErrInvalidCursor
+	}
+	return after, nil
+}
+
+// ApplicationPage is the cursor paging envelope returned by application listings.
+// NextCursor is empty on the last page.
+type ApplicationPage struct {
+	Items      []Application `json:"items"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+	Limit      int           `json:"limit"`
+}
+
+// NewApplicationPage builds a page from up to opts.Limit+1 applications; the extra
+// one only signals that another page exists
+func NewApplicationPage(applications []Application, opts ApplicationQueryOptions) *ApplicationPage {
+	page := &ApplicationPage{Items: applications, Limit: opts.Limit}
+	if page.Items == nil {
+		page.Items = make([]Application, 0)
+	}
+	if len(page.Items) > opts.Limit {
+		page.Items = page.Items[:opts.Limit]
+		page.NextCursor = EncodeApplicationCursor(page.Items[len(page.Items)-1], opts)
+	}
+	return page
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Bulk actions recruiters can apply to many applications at once
+const (
+	BulkActionMoveStage  = "move_stage"
+	BulkActionAddTags    = "add_tags"
+	BulkActionRemoveTags = "remove_tags"
+	BulkActionReject     = "reject"
+)
+
+// Outcomes of a bulk action for one application
+const (
+	BulkItemUpdated   = "updated"
+	BulkItemUnchanged = "unchanged"
+	BulkItemFailed    = "failed"
+)
+
+const (
+	MaxBulkApplications = 200
+	MaxTagLength        = 50
+)
+
+// BulkAction applies one action to a list of applications. Stage is the target of
+// move_stage, Tags the labels added or removed, and Disposition the reason for reject.
+type BulkAction struct {
+	ApplicationIDs []string     `json:"applicationIds"`
+	Action         string       `json:"action"`
+	Stage          string       `json:"stage,omitempty"`
+	Reason         string       `json:"reason,omitempty"`
+	Tags           []string     `json:"tags,omitempty"`
+	Disposition    *Disposition `json:"disposition,omitempty"`
+}
+
+// Validate checks that the action is known and has what it needs, dropping blank and
+// repeated application IDs and tags
+func (a *BulkAction) Validate() error {
+	a.ApplicationIDs = uniqueTrimmed(a.ApplicationIDs)
+	if len(a.ApplicationIDs) == 0 {
+		return &ValidationError{Message: "applicationIds is required"}
+	}
+	if len(a.ApplicationIDs) > MaxBulkApplications {
+		return &ValidationError{Message: fmt.Sprintf("at most %d applications can be updated at once", MaxBulkApplications)}
+	}
+	a.Stage = strings.TrimSpace(a.Stage)
+	a.Reason = strings.TrimSpace(a.Reason)
+
+	switch a.Action {
+	case BulkActionMoveStage:
+		if a.Stage == "" {
+			return &ValidationError{Message: "stage is required to move applications"}
+		}
+	case BulkActionAddTags, BulkActionRemoveTags:
+		a.Tags = uniqueTrimmed(a.Tags)
+		if len(a.Tags) == 0 {
+			return &ValidationError{Message: "tags is required to add or remove tags"}
+		}
+		for _, tag := range a.Tags {
+			if len(tag) > MaxTagLength {
+				return &ValidationError{Message: fmt.Sprintf("tags cannot be longer than %d characters", MaxTagLength)}
+			}
+		}
+	case BulkActionReject:
+		if a.Disposition == nil {
+			return &ValidationError{Message: "disposition is required to reject applications"}
+		}
+		return a.Disposition.Validate()
+	default:
+		return &ValidationError{Message: fmt.Sprintf("unknown action %q", a.Action)}
+	}
+	if a.Disposition != nil {
+		return &ValidationError{Message: "a disposition can only be given when rejecting applications"}
+	}
+	return nil
+}
+
+// ApplicationUpdate is the change a bulk action makes to one application. Change moves
+// it like a single stage update, guarded by Change.From, and Disposition is stored with
+// the move. An update adds or removes tags, not both.
+type ApplicationUpdate struct {
+	ApplicationID string
+	Change        *StageChange
+	Disposition   *Disposition
+	AddTags       []string
+	RemoveTags    []string
+}
+
+// AppliedTo reports whether app reflects the update: its latest stage change is Change
+// and it has the added tags and none of the removed ones
+func (u *ApplicationUpdate) AppliedTo(app *Application) bool {
+	if u.Change != nil {
+		if len(app.StageHistory) == 0 {
+			return false
+		}
+		last := app.StageHistory[len(app.StageHistory)-1]
+		if last.To != u.Change.To || last.ChangedBy != u.Change.ChangedBy || !last.ChangedAt.Equal(u.Change.ChangedAt) {
+			return false
+		}
+	}
+	for _, tag := range u.AddTags {
+		if !slices.Contains(app.Tags, tag) {
+			return false
+		}
+	}
+	for _, tag := range u.RemoveTags {
+		if slices.Contains(app.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// BulkItemResult is the outcome of a bulk action for one application. Error explains
+// why it failed.
+type BulkItemResult struct {
+	ApplicationID string `json:"applicationId"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+}
+
+// BulkActionResult reports a bulk action per application, in the order they were given
+type BulkActionResult struct {
+	Action    string           `json:"action"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// Add records the outcome for one application, counting it by status
+func (r *BulkActionResult) Add(item BulkItemResult) {
+	switch item.Status {
+	case BulkItemUpdated:
+		r.Updated++
+	case BulkItemUnchanged:
+		r.Unchanged++
+	default:
+		r.Failed++
+	}
+	r.Results = append(r.Results, item)
+}
+
+// uniqueTrimmed trims values and drops blank and repeated ones, keeping their order
+func uniqueTrimmed(values []string) []string {
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !slices.Contains(unique, value) {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	MaxRejectionReasonLabelLength = 100
+	MaxDispositionMessageLength   = 2000
+)
+
+var rejectionReasonCodePattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+// RejectionReason is an entry in a company's catalog of reasons for rejecting
+// candidates. Archived reasons cannot be used for new rejections but still label the
+// rejections made with them.
+type RejectionReason struct {
+	Code        string    `bson:"code" json:"code"`
+	CompanyID   uint      `bson:"company_id" json:"companyId,omitempty"`
+	Label       string    `bson:"label" json:"label"`
+	Description string    `bson:"description,omitempty" json:"description,omitempty"`
+	Archived    bool      `bson:"archived" json:"archived"`
+	CreatedAt   time.Time `bson:"created_at,omitempty" json:"createdAt,omitempty"`
+	UpdatedAt   time.Time `bson:"updated_at,omitempty" json:"updatedAt,omitempty"`
+}
+
+// DefaultRejectionReasons is the catalog of companies that have not defined their own.
+// A company's catalog starts as a copy of it the first time the company changes it.
+var DefaultRejectionReasons = []RejectionReason{
+	{Code: "skills_mismatch", Label: "Skills do not match the role"},
+	{Code: "insufficient_experience", Label: "Not enough experience"},
+	{Code: "interview_performance", Label: "Did not pass the interviews"},
+	{Code: "compensation_mismatch", Label: "Compensation expectations do not match"},
+	{Code: "position_filled", Label: "Position filled by another candidate"},
+	{Code: "candidate_unresponsive", Label: "Candidate stopped responding"},
+	{Code: "other", Label: "Other"},
+}
+
+// Validate checks a rejection reason's code and label
+func (r *RejectionReason) Validate() error {
+	r.Code = strings.TrimSpace(r.Code)
+	r.Label = strings.TrimSpace(r.Label)
+	r.Description = strings.TrimSpace(r.Description)
+
+	if !rejectionReasonCodePattern.MatchString(r.Code) {
+		return &ValidationError{Message: "code must be 1 to 50 lowercase letters, digits or underscores"}
+	}
+	if r.Label == "" {
+		return &ValidationError{Message: "label is required"}
+	}
+	if len(r.Label) > MaxRejectionReasonLabelLength {
+		return &ValidationError{Message: fmt.Sprintf("label cannot be longer than %d characters", MaxRejectionReasonLabelLength)}
+	}
+	return nil
+}
+
+// Disposition records why an application was rejected. The reason and internal note
+// are for the hiring team; only CandidateMessage is shown to the candidate.
+type Disposition struct {
+	ReasonCode       string    `bson:"reason_code" json:"reasonCode"`
+	ReasonLabel      string    `bson:"reason_label" json:"reasonLabel,omitempty"`
+	InternalNote     string    `bson:"internal_note,omitempty" json:"internalNote,omitempty"`
+	CandidateMessage string    `bson:"candidate_message,omitempty" json:"candidateMessage,omitempty"`
+	DecidedBy        uint      `bson:"decided_by" json:"decidedBy,omitempty"`
+	DecidedAt        time.Time `bson:"decided_at" json:"decidedAt"`
+}
+
+// Validate checks the fields a recruiter submits with a rejection
+func (d *Disposition) Validate() error {
+	d.ReasonCode = strings.TrimSpace(d.ReasonCode)
+	d.InternalNote = strings.TrimSpace(d.InternalNote)
+	d.CandidateMessage = strings.TrimSpace(d.CandidateMessage)
+
+	if d.ReasonCode == "" {
+		return &ValidationError{Message: "reasonCode is required"}
+	}
+	if len(d.InternalNote) > MaxDispositionMessageLength || len(d.CandidateMessage) > MaxDispositionMessageLength {
+		return &ValidationError{Message: fmt.Sprintf("internalNote and candidateMessage cannot be longer than %d characters", MaxDispositionMessageLength)}
+	}
+	return nil
+}
+
+// CandidateView returns a copy of the application holding only what the candidate may
+// see. It is an allow-list, so fields added for the hiring team stay hidden until they
+// are added here: tags, ratings, scorecards, match scores, the reasons recruiters give
+// for stage changes and the rejection reason and internal note are all left out.
+func (a *Application) CandidateView() *Application {
+	if a == nil {
+		return nil
+	}
+	view := &Application{
+		ApplicationID:     a.ApplicationID,
+		CandidateID:       a.CandidateID,
+		JobID:             a.JobID,
+		CompanyID:         a.CompanyID,
+		ResumeURL:         a.ResumeURL,
+		Status:            Status{CurrentStage: a.Status.CurrentStage, LastUpdated: a.Status.LastUpdated},
+		Email:             a.Email,
+		Phone:             a.Phone,
+		Skills:            a.Skills,
+		YearsOfExperience: a.YearsOfExperience,
+		AppliedAt:         a.AppliedAt,
+		Active:            a.Active,
+		WithdrawnAt:       a.WithdrawnAt,
+		Resume:            a.Resume,
+	}
+	if len(a.StageHistory) > 0 {
+		view.StageHistory = make([]StageChange, len(a.StageHistory))
+		for i, change := range a.StageHistory {
+			view.StageHistory[i] = StageChange{From: change.From, To: change.To, ChangedAt: change.ChangedAt}
+		}
+	}
+	if a.Disposition != nil {
+		view.Disposition = &Disposition{
+			CandidateMessage: a.Disposition.CandidateMessage,
+			DecidedAt:        a.Disposition.DecidedAt,
+		}
+	}
+	return view
+}
+
+// PublicView is the candidate view served without authentication. It also leaves out
+// the resume, since anyone can look applications up by ID.
+func (a *Application) PublicView() *Application {
+	view := a.CandidateView()
+	if view != nil {
+		view.ResumeURL = ""
+		view.Resume = nil
+	}
+	return view
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+// icalMaxLineOctets is the longest a content line may be before it has to be folded (RFC 5545 3.1)
+const icalMaxLineOctets = 75
+
+// ICalendar renders the interview as an RFC 5545 calendar with a single event. Cancelled
+// interviews are published with STATUS:CANCELLED so importing them removes the event.
+func (i *Interview) ICalendar(now time.Time) []byte {
+	summary := i.Title
+	if summary == "" {
+		summary = "Interview: " + i.JobTitle
+	}
+	status := "CONFIRMED"
+	if i.Status == InterviewStatusCancelled {
+		status = "CANCELLED"
+	}
+
+	var description []string
+	if i.VideoLink != "" {
+		description = append(description, "Join: "+i.VideoLink)
+	}
+	description = append(description, fmt.Sprintf("Application %s for %s", i.ApplicationID, i.JobTitle))
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//SwiftSelect//jobs-svc//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:" + i.ID + "@jobs-svc",
+		"DTSTAMP:" + now.UTC().Format(icalTimeFormat),
+		"DTSTART:" + i.StartsAt.UTC().Format(icalTimeFormat),
+		"DTEND:" + i.EndsAt.UTC().Format(icalTimeFormat),
+		fmt.Sprintf("SEQUENCE:%d", i.Sequence),
+		"STATUS:" + status,
+		"SUMMARY:" + icalEscape(summary),
+		"DESCRIPTION:" + icalEscape(strings.Join(description, "\n")),
+	}
+	location := i.Location
+	if location == "" {
+		location = i.VideoLink
+	}
+	lines = append(lines, "LOCATION:"+icalEscape(location))
+	if i.VideoLink != "" {
+		lines = append(lines, "URL:"+i.VideoLink)
+	}
+	if i.CandidateEmail != "" {
+		lines = append(lines, "ATTENDEE;ROLE=REQ-PARTICIPANT:mailto:"+i.CandidateEmail)
+	}
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	var calendar strings.Builder
+	for _, line := range lines {
+		calendar.WriteString(icalFold(line))
+		calendar.WriteString("\r\n")
+	}
+	return []byte(calendar.String())
+}
+
+// icalEscape escapes a TEXT value (RFC 5545 3.3.11)
+func icalEscape(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// icalFold splits a content line into lines of at most 75 octets, continuing each one
+// with a leading space and never splitting a UTF-8 character
+func icalFold(line string) string {
+	if len(line) <= icalMaxLineOctets {
+		return line
+	}
+	var folded strings.Builder
+	limit := icalMaxLineOctets
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			folded.WriteString("\r\n ")
+			// the leading space counts towards the continuation line's length
+			limit = icalMaxLineOctets - 1
+			width = 0
+		}
+		folded.WriteRune(r)
+		width += size
+	}
+	return folded.String()
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	InterviewStatusScheduled = "scheduled"
+	InterviewStatusCancelled = "cancelled"
+)
+
+const MaxInterviewDuration = 8 * time.Hour
+
+// Interview is a time slot in which interviewers meet the candidate of an application.
+// Sequence starts at 0 and goes up each time the interview is rescheduled or cancelled,
+// so calendar clients replace the copy they imported.
+type Interview struct {
+	ID             string    `bson:"interview_id" json:"id"`
+	ApplicationID  string    `bson:"application_id" json:"applicationId"`
+	JobID          uint      `bson:"job_id" json:"jobId"`
+	JobTitle       string    `bson:"job_title" json:"jobTitle"`
+	CompanyID      uint      `bson:"company_id" json:"companyId"`
+	CandidateID    uint      `bson:"candidate_id" json:"candidateId"`
+	CandidateEmail string    `bson:"candidate_email,omitempty" json:"candidateEmail,omitempty"`
+	Title          string    `bson:"title,omitempty" json:"title,omitempty"`
+	InterviewerIDs []uint    `bson:"interviewer_ids" json:"interviewerIds"`
+	StartsAt       time.Time `bson:"starts_at" json:"startsAt"`
+	EndsAt         time.Time `bson:"ends_at" json:"endsAt"`
+	Location       string    `bson:"location,omitempty" json:"location,omitempty"`
+	VideoLink      string    `bson:"video_link,omitempty" json:"videoLink,omitempty"`
+	Status         string    `bson:"status" json:"status"`
+	Sequence       int       `bson:"sequence" json:"sequence"`
+	CreatedBy      uint      `bson:"created_by" json:"createdBy"`
+	CreatedAt      time.Time `bson:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updatedAt"`
+	// SlotHold identifies the slots holding the interviewers' time for the current schedule
+	SlotHold string `bson:"slot_hold,omitempty" json:"-"`
+}
+
+// InterviewSlot is time an interviewer is held for an interview. All of an
+// interviewer's slots live in one document, so a slot is checked against the others and
+// taken in a single write. Hold tells apart the slots of successive schedules of the
+// same interview while it is being moved.
+type InterviewSlot struct {
+	InterviewID string    `bson:"interview_id"`
+	Hold        string    `bson:"hold"`
+	StartsAt    time.Time `bson:"starts_at"`
+	EndsAt      time.Time `bson:"ends_at"`
+}
+
+// Validate checks the slot, interviewers and meeting place of an interview. Times are
+// converted to UTC and repeated interviewers dropped.
+func (i *Interview) Validate() error {
+	i.Title = strings.TrimSpace(i.Title)
+	i.Location = strings.TrimSpace(i.Location)
+	i.VideoLink = strings.TrimSpace(i.VideoLink)
+
+	if i.StartsAt.IsZero() || i.EndsAt.IsZero() {
+		return &ValidationError{Message: "startsAt and endsAt are required"}
+	}
+	i.StartsAt = i.StartsAt.UTC()
+	i.EndsAt = i.EndsAt.UTC()
+	if !i.EndsAt.After(i.StartsAt) {
+		return &ValidationError{Message: "endsAt must be after startsAt"}
+	}
+	if i.EndsAt.Sub(i.StartsAt) > MaxInterviewDuration {
+		return &ValidationError{Message: "interviews cannot be longer than 8 hours"}
+	}
+
+	interviewers := make([]uint, 0, len(i.InterviewerIDs))
+	for _, id := range i.InterviewerIDs {
+		if id == 0 {
+			return &ValidationError{Message: "interviewer IDs must be positive"}
+		}
+		if !slices.Contains(interviewers, id) {
+			interviewers = append(interviewers, id)
+		}
+	}
+	if len(interviewers) == 0 {
+		return &ValidationError{Message: "at least one interviewer is required"}
+	}
+	i.InterviewerIDs = interviewers
+
+	if i.Location == "" && i.VideoLink == "" {
+		return &ValidationError{Message: "location or videoLink is required"}
+	}
+	if i.VideoLink != "" {
+		link, err := url.ParseRequestURI(i.VideoLink)
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+			return &ValidationError{Message: "videoLink must be an http or https URL"}
+		}
+	}
+	return nil
+}
+
+// Overlaps reports whether the interview is scheduled during any part of start to end
+func (i *Interview) Overlaps(start, end time.Time) bool {
+	return i.Status == InterviewStatusScheduled && i.StartsAt.Before(end) && i.EndsAt.After(start)
+}
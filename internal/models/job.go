@@ -1,8 +1,13 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -24,12 +29,22 @@ type Job struct {
 	SalaryRange      string    `gorm:"not null;default:'$120,000 - $160,000'" json:"salaryRange"`
 	RecruiterId      uint      `json:"recruiterId"`
 	BenefitsAndPerks string    `gorm:"not null;default:'Health, Dental, Vision, 401k'" json:"benefitsAndPerks"`
+	Version          uint      `gorm:"not null;default:1" json:"version"`
+	// PipelineID references one of the company's hiring pipelines; empty means the default pipeline
+	PipelineID string `gorm:"index" json:"pipelineId,omitempty"`
+	// Scorecard is the template interviewers fill in for the job's applications
+	Scorecard *ScorecardTemplate `gorm:"type:jsonb;serializer:json" json:"scorecard,omitempty"`
 }
 
 func (j Job) DaysPostedAgo() int {
 	return int(time.Since(j.PostedDate).Hours() / 24)
 }
 
+// ETag is the entity tag for the current version of the job
+func (j Job) ETag() string {
+	return fmt.Sprintf("\"%d\"", j.Version)
+}
+
 type Recruiter struct {
 	gorm.Model
 	Name  string `gorm:"not null" json:"name"`
@@ -37,8 +52,6 @@ type Recruiter struct {
 	Jobs  []Job  `json:"jobs"`
 }
 
-type JobStatus int
-
 type JobSummary struct {
 	ID            uint   `json:"id"`
 	Title         string `json:"title"`
@@ -48,13 +61,141 @@ type JobSummary struct {
 	SalaryRange   string `json:"salaryRange"`
 }
 
+// jobSearchMigrations add a generated tsvector over the searchable job fields and its GIN index.
+// Title and skills are weighted above overview and description so they rank higher.
+var jobSearchMigrations = []string{
+	`ALTER TABLE jobs ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(skills, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(overview, '')), 'C') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'D')
+	) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_jobs_search_vector ON jobs USING GIN (search_vector)`,
+}
+
+// JobStatus is stored as an integer. Open and Closed keep their original values so
+// existing rows and numeric payloads stay readable; newer states are appended.
+type JobStatus int
+
 const (
 	Open JobStatus = iota
 	Closed
+	Draft
+	Paused
+	Filled
+	Archived
 )
 
+var jobStatusNames = map[JobStatus]string{
+	Draft:    "draft",
+	Open:     "open",
+	Paused:   "paused",
+	Closed:   "closed",
+	Filled:   "filled",
+	Archived: "archived",
+}
+
+// jobStatusTransitions lists the states each status may move to
+var jobStatusTransitions = map[JobStatus][]JobStatus{
+	Draft:    {Open, Archived},
+	Open:     {Paused, Closed, Filled},
+	Paused:   {Open, Closed, Filled},
+	Closed:   {Archived},
+	Filled:   {Archived},
+	Archived: {},
+}
+
+func (s JobStatus) String() string {
+	if name, ok := jobStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("JobStatus(%d)", int(s))
+}
+
+func (s JobStatus) IsValid() bool {
+	_, ok := jobStatusNames[s]
+	return ok
+}
+
+// ListedJobStatuses are the statuses of jobs shown on the public job routes. Drafts
+// have not been published yet and archived jobs have been taken down.
+var ListedJobStatuses = []JobStatus{Open, Paused, Closed, Filled}
+
+// IsListed reports whether a job in status s is shown on the public job routes
+func (s JobStatus) IsListed() bool {
+	return slices.Contains(ListedJobStatuses, s)
+}
+
+// IsClosed reports whether a job in status s no longer takes applications for good
+func (s JobStatus) IsClosed() bool {
+	return s == Closed || s == Filled || s == Archived
+}
+
+// CanTransitionTo reports whether a job in status s may move to next
+func (s JobStatus) CanTransitionTo(next JobStatus) bool {
+	for _, allowed := range jobStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseJobStatus accepts a status name (case-insensitive) or its legacy numeric value
+func ParseJobStatus(value string) (JobStatus, error) {
+	value = strings.TrimSpace(value)
+	for status, name := range jobStatusNames {
+		if strings.EqualFold(name, value) {
+			return status, nil
+		}
+	}
+	if numeric, err := strconv.Atoi(value); err == nil && JobStatus(numeric).IsValid() {
+		return JobStatus(numeric), nil
+	}
+	return 0, fmt.Errorf("invalid job status: %s", value)
+}
+
+func (s JobStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *JobStatus) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		status, err := ParseJobStatus(name)
+		if err != nil {
+			return err
+		}
+		*s = status
+		return nil
+	}
+
+	var numeric int
+	if err := json.Unmarshal(data, &numeric); err != nil {
+		return fmt.Errorf("job status must be a string or number: %s", string(data))
+	}
+	if !JobStatus(numeric).IsValid() {
+		return fmt.Errorf("invalid job status: %d", numeric)
+	}
+	*s = JobStatus(numeric)
+	return nil
+}
+
 func InitPostgres(db *gorm.DB) {
-	db.AutoMigrate(&Job{})
+	db.AutoMigrate(&Job{}, &JobRevision{})
+	if err := MigrateJobSearch(db); err != nil {
+		log.Printf("Failed to set up job search index: %v", err)
+	}
+}
+
+// MigrateJobSearch creates the full-text search column and index for jobs
+func MigrateJobSearch(db *gorm.DB) error {
+	for _, statement := range jobSearchMigrations {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 var PostgresDB *gorm.DB
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	DefaultBoardCardLimit = 10
+	MaxBoardCardLimit     = 50
+)
+
+// ApplicationCard is the summary of an application shown on a job's board
+type ApplicationCard struct {
+	ApplicationID string    `bson:"application_id" json:"applicationId"`
+	CandidateID   NumericID `bson:"candidate_id" json:"candidateId"`
+	Email         string    `bson:"email" json:"email"`
+	AppliedAt     time.Time `bson:"applied_at" json:"appliedAt"`
+	LastUpdated   time.Time `bson:"last_updated" json:"lastUpdated"`
+	Tags          []string  `bson:"tags,omitempty" json:"tags,omitempty"`
+	Rating        *float64  `bson:"rating,omitempty" json:"rating,omitempty"`
+}
+
+// BoardColumn is one stage of a job's board: how many applications are in it and the
+// newest of them. NextCursor continues the column through the application listing
+// with stage set to the column's stage and the default sort.
+type BoardColumn struct {
+	Stage      string            `bson:"_id" json:"stage"`
+	Terminal   bool              `bson:"-" json:"terminal"`
+	Count      int64             `bson:"count" json:"count"`
+	Cards      []ApplicationCard `bson:"cards" json:"cards"`
+	NextCursor string            `bson:"-" json:"nextCursor,omitempty"`
+}
+
+// JobBoard groups a job's applications by stage in pipeline order
+type JobBoard struct {
+	JobID    uint          `json:"jobId"`
+	Pipeline string        `json:"pipeline"`
+	Total    int64         `json:"total"`
+	Columns  []BoardColumn `json:"columns"`
+}
+
+// NewJobBoard lays out columns in the order of the pipeline's stages, with empty columns
+// for stages no application is in. Stages that are not part of the pipeline, such as
+// Job Closed, follow in name order. Each column holds up to limit+1 cards; the extra
+// one only signals that the column continues.
+func NewJobBoard(jobID uint, pipeline Pipeline, columns []BoardColumn, limit int) *JobBoard {
+	byStage := make(map[string]BoardColumn, len(columns))
+	for _, column := range columns {
+		byStage[column.Stage] = column
+	}
+
+	board := &JobBoard{JobID: jobID, Pipeline: pipeline.Name, Columns: make([]BoardColumn, 0, len(pipeline.Stages))}
+	for _, stage := range pipeline.Stages {
+		column, ok := byStage[stage.Name]
+		if !ok {
+			column = BoardColumn{Stage: stage.Name}
+		}
+		delete(byStage, stage.Name)
+		board.Columns = append(board.Columns, column)
+	}
+	extra := make([]BoardColumn, 0, len(byStage))
+	for _, column := range byStage {
+		extra = append(extra, column)
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].Stage < extra[j].Stage })
+	board.Columns = append(board.Columns, extra...)
+
+	cursorOpts := ApplicationQueryOptions{SortBy: "appliedAt", SortDesc: true}
+	for i := range board.Columns {
+		column := &board.Columns[i]
+		column.Terminal = pipeline.IsTerminal(column.Stage)
+		if column.Cards == nil {
+			column.Cards = make([]ApplicationCard, 0)
+		}
+		if len(column.Cards) > limit {
+			column.Cards = column.Cards[:limit]
+			last := column.Cards[len(column.Cards)-1]
+			column.NextCursor = EncodeApplicationCursor(Application{ApplicationID: last.ApplicationID, AppliedAt: last.AppliedAt}, cursorOpts)
+		}
+		board.Total += column.Count
+	}
+	return board
+}
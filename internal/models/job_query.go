@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+const (
+	DefaultJobPageLimit = 20
+	MaxJobPageLimit     = 100
+)
+
+// JobSortFields maps the sort keys accepted by GET /jobs to their columns
+var JobSortFields = map[string]string{
+	"postedDate": "posted_date",
+	"createdAt":  "created_at",
+	"title":      "title",
+	"company":    "company",
+	"location":   "location",
+}
+
+// JobQueryOptions holds paging, filters and sorting for job listings
+type JobQueryOptions struct {
+	Page   int
+	Limit  int
+	Status *JobStatus
+	// Statuses limits the jobs to these statuses when Status is not set
+	Statuses     []JobStatus
+	Location     string
+	Company      string
+	CompanyID    uint
+	RecruiterID  uint
+	PipelineID   string
+	PostedAfter  *time.Time
+	PostedBefore *time.Time
+	SortBy       string
+	SortDesc     bool
+}
+
+// Normalize fills in defaults for paging and sorting
+func (o *JobQueryOptions) Normalize() {
+	if o.Page < 1 {
+		o.Page = 1
+	}
+	if o.Limit < 1 {
+		o.Limit = DefaultJobPageLimit
+	}
+	if o.Limit > MaxJobPageLimit {
+		o.Limit = MaxJobPageLimit
+	}
+	if o.SortBy == "" {
+		o.SortBy = "postedDate"
+		o.SortDesc = true
+	}
+}
+
+func (o JobQueryOptions) Offset() int {
+	return (o.Page - 1) * o.Limit
+}
+
+// JobPage is the paging envelope returned by job listings
+type JobPage struct {
+	Items      []Job `json:"items"`
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"totalPages"`
+}
+
+func NewJobPage(jobs []Job, total int64, opts JobQueryOptions) *JobPage {
+	totalPages := 0
+	if opts.Limit > 0 {
+		totalPages = int((total + int64(opts.Limit) - 1) / int64(opts.Limit))
+	}
+	return &JobPage{
+		Items:      jobs,
+		Page:       opts.Page,
+		Limit:      opts.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// JobSearchResult is a job matched by full-text search with its relevance and an
+// HTML-escaped snippet whose matches are wrapped in <mark>
+type JobSearchResult struct {
+	Job     `gorm:"embedded"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// JobSearchPage is the paging envelope returned by job search
+type JobSearchPage struct {
+	Query      string            `json:"query"`
+	Items      []JobSearchResult `json:"items"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	Total      int64             `json:"total"`
+	TotalPages int               `json:"totalPages"`
+}
+
+func NewJobSearchPage(query string, results []JobSearchResult, total int64, opts JobQueryOptions) *JobSearchPage {
+	page := NewJobPage(nil, total, opts)
+	return &JobSearchPage{
+		Query:      query,
+		Items:      results,
+		Page:       page.Page,
+		Limit:      page.Limit,
+		Total:      page.Total,
+		TotalPages: page.TotalPages,
+	}
+}
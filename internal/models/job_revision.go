@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobRevision is a snapshot of a job taken each time it changes.
+// Revision matches the job's version after the change.
+type JobRevision struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	JobID     uint            `gorm:"not null;uniqueIndex:idx_job_revisions_job_revision" json:"jobId"`
+	Revision  uint            `gorm:"not null;uniqueIndex:idx_job_revisions_job_revision" json:"revision"`
+	EditorID  uint            `json:"editorId"`
+	Snapshot  json.RawMessage `gorm:"type:jsonb;not null" json:"snapshot"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// JobFieldChange is one field that differs between two job revisions.
+// Old is omitted for fields added by the revision and New for fields it removed.
+type JobFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+// JobRevisionDiff lists the field changes a revision made to the one before it
+type JobRevisionDiff struct {
+	JobID     uint             `json:"jobId"`
+	From      uint             `json:"from"`
+	To        uint             `json:"to"`
+	EditorID  uint             `json:"editorId"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Changes   []JobFieldChange `json:"changes"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Note visibilities: private notes are only shown to their author, team notes to
+// everyone at the company that owns the job
+const (
+	NoteVisibilityPrivate = "private"
+	NoteVisibilityTeam    = "team"
+)
+
+const MaxNoteLength = 10000
+
+// mentionPattern matches @<user ID> at the start of the body or after a character that
+// cannot be part of an email address
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.@])@(\d+)\b`)
+
+// Note is a comment the hiring team leaves on an application
+type Note struct {
+	ID            string    `bson:"note_id" json:"id"`
+	ApplicationID string    `bson:"application_id" json:"applicationId"`
+	JobID         uint      `bson:"job_id" json:"jobId"`
+	CompanyID     uint      `bson:"company_id" json:"companyId"`
+	AuthorID      uint      `bson:"author_id" json:"authorId"`
+	Body          string    `bson:"body" json:"body"`
+	Visibility    string    `bson:"visibility" json:"visibility"`
+	Mentions      []uint    `bson:"mentions,omitempty" json:"mentions,omitempty"`
+	CreatedAt     time.Time `bson:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updatedAt"`
+}
+
+// Validate checks the body and visibility of a note, defaulting to team visibility, and
+// collects the users its body mentions. Private notes cannot mention anyone since the
+// mentioned users would not be able to read them.
+func (n *Note) Validate() error {
+	n.Body = strings.TrimSpace(n.Body)
+	if n.Body == "" {
+		return &ValidationError{Message: "body is required"}
+	}
+	if len(n.Body) > MaxNoteLength {
+		return &ValidationError{Message: fmt.Sprintf("body cannot be longer than %d characters", MaxNoteLength)}
+	}
+
+	if n.Visibility == "" {
+		n.Visibility = NoteVisibilityTeam
+	}
+	if n.Visibility != NoteVisibilityPrivate && n.Visibility != NoteVisibilityTeam {
+		return &ValidationError{Message: "visibility must be private or team"}
+	}
+
+	n.Mentions = ParseMentions(n.Body, n.AuthorID)
+	if n.Visibility == NoteVisibilityPrivate && len(n.Mentions) > 0 {
+		return &ValidationError{Message: "private notes cannot mention other users"}
+	}
+	return nil
+}
+
+// VisibleTo reports whether userID may read the note
+func (n *Note) VisibleTo(userID uint) bool {
+	return n.Visibility == NoteVisibilityTeam || n.AuthorID == userID
+}
+
+// ParseMentions returns the user IDs mentioned as @<id> in body, once each and in the
+// order they first appear, leaving out the author
+func ParseMentions(body string, authorID uint) []uint {
+	var mentions []uint
+	seen := make(map[uint]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		id, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || id == 0 || uint(id) == authorID || seen[uint(id)] {
+			continue
+		}
+		seen[uint(id)] = true
+		mentions = append(mentions, uint(id))
+	}
+	return mentions
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Offer statuses. Drafts are submitted for approval, approved offers are extended to
+// the candidate, and extended offers end accepted, declined or expired.
+const (
+	OfferStatusDraft           = "draft"
+	OfferStatusPendingApproval = "pending_approval"
+	OfferStatusExtended        = "extended"
+	OfferStatusAccepted        = "accepted"
+	OfferStatusDeclined        = "declined"
+	OfferStatusExpired         = "expired"
+)
+
+// offerTransitions lists the statuses an offer in each status can move to
+var offerTransitions = map[string][]string{
+	OfferStatusDraft:           {OfferStatusPendingApproval},
+	OfferStatusPendingApproval: {OfferStatusDraft, OfferStatusExtended},
+	OfferStatusExtended:        {OfferStatusAccepted, OfferStatusDeclined, OfferStatusExpired},
+}
+
+// OfferStatusChange is an entry in an offer's status history
+type OfferStatusChange struct {
+	From      string    `bson:"from,omitempty" json:"from,omitempty"`
+	To        string    `bson:"to" json:"to"`
+	ChangedBy uint      `bson:"changed_by" json:"changedBy"`
+	ChangedAt time.Time `bson:"changed_at" json:"changedAt"`
+	Reason    string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// Offer is a job offer made to the candidate of an application. Salary is the yearly
+// amount in Currency, an ISO 4217 code.
+type Offer struct {
+	ID            string              `bson:"offer_id" json:"id"`
+	ApplicationID string              `bson:"application_id" json:"applicationId"`
+	JobID         uint                `bson:"job_id" json:"jobId"`
+	CompanyID     uint                `bson:"company_id" json:"companyId"`
+	CandidateID   uint                `bson:"candidate_id" json:"candidateId"`
+	Salary        float64             `bson:"salary" json:"salary"`
+	Currency      string              `bson:"currency" json:"currency"`
+	StartDate     time.Time           `bson:"start_date" json:"startDate"`
+	ExpiresAt     time.Time           `bson:"expires_at" json:"expiresAt"`
+	Status        string              `bson:"status" json:"status"`
+	History       []OfferStatusChange `bson:"history" json:"history"`
+	CreatedBy     uint                `bson:"created_by" json:"createdBy"`
+	CreatedAt     time.Time           `bson:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time           `bson:"updated_at" json:"updatedAt"`
+}
+
+// Validate checks the terms of an offer. The offer must expire after now and before
+// the candidate would start.
+func (o *Offer) Validate(now time.Time) error {
+	o.Currency = strings.ToUpper(strings.TrimSpace(o.Currency))
+
+	if o.Salary <= 0 {
+		return &ValidationError{Message: "salary must be positive"}
+	}
+	if len(o.Currency) != 3 || strings.Trim(o.Currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return &ValidationError{Message: "currency must be a three-letter ISO 4217 code"}
+	}
+	if o.StartDate.IsZero() || o.ExpiresAt.IsZero() {
+		return &ValidationError{Message: "startDate and expiresAt are required"}
+	}
+	o.StartDate = o.StartDate.UTC()
+	o.ExpiresAt = o.ExpiresAt.UTC()
+	if !o.ExpiresAt.After(now) {
+		return &ValidationError{Message: "expiresAt must be in the future"}
+	}
+	if o.StartDate.Before(o.ExpiresAt) {
+		return &ValidationError{Message: "startDate cannot be before expiresAt"}
+	}
+	return nil
+}
+
+// IsOpen reports whether the offer is still in progress, so no other offer may be made
+// for its application
+func (o *Offer) IsOpen() bool {
+	_, ok := offerTransitions[o.Status]
+	return ok
+}
+
+// CanTransition reports whether the offer may move to status to
+func (o *Offer) CanTransition(to string) bool {
+	for _, next := range offerTransitions[o.Status] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// SetStatus moves the offer to status to, recording the change in its history
+func (o *Offer) SetStatus(to string, changedBy uint, changedAt time.Time, reason string) {
+	o.History = append(o.History, OfferStatusChange{
+		From:      o.Status,
+		To:        to,
+		ChangedBy: changedBy,
+		ChangedAt: changedAt,
+		Reason:    reason,
+	})
+	o.Status = to
+	o.UpdatedAt = changedAt
+}
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Application stages used by the default pipeline
+const (
+	StageApplied   = "Applied"
+	StageScreening = "Screening"
+	StageInterview = "Interview"
+	StageOffer     = "Offer"
+	StageHired     = "Hired"
+	StageRejected  = "Rejected"
+	StageWithdrawn = "Withdrawn"
+	// StageJobClosed is set on applications whose job was closed or deleted. Like
+	// StageWithdrawn it is terminal in every pipeline, whether or not the pipeline lists it.
+	StageJobClosed = "Job Closed"
+)
+
+// Stage kinds tell the service what a stage means when a pipeline names it differently
+const (
+	StageKindHired     = "hired"
+	StageKindRejected  = "rejected"
+	StageKindWithdrawn = "withdrawn"
+	// StageKindInterview marks an open stage in which interviews can be scheduled
+	StageKindInterview = "interview"
+)
+
+// terminalStageKinds are the kinds only a terminal stage may have
+var terminalStageKinds = []string{StageKindHired, StageKindRejected, StageKindWithdrawn}
+
+// TerminalStages are the stages an application does not move on from in the default pipeline
+var TerminalStages = []string{StageHired, StageRejected, StageWithdrawn, StageJobClosed}
+
+// PipelineStage is a stage an application can be in and the stages it may move to next.
+// Terminal stages have no transitions.
+type PipelineStage struct {
+	Name        string   `bson:"name" json:"name"`
+	Terminal    bool     `bson:"terminal" json:"terminal"`
+	Kind        string   `bson:"kind,omitempty" json:"kind,omitempty"`
+	Transitions []string `bson:"transitions" json:"transitions"`
+}
+
+// Pipeline is the ordered set of stages applications move through. New applications
+// start in the first stage. Companies define their own pipelines in the pipelines
+// collection; jobs without one use DefaultPipeline.
+type Pipeline struct {
+	ID        string          `bson:"pipeline_id" json:"id"`
+	CompanyID uint            `bson:"company_id" json:"companyId"`
+	Name      string          `bson:"name" json:"name"`
+	Stages    []PipelineStage `bson:"stages" json:"stages"`
+	CreatedBy uint            `bson:"created_by,omitempty" json:"createdBy,omitempty"`
+	CreatedAt time.Time       `bson:"created_at,omitempty" json:"createdAt,omitempty"`
+	UpdatedAt time.Time       `bson:"updated_at,omitempty" json:"updatedAt,omitempty"`
+}
+
+// DefaultPipeline is Applied → Screening → Interview → Offer → Hired. An application
+// can be rejected or withdrawn from any open stage.
+var DefaultPipeline = Pipeline{
+	Name: "default",
+	Stages: []PipelineStage{
+		{Name: StageApplied, Transitions: []string{StageScreening, StageRejected, StageWithdrawn}},
+		{Name: StageScreening, Transitions: []string{StageInterview, StageRejected, StageWithdrawn}},
+		{Name: StageInterview, Transitions: []string{StageOffer, StageRejected, StageWithdrawn}},
+		{Name: StageOffer, Transitions: []string{StageHired, StageRejected, StageWithdrawn}},
+		{Name: StageHired, Terminal: true, Kind: StageKindHired},
+		{Name: StageRejected, Terminal: true, Kind: StageKindRejected},
+		{Name: StageWithdrawn, Terminal: true, Kind: StageKindWithdrawn},
+		{Name: StageJobClosed, Terminal: true},
+	},
+}
+
+// Stage looks up a stage by name, ignoring case so older lowercase stages still match
+func (p Pipeline) Stage(name string) (PipelineStage, bool) {
+	for _, stage := range p.Stages {
+		if strings.EqualFold(stage.Name, strings.TrimSpace(name)) {
+			return stage, true
+		}
+	}
+	return PipelineStage{}, false
+}
+
+// InitialStage is the stage new applications start in
+func (p Pipeline) InitialStage() string {
+	if len(p.Stages) == 0 {
+		return StageApplied
+	}
+	return p.Stages[0].Name
+}
+
+// TerminalStages lists the pipeline's terminal stages, always including StageJobClosed
+// and StageWithdrawn
+func (p Pipeline) TerminalStages() []string {
+	terminal := []string{StageJobClosed, StageWithdrawn}
+	for _, stage := range p.Stages {
+		if stage.Terminal && !strings.EqualFold(stage.Name, StageJobClosed) && !strings.EqualFold(stage.Name, StageWithdrawn) {
+			terminal = append(terminal, stage.Name)
+		}
+	}
+	return terminal
+}
+
+// IsTerminal reports whether an application in stage name has left the pipeline
+func (p Pipeline) IsTerminal(name string) bool {
+	for _, terminal := range p.TerminalStages() {
+		if strings.EqualFold(terminal, strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithdrawnStage is the stage candidates who withdraw are moved to: the pipeline's
+// first stage of kind withdrawn, or StageWithdrawn when it has none
+func (p Pipeline) WithdrawnStage() string {
+	for _, stage := range p.Stages {
+		if stage.Kind == StageKindWithdrawn {
+			return stage.Name
+		}
+	}
+	return StageWithdrawn
+}
+
+// HiredStage is the stage candidates who accept an offer are moved to: the pipeline's
+// first stage of kind hired, or its StageHired stage. ok is false when it has neither.
+func (p Pipeline) HiredStage() (name string, ok bool) {
+	for _, stage := range p.Stages {
+		if stage.Kind == StageKindHired {
+			return stage.Name, true
+		}
+	}
+	if stage, ok := p.Stage(StageHired); ok {
+		return stage.Name, true
+	}
+	return "", false
+}
+
+// IsInterviewStage reports whether stage name is of kind interview or is the pipeline's
+// StageInterview stage
+func (p Pipeline) IsInterviewStage(name string) bool {
+	stage, ok := p.Stage(name)
+	if !ok {
+		return false
+	}
+	return stage.Kind == StageKindInterview || strings.EqualFold(stage.Name, StageInterview)
+}
+
+// RejectedStage is the stage rejected applications are moved to: the pipeline's first
+// stage of kind rejected, or its StageRejected stage. ok is false when it has neither.
+func (p Pipeline) RejectedStage() (name string, ok bool) {
+	for _, stage := range p.Stages {
+		if stage.Kind == StageKindRejected {
+			return stage.Name, true
+		}
+	}
+	if stage, ok := p.Stage(StageRejected); ok {
+		return stage.Name, true
+	}
+	return "", false
+}
+
+// CanTransition reports whether an application in stage from may move to stage to
+func (p Pipeline) CanTransition(from, to string) bool {
+	stage, ok := p.Stage(from)
+	if !ok {
+		return false
+	}
+	for _, next := range stage.Transitions {
+		if strings.EqualFold(next, strings.TrimSpace(to)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that a pipeline has a name and that its stages form a usable graph:
+// stage names are unique, transitions point at stages of the pipeline, terminal stages
+// have no transitions and every other stage has at least one
+func (p *Pipeline) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return &ValidationError{Message: "pipeline name is required"}
+	}
+	if len(p.Stages) == 0 {
+		return &ValidationError{Message: "pipeline needs at least one stage"}
+	}
+
+	seen := make(map[string]bool, len(p.Stages))
+	for i := range p.Stages {
+		stage := &p.Stages[i]
+		stage.Name = strings.TrimSpace(stage.Name)
+		if stage.Name == "" {
+			return &ValidationError{Message: fmt.Sprintf("stage %d has no name", i+1)}
+		}
+		key := strings.ToLower(stage.Name)
+		if seen[key] {
+			return &ValidationError{Message: fmt.Sprintf("stage %q is listed more than once", stage.Name)}
+		}
+		seen[key] = true
+	}
+
+	if p.Stages[0].Terminal {
+		return &ValidationError{Message: "the first stage cannot be terminal"}
+	}
+	for _, stage := range p.Stages {
+		if stage.Kind != "" && stage.Kind != StageKindInterview && !containsStageKind(stage.Kind) {
+			return &ValidationError{Message: fmt.Sprintf("stage %q has unknown kind %q", stage.Name, stage.Kind)}
+		}
+		if stage.Kind != "" && stage.Kind != StageKindInterview && !stage.Terminal {
+			return &ValidationError{Message: fmt.Sprintf("stage %q must be terminal to have kind %q", stage.Name, stage.Kind)}
+		}
+		if stage.Kind == StageKindInterview && stage.Terminal {
+			return &ValidationError{Message: fmt.Sprintf("terminal stage %q cannot have kind %q", stage.Name, stage.Kind)}
+		}
+		if stage.Terminal && len(stage.Transitions) > 0 {
+			return &ValidationError{Message: fmt.Sprintf("terminal stage %q cannot have transitions", stage.Name)}
+		}
+		if !stage.Terminal && len(stage.Transitions) == 0 {
+			return &ValidationError{Message: fmt.Sprintf("stage %q needs at least one transition or must be terminal", stage.Name)}
+		}
+		for _, next := range stage.Transitions {
+			if _, ok := p.Stage(next); !ok {
+				return &ValidationError{Message: fmt.Sprintf("stage %q moves to unknown stage %q", stage.Name, next)}
+			}
+		}
+	}
+	return nil
+}
+
+func containsStageKind(kind string) bool {
+	for _, known := range terminalStageKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxResumeSize is the largest resume file that can be uploaded, in bytes
+const MaxResumeSize = 5 << 20
+
+// Resume content types accepted for upload
+const (
+	ResumeTypePDF  = "application/pdf"
+	ResumeTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+// resumeExtensions maps each accepted content type to the file extension it is stored with
+var resumeExtensions = map[string]string{
+	ResumeTypePDF:  ".pdf",
+	ResumeTypeDOCX: ".docx",
+}
+
+// ResumeFile describes a resume uploaded to the service. Key locates the file in the
+// blob store and is derived from its SHA-256, so identical files are stored once.
+type ResumeFile struct {
+	Key         string    `bson:"key" json:"-"`
+	FileName    string    `bson:"file_name" json:"fileName"`
+	ContentType string    `bson:"content_type" json:"contentType"`
+	Size        int64     `bson:"size" json:"size"`
+	SHA256      string    `bson:"sha256" json:"sha256"`
+	UploadedAt  time.Time `bson:"uploaded_at" json:"uploadedAt"`
+}
+
+// ResumeKey is the blob store key of a resume with the given SHA-256 and content type
+func ResumeKey(sha256 string, contentType string) string {
+	return fmt.Sprintf("resumes/%s/%s%s", sha256[:2], sha256, resumeExtensions[contentType])
+}
+
+// DetectResumeType works out whether data is a PDF or DOCX file from its contents,
+// checking that the file name's extension agrees
+func DetectResumeType(fileName string, data []byte) (string, error) {
+	var contentType string
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		contentType = ResumeTypePDF
+	case isDOCX(data):
+		contentType = ResumeTypeDOCX
+	default:
+		return "", &ValidationError{Message: "resume must be a PDF or DOCX file"}
+	}
+	if ext := strings.ToLower(filepath.Ext(fileName)); ext != resumeExtensions[contentType] {
+		return "", &ValidationError{Message: fmt.Sprintf("resume content does not match its %q extension", ext)}
+	}
+	return contentType, nil
+}
+
+// isDOCX reports whether data is a zip archive holding a Word document
+func isDOCX(data []byte) bool {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return false
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			return true
+		}
+	}
+	return false
+}
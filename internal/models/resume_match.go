@@ -0,0 +1,179 @@
+package models
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ResumeMatch is how well an application covers its job's comma-separated Skills and
+// Experience. Score is the percentage of those requirements found, from 0 to 100.
+type ResumeMatch struct {
+	Score             float64  `bson:"score" json:"score"`
+	MatchedSkills     []string `bson:"matched_skills" json:"matchedSkills"`
+	MissingSkills     []string `bson:"missing_skills" json:"missingSkills"`
+	MatchedExperience []string `bson:"matched_experience,omitempty" json:"matchedExperience,omitempty"`
+	MissingExperience []string `bson:"missing_experience,omitempty" json:"missingExperience,omitempty"`
+	// ResumeSHA256 identifies the uploaded resume that was scored; empty when the
+	// application had none and only its listed skills were considered
+	ResumeSHA256 string    `bson:"resume_sha256,omitempty" json:"resumeSha256,omitempty"`
+	ScoredAt     time.Time `bson:"scored_at" json:"scoredAt"`
+}
+
+// yearsPattern finds requirements and claims such as "5+ yrs" or "3 years"
+var yearsPattern = regexp.MustCompile(`(\d+)\s*\+?\s*(?:yrs?|years?)\b`)
+
+// experienceFillers are words in an Experience entry that say nothing about the experience itself
+var experienceFillers = map[string]bool{
+	"of": true, "in": true, "and": true, "with": true, "the": true, "a": true, "an": true,
+	"experience": true, "yr": true, "yrs": true, "year": true, "years": true,
+}
+
+// RequirementList splits one of a job's comma-separated requirement fields, dropping
+// blanks and repeats
+func RequirementList(field string) []string {
+	requirements := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, requirement := range strings.Split(field, ",") {
+		requirement = strings.TrimSpace(requirement)
+		key := normalizeMatchText(requirement)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		requirements = append(requirements, requirement)
+	}
+	return requirements
+}
+
+// MatchResume scores an application against the job's skills and experience.
+// resumeText is the text of its uploaded resume, if any; the skills the candidate
+// listed when applying count as well. It returns nil when the job lists no requirements.
+func MatchResume(job *Job, app *Application, resumeText string) *ResumeMatch {
+	skills := RequirementList(job.Skills)
+	experience := RequirementList(job.Experience)
+	if len(skills)+len(experience) == 0 {
+		return nil
+	}
+
+	text := normalizeMatchText(resumeText + "\n" + strings.Join(app.Skills, "\n"))
+	years := app.YearsOfExperience
+	if years == 0 {
+		years = claimedYears(text)
+	}
+
+	match := &ResumeMatch{
+		MatchedSkills: make([]string, 0),
+		MissingSkills: make([]string, 0),
+		ScoredAt:      time.Now(),
+	}
+	if app.Resume != nil {
+		match.ResumeSHA256 = app.Resume.SHA256
+	}
+	for _, skill := range skills {
+		if containsTerm(text, normalizeMatchText(skill)) {
+			match.MatchedSkills = append(match.MatchedSkills, skill)
+		} else {
+			match.MissingSkills = append(match.MissingSkills, skill)
+		}
+	}
+	for _, entry := range experience {
+		if matchesExperience(text, years, entry) {
+			match.MatchedExperience = append(match.MatchedExperience, entry)
+		} else {
+			match.MissingExperience = append(match.MissingExperience, entry)
+		}
+	}
+
+	matched := len(match.MatchedSkills) + len(match.MatchedExperience)
+	score := 100 * float64(matched) / float64(len(skills)+len(experience))
+	match.Score = math.Round(score*10) / 10
+	return match
+}
+
+// matchesExperience reports whether an Experience entry such as "5+ yrs React
+// development" is covered: any years it asks for are met, and at least half of its
+// other words appear in the text
+func matchesExperience(text string, years float64, entry string) bool {
+	entry = normalizeMatchText(entry)
+	if required := yearsPattern.FindStringSubmatch(entry); required != nil {
+		if needed, _ := strconv.ParseFloat(required[1], 64); years < needed {
+			return false
+		}
+		entry = yearsPattern.ReplaceAllString(entry, " ")
+	}
+
+	keywords, found := 0, 0
+	for _, word := range matchWords(entry) {
+		if experienceFillers[word] {
+			continue
+		}
+		keywords++
+		if containsTerm(text, word) {
+			found++
+		}
+	}
+	return keywords == 0 || 2*found >= keywords
+}
+
+// claimedYears is the most years of experience the text mentions, such as "7 years"
+func claimedYears(text string) float64 {
+	most := 0.0
+	for _, claim := range yearsPattern.FindAllStringSubmatch(text, -1) {
+		// numbers this large are dates or durations of something else
+		if years, err := strconv.ParseFloat(claim[1], 64); err == nil && years <= 50 && years > most {
+			most = years
+		}
+	}
+	return most
+}
+
+// normalizeMatchText lowercases text and collapses its whitespace, so terms match
+// regardless of case and line breaks
+func normalizeMatchText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// matchWords splits normalized text into words, keeping the punctuation that is part
+// of technology names such as "node.js", "c++" and "c#"
+func matchWords(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !isMatchWordRune(r)
+	})
+	for i, word := range words {
+		words[i] = strings.TrimRight(word, ".")
+	}
+	return words
+}
+
+func isMatchWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' || r == '.'
+}
+
+// containsTerm reports whether the normalized text contains term as whole words. A
+// term is not found inside a longer word, so "java" does not match "javascript".
+func containsTerm(text string, term string) bool {
+	if term == "" {
+		return false
+	}
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(term)
+		if (start == 0 || !isTermRune(text[start-1])) && (end == len(text) || !isTermRune(text[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isTermRune reports whether a byte next to a match would make it part of a longer word.
+// A trailing "." ends a sentence rather than extending a name.
+func isTermRune(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '+' || b == '#' || b >= 0x80
+}
@@ -0,0 +1,322 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// maxResumeTextSize caps the text kept from a resume and the data decompressed to find it,
+// so a small file cannot expand into an unbounded amount of work
+const maxResumeTextSize = 1 << 20
+
+var ErrNoResumeText = errors.New("no text could be extracted from the resume")
+
+// ExtractResumeText returns the plain text of a PDF or DOCX resume. PDF text is read
+// from the page content streams, so scanned resumes and fonts with custom encodings
+// yield little or no text.
+func ExtractResumeText(contentType string, data []byte) (string, error) {
+	var text string
+	var err error
+	switch contentType {
+	case ResumeTypePDF:
+		text = extractPDFText(data)
+	case ResumeTypeDOCX:
+		text, err = extractDOCXText(data)
+	default:
+		return "", fmt.Errorf("cannot extract text from %s", contentType)
+	}
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", ErrNoResumeText
+	}
+	return text, nil
+}
+
+// extractDOCXText collects the text runs of word/document.xml, one line per paragraph
+func extractDOCXText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx: %v", err)
+	}
+	document, err := archive.Open("word/document.xml")
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx document: %v", err)
+	}
+	defer document.Close()
+
+	var text strings.Builder
+	decoder := xml.NewDecoder(io.LimitReader(document, 8*maxResumeTextSize))
+	inText := false
+	for text.Len() < maxResumeTextSize {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read docx document: %v", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteByte('\t')
+			case "br", "cr":
+				text.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(token)
+			}
+		}
+	}
+	return text.String(), nil
+}
+
+// extractPDFText reads the text shown by the content streams of a PDF. Streams that
+// cannot hold page text, such as images and fonts, are skipped.
+func extractPDFText(data []byte) string {
+	var text strings.Builder
+	budget := 8 * maxResumeTextSize
+	for rest := data; budget > 0 && text.Len() < maxResumeTextSize; {
+		dict, content, next, ok := nextPDFStream(rest)
+		if !ok {
+			break
+		}
+		rest = next
+		if !isPDFContentStream(dict) {
+			continue
+		}
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			reader, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			// a stream cut short still gives up the text before the damage
+			content, _ = io.ReadAll(io.LimitReader(reader, int64(budget)))
+			reader.Close()
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			continue
+		}
+		budget -= len(content)
+		writePDFContentText(&text, content)
+	}
+	return text.String()
+}
+
+// nextPDFStream finds the next stream in data, returning its dictionary, its raw
+// content and the data that follows it
+func nextPDFStream(data []byte) (dict []byte, content []byte, rest []byte, ok bool) {
+	start := bytes.Index(data, []byte("stream"))
+	for start >= 0 && (start == 0 || !bytes.HasSuffix(bytes.TrimRight(data[:start], " \r\n"), []byte(">>"))) {
+		// "stream" inside some other token, such as "endstream"
+		next := bytes.Index(data[start+len("stream"):], []byte("stream"))
+		if next < 0 {
+			return nil, nil, nil, false
+		}
+		start += len("stream") + next
+	}
+	if start < 0 {
+		return nil, nil, nil, false
+	}
+	dictStart := bytes.LastIndex(data[:start], []byte("obj"))
+	if dictStart < 0 {
+		dictStart = 0
+	}
+	dict = data[dictStart:start]
+
+	body := data[start+len("stream"):]
+	body = bytes.TrimPrefix(body, []byte("\r"))
+	body = bytes.TrimPrefix(body, []byte("\n"))
+	end := bytes.Index(body, []byte("endstream"))
+	if end < 0 {
+		return nil, nil, nil, false
+	}
+	return dict, body[:end], body[end+len("endstream"):], true
+}
+
+// isPDFContentStream reports whether a stream dictionary can belong to a page's content
+func isPDFContentStream(dict []byte) bool {
+	for _, marker := range []string{"/Image", "/Length1", "/Length2", "/FontFile", "/XRef", "/ObjStm", "/Metadata", "/ICCBased", "/CMap"} {
+		if bytes.Contains(dict, []byte(marker)) {
+			return false
+		}
+	}
+	return true
+}
+
+// writePDFContentText interprets the text operators of a content stream: strings shown
+// with Tj, TJ, ' and ", with line breaks where the text moves to a new line
+func writePDFContentText(text *strings.Builder, content []byte) {
+	var operands []string
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, next := readPDFLiteralString(content, i)
+			operands = append(operands, s)
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			s, next := readPDFHexString(content, i)
+			operands = append(operands, s)
+			i = next
+		case c == '[' || c == ']':
+			i++
+		case isPDFDelimiter(c):
+			i++
+		default:
+			start := i
+			for i < len(content) && !isPDFDelimiter(content[i]) {
+				i++
+			}
+			word := string(content[start:i])
+			switch word {
+			case "Tj", "TJ":
+				text.WriteString(strings.Join(operands, ""))
+			case "'", "\"":
+				text.WriteByte('\n')
+				text.WriteString(strings.Join(operands, ""))
+			case "Td", "TD", "T*", "ET":
+				text.WriteByte('\n')
+			case "Tm":
+				text.WriteByte(' ')
+			}
+			if word != "" && (word[0] < '0' || word[0] > '9') && word[0] != '-' && word[0] != '.' {
+				operands = operands[:0]
+			} else if isPDFKerning(word) {
+				// wide gaps inside a TJ array separate words
+				operands = append(operands, " ")
+			}
+		}
+	}
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\x00()<>[]{}/%", c) >= 0
+}
+
+// isPDFKerning reports whether a number inside a TJ array moves the text far enough to be a space
+func isPDFKerning(word string) bool {
+	var value float64
+	_, err := fmt.Sscanf(word, "%g", &value)
+	return err == nil && value < -200
+}
+
+// readPDFLiteralString decodes the (...) string starting at data[start], returning it
+// and the index just past it
+func readPDFLiteralString(data []byte, start int) (string, int) {
+	var s []byte
+	depth := 0
+	i := start
+	for ; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\\' && i+1 < len(data):
+			i++
+			switch e := data[i]; e {
+			case 'n', 'r':
+				s = append(s, '\n')
+			case 't':
+				s = append(s, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// a line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					value := 0
+					for n := 0; n < 3 && i < len(data) && data[i] >= '0' && data[i] <= '7'; n++ {
+						value = value*8 + int(data[i]-'0')
+						i++
+					}
+					i--
+					s = append(s, byte(value))
+				} else {
+					s = append(s, e)
+				}
+			}
+		case c == '(':
+			if depth > 0 {
+				s = append(s, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return pdfBytesToText(s), i + 1
+			}
+			s = append(s, c)
+		default:
+			s = append(s, c)
+		}
+	}
+	return pdfBytesToText(s), i
+}
+
+// readPDFHexString decodes the <...> string starting at data[start], returning it and
+// the index just past it
+func readPDFHexString(data []byte, start int) (string, int) {
+	end := bytes.IndexByte(data[start:], '>')
+	if end < 0 {
+		return "", len(data)
+	}
+	digits := bytes.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, data[start+1:start+end])
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	decoded := make([]byte, hex.DecodedLen(len(digits)))
+	if _, err := hex.Decode(decoded, digits); err != nil {
+		return "", start + end + 1
+	}
+	return pdfBytesToText(decoded), start + end + 1
+}
+
+// pdfBytesToText reads string bytes as UTF-16 when they carry its byte order mark and
+// as Latin-1 otherwise, dropping control characters
+func pdfBytesToText(s []byte) string {
+	var text strings.Builder
+	if len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff {
+		for i := 2; i+1 < len(s); i += 2 {
+			if r := rune(s[i])<<8 | rune(s[i+1]); unicode.IsPrint(r) || unicode.IsSpace(r) {
+				text.WriteRune(r)
+			}
+		}
+		return text.String()
+	}
+	for _, b := range s {
+		if r := rune(b); unicode.IsPrint(r) || unicode.IsSpace(r) {
+			text.WriteRune(r)
+		}
+	}
+	return text.String()
+}
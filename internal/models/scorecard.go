@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Scorecard ratings are normalized to this scale, whatever scales the competencies use
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// RatingScale is the range of whole-number scores a competency is rated on
+type RatingScale struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// Competency is something interviewers rate candidates on. Weight sets how much it counts
+// towards the overall rating and defaults to 1.
+type Competency struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Scale       RatingScale `json:"scale"`
+	Weight      float64     `json:"weight,omitempty"`
+}
+
+// ScorecardTemplate lists the competencies interviewers rate a job's candidates on
+type ScorecardTemplate struct {
+	Competencies []Competency `json:"competencies"`
+}
+
+// Validate checks that the template has uniquely named competencies with usable scales and weights
+func (t *ScorecardTemplate) Validate() error {
+	if len(t.Competencies) == 0 {
+		return &ValidationError{Message: "scorecard needs at least one competency"}
+	}
+	seen := make(map[string]bool)
+	for i := range t.Competencies {
+		competency := &t.Competencies[i]
+		competency.Name = strings.TrimSpace(competency.Name)
+		if competency.Name == "" {
+			return &ValidationError{Message: "competency name is required"}
+		}
+		key := strings.ToLower(competency.Name)
+		if seen[key] {
+			return &ValidationError{Message: fmt.Sprintf("duplicate competency %q", competency.Name)}
+		}
+		seen[key] = true
+		if competency.Scale.Min < 0 || competency.Scale.Max <= competency.Scale.Min {
+			return &ValidationError{Message: fmt.Sprintf("competency %q needs a scale with 0 <= min < max", competency.Name)}
+		}
+		if competency.Weight < 0 {
+			return &ValidationError{Message: fmt.Sprintf("competency %q cannot have a negative weight", competency.Name)}
+		}
+	}
+	return nil
+}
+
+// Competency looks up a competency by name, ignoring case
+func (t *ScorecardTemplate) Competency(name string) (Competency, bool) {
+	for _, competency := range t.Competencies {
+		if strings.EqualFold(competency.Name, strings.TrimSpace(name)) {
+			return competency, true
+		}
+	}
+	return Competency{}, false
+}
+
+// CompetencyScore is an interviewer's score for one competency
+type CompetencyScore struct {
+	Competency string `bson:"competency" json:"competency"`
+	Score      int    `bson:"score" json:"score"`
+	Comment    string `bson:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// Scorecard is one interviewer's assessment of an application. Overall is the weighted
+// mean of the scores, normalized to MinRating..MaxRating.
+type Scorecard struct {
+	InterviewerID uint              `bson:"interviewer_id" json:"interviewerId"`
+	Scores        []CompetencyScore `bson:"scores" json:"scores"`
+	Summary       string            `bson:"summary,omitempty" json:"summary,omitempty"`
+	Overall       float64           `bson:"overall" json:"overall"`
+	SubmittedAt   time.Time         `bson:"submitted_at" json:"submittedAt"`
+}
+
+// Score checks a scorecard against the template and computes its overall rating.
+// Competencies can be left out, but each one may only be scored once.
+func (s *Scorecard) Score(template *ScorecardTemplate) error {
+	if len(s.Scores) == 0 {
+		return &ValidationError{Message: "scorecard needs at least one score"}
+	}
+
+	seen := make(map[string]bool)
+	var total, weights float64
+	for i := range s.Scores {
+		score := &s.Scores[i]
+		competency, ok := template.Competency(score.Competency)
+		if !ok {
+			return &ValidationError{Message: fmt.Sprintf("unknown competency %q", score.Competency)}
+		}
+		score.Competency = competency.Name
+		if seen[competency.Name] {
+			return &ValidationError{Message: fmt.Sprintf("competency %q is scored more than once", competency.Name)}
+		}
+		seen[competency.Name] = true
+		if score.Score < competency.Scale.Min || score.Score > competency.Scale.Max {
+			return &ValidationError{Message: fmt.Sprintf("score for %q must be between %d and %d", competency.Name, competency.Scale.Min, competency.Scale.Max)}
+		}
+
+		weight := competency.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		normalized := float64(score.Score-competency.Scale.Min) / float64(competency.Scale.Max-competency.Scale.Min)
+		total += weight * normalized
+		weights += weight
+	}
+
+	s.Overall = roundRating(MinRating + total/weights*(MaxRating-MinRating))
+	return nil
+}
+
+// ScorecardSummary is an application's rating and the scorecards it is computed from
+type ScorecardSummary struct {
+	ApplicationID string      `json:"applicationId"`
+	Rating        *float64    `json:"rating"`
+	Scorecards    []Scorecard `json:"scorecards"`
+}
+
+func NewScorecardSummary(app *Application) *ScorecardSummary {
+	scorecards := app.Scorecards
+	if scorecards == nil {
+		scorecards = make([]Scorecard, 0)
+	}
+	return &ScorecardSummary{ApplicationID: app.ApplicationID, Rating: app.Rating, Scorecards: scorecards}
+}
+
+// AverageRating is the mean overall rating of scorecards, or nil when there are none
+func AverageRating(scorecards []Scorecard) *float64 {
+	if len(scorecards) == 0 {
+		return nil
+	}
+	var total float64
+	for _, scorecard := range scorecards {
+		total += scorecard.Overall
+	}
+	rating := roundRating(total / float64(len(scorecards)))
+	return &rating
+}
+
+func roundRating(rating float64) float64 {
+	return math.Round(rating*100) / 100
+}
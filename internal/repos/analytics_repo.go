@@ -0,0 +1,144 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"jobs-svc/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AnalyticsRepo runs reporting aggregations over the applications collection
+type AnalyticsRepo struct {
+	Collection *mongo.Collection
+}
+
+// CreateIndexes creates the index company-wide reports filter on. Job reports use the
+// job_id and applied_at listing index.
+func (repo *AnalyticsRepo) CreateIndexes() error {
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "company_id", Value: 1}, {Key: "applied_at", Value: -1}},
+	}
+	_, err := repo.Collection.Indexes().CreateOne(context.TODO(), indexModel)
+	return err
+}
+
+// GetStageVisits replays the stage history of the applications matching filter. Every
+// history entry starts a visit to a stage that lasts until the next entry; the visits
+// are then grouped by stage and the stage they moved on to.
+func (repo *AnalyticsRepo) GetStageVisits(filter models.FunnelFilter) ([]models.StageVisitGroup, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: funnelMatch(filter)}},
+		// applications from before stage history was kept count as one visit to their current stage
+		{{Key: "$project", Value: bson.M{
+			"applied_at": 1,
+			"history": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$stage_history", bson.A{}}}}, 0}},
+				"$stage_history",
+				bson.A{bson.M{"to": "$status.current_stage", "changed_at": "$applied_at"}},
+			}},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"visits": bson.M{"$map": bson.M{
+				"input": bson.M{"$range": bson.A{0, bson.M{"$size": "$history"}}},
+				"as":    "i",
+				"in": bson.M{"$let": bson.M{
+					"vars": bson.M{
+						"current": bson.M{"$arrayElemAt": bson.A{"$history", "$$i"}},
+						"next":    bson.M{"$arrayElemAt": bson.A{"$history", bson.M{"$add": bson.A{"$$i", 1}}}},
+					},
+					"in": bson.M{
+						"stage":         "$$current.to",
+						"next":          bson.M{"$ifNull": bson.A{"$$next.to", nil}},
+						"duration":      bson.M{"$subtract": bson.A{"$$next.changed_at", "$$current.changed_at"}},
+						"since_applied": bson.M{"$subtract": bson.A{"$$current.changed_at", "$applied_at"}},
+					},
+				}},
+			}},
+		}}},
+		{{Key: "$unwind", Value: "$visits"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           bson.M{"stage": "$visits.stage", "next": "$visits.next"},
+			"count":         bson.M{"$sum": 1},
+			"durations":     bson.M{"$push": "$visits.duration"},
+			"since_applied": bson.M{"$push": "$visits.since_applied"},
+		}}},
+		// visits still in progress have no duration
+		{{Key: "$project", Value: bson.M{
+			"_id":           0,
+			"stage":         "$_id.stage",
+			"next":          "$_id.next",
+			"count":         1,
+			"durations":     nonNull("$durations"),
+			"since_applied": nonNull("$since_applied"),
+		}}},
+	}
+
+	cursor, err := repo.Collection.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate stage visits: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	groups := make([]models.StageVisitGroup, 0)
+	if err = cursor.All(context.TODO(), &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode stage visits: %v", err)
+	}
+	return groups, nil
+}
+
+// GetRejectionReasons counts the rejected applications matching filter by the reason
+// they were rejected with, keeping the label most recently recorded for each reason
+func (repo *AnalyticsRepo) GetRejectionReasons(filter models.FunnelFilter) ([]models.RejectionReasonCount, error) {
+	match := funnelMatch(filter)
+	match["disposition.reason_code"] = bson.M{"$exists": true}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "disposition.decided_at", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$disposition.reason_code",
+			"label": bson.M{"$last": "$disposition.reason_label"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := repo.Collection.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate rejection reasons: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	counts := make([]models.RejectionReasonCount, 0)
+	if err = cursor.All(context.TODO(), &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode rejection reasons: %v", err)
+	}
+	return counts, nil
+}
+
+func funnelMatch(filter models.FunnelFilter) bson.M {
+	match := bson.M{}
+	switch {
+	case filter.JobID != 0:
+		// a job's report goes by job_id alone: applications created before they were
+		// stamped with their job's company have no company_id
+		match["job_id"] = idFilter(filter.JobID)
+	case filter.CompanyID != 0:
+		match["company_id"] = idFilter(filter.CompanyID)
+	}
+	applied := bson.M{}
+	if filter.AppliedAfter != nil {
+		applied["$gte"] = *filter.AppliedAfter
+	}
+	if filter.AppliedBefore != nil {
+		applied["$lte"] = *filter.AppliedBefore
+	}
+	if len(applied) > 0 {
+		match["applied_at"] = applied
+	}
+	return match
+}
+
+func nonNull(array string) bson.M {
+	return bson.M{"$filter": bson.M{"input": array, "cond": bson.M{"$ne": bson.A{"$$this", nil}}}}
+}
@@ -0,0 +1,9 @@
+package repos
+
+import "jobs-svc/internal/models"
+
+type AnalyticsRepoInterface interface {
+	GetStageVisits(filter models.FunnelFilter) ([]models.StageVisitGroup, error)
+	GetRejectionReasons(filter models.FunnelFilter) ([]models.RejectionReasonCount, error)
+	CreateIndexes() error
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"jobs-svc/internal/models"
 	"log"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -18,124 +20,272 @@ type ApplicationRepo struct {
 	Collection *mongo.Collection
 }
 
+// legacyUniqueIndex is the unique candidate_id and job_id index that covered every
+// application, withdrawn or not
+const legacyUniqueIndex = "candidate_id_1_job_id_1"
+
 // CreateUniqueIndex creates a unique compound index on candidate_id and job_id
-// for checking if candidate trying to apply to same job again
+// for checking if candidate trying to apply to same job again. The index only covers
+// active applications so candidates can reapply after withdrawing.
 func (repo *ApplicationRepo) CreateUniqueIndex() error {
-	// Create a compound index
+	ctx := context.TODO()
+
+	// applications created before withdrawal existed have no active flag
+	_, err := repo.Collection.UpdateMany(ctx,
+		bson.M{"active": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"active": true}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to backfill active applications: %v", err)
+	}
+
+	if _, err := repo.Collection.Indexes().DropOne(ctx, legacyUniqueIndex); err != nil && !isIndexNotFound(err) {
+		return fmt.Errorf("failed to drop index %s: %v", legacyUniqueIndex, err)
+	}
+
 	indexModel := mongo.IndexModel{
 		Keys: bson.D{
 			{Key: "candidate_id", Value: 1},
 			{Key: "job_id", Value: 1},
 		},
-		Options: options.Index().SetUnique(true),
-	}
-
-	// Create the index
-	_, err := repo.Collection.Indexes().CreateOne(context.TODO(), indexModel)
-	if err != nil {
-		return err
+		Options: options.Index().
+			SetName("candidate_id_1_job_id_1_active").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"active": true}),
 	}
+	_, err = repo.Collection.Indexes().CreateOne(ctx, indexModel)
+	return err
+}
 
-	return nil
+// isIndexNotFound reports whether err is MongoDB's IndexNotFound error
+func isIndexNotFound(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == 27
 }
 
-func (repo *ApplicationRepo) CreateApplication(application bson.M) error {
+func (repo *ApplicationRepo) CreateApplication(application *models.Application) error {
 	filter := bson.M{
-		"candidate_id": application["candidate_id"],
-		"job_id":       application["job_id"],
+		"candidate_id": idFilter(uint(application.CandidateID)),
+		"job_id":       idFilter(uint(application.JobID)),
+		"active":       true,
 	}
 
-	var existingApp bson.M
-	err := repo.Collection.FindOne(context.TODO(), filter).Decode(&existingApp)
+	err := repo.Collection.FindOne(context.TODO(), filter).Err()
 	if err == nil {
-		return errors.New("candidate has already applied for this job")
+		return ErrDuplicateApplication
 	} else if err != mongo.ErrNoDocuments {
 		return err
 	}
 
-	if application["application_id"] == nil {
-		application["application_id"] = primitive.NewObjectID().Hex()
+	if application.ApplicationID == "" {
+		application.ApplicationID = primitive.NewObjectID().Hex()
 	}
 
 	// Validate required fields
-	if application["job_id"] == nil || application["candidate_id"] == nil {
+	if application.JobID == 0 || application.CandidateID == 0 {
 		return errors.New("JobID and CandidateID are required")
 	}
 
 	// Set status if not provided
-	if application["status"] == nil {
-		application["status"] = bson.M{
-			"current_stage": "applied",
-			"last_updated":  time.Now(),
-		}
-	} else {
-		// Update last_updated if status exists
-		if status, ok := application["status"].(bson.M); ok {
-			status["last_updated"] = time.Now()
-		}
+	if application.Status.CurrentStage == "" {
+		application.Status.CurrentStage = models.StageApplied
 	}
+	application.Status.LastUpdated = time.Now()
 
-	log.Println("Inserting application:", application)
+	log.Println("Inserting application:", application.ApplicationID)
 	_, err = repo.Collection.InsertOne(context.TODO(), application)
-	log.Println("Error inserting application:", err)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicateApplication
+	}
+	if err != nil {
+		log.Println("Error inserting application:", err)
+	}
 	return err
 }
 
-func (repo *ApplicationRepo) GetApplicationsByJobID(jobID uint) ([]bson.M, error) {
-	var applications []bson.M
-	filter := bson.M{"job_id": jobID}
-	cursor, err := repo.Collection.Find(context.TODO(), filter)
+// CreateListingIndexes creates the compound indexes behind application listings: one
+// per sort key, and one for each filter that narrows a job's applications much
+func (repo *ApplicationRepo) CreateListingIndexes() error {
+	ctx := context.TODO()
+
+	// applications created before listings were sortable have no applied_at; their
+	// first stage change, or failing that their last update, is the closest there is
+	backfill := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"applied_at": bson.M{"$ifNull": bson.A{
+				bson.M{"$arrayElemAt": bson.A{"$stage_history.changed_at", 0}},
+				"$status.last_updated",
+			}},
+		}}},
+	}
+	if _, err := repo.Collection.UpdateMany(ctx, bson.M{"applied_at": bson.M{"$exists": false}}, backfill); err != nil {
+		return fmt.Errorf("failed to backfill applied_at: %v", err)
+	}
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "job_id", Value: 1}, {Key: "applied_at", Value: -1}, {Key: "application_id", Value: -1}}},
+		{Keys: bson.D{{Key: "job_id", Value: 1}, {Key: "status.last_updated", Value: -1}, {Key: "application_id", Value: -1}}},
+		{Keys: bson.D{{Key: "job_id", Value: 1}, {Key: "rating", Value: -1}, {Key: "application_id", Value: -1}}},
+		{Keys: bson.D{{Key: "job_id", Value: 1}, {Key: "match.score", Value: -1}, {Key: "application_id", Value: -1}}},
+		{Keys: bson.D{{Key: "job_id", Value: 1}, {Key: "status.current_stage", Value: 1}, {Key: "applied_at", Value: -1}, {Key: "application_id", Value: -1}}},
+		{Keys: bson.D{{Key: "job_id", Value: 1}, {Key: "tags", Value: 1}, {Key: "applied_at", Value: -1}, {Key: "application_id", Value: -1}}},
+	}
+	_, err := repo.Collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// GetApplicationsByJobID returns one page of a job's applications. It fetches one
+// application more than opts.Limit so the caller can tell whether another page follows.
+func (repo *ApplicationRepo) GetApplicationsByJobID(jobID uint, opts models.ApplicationQueryOptions) ([]models.Application, error) {
+	filter := bson.M{"job_id": idFilter(jobID)}
+	if len(opts.Stages) > 0 {
+		filter["status.current_stage"] = bson.M{"$in": opts.Stages}
+	}
+	if len(opts.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": opts.Tags}
+	}
+	applied := bson.M{}
+	if opts.AppliedAfter != nil {
+		applied["$gte"] = *opts.AppliedAfter
+	}
+	if opts.AppliedBefore != nil {
+		applied["$lte"] = *opts.AppliedBefore
+	}
+	if len(applied) > 0 {
+		filter["applied_at"] = applied
+	}
+	rating := bson.M{}
+	if opts.MinRating != nil {
+		rating["$gte"] = *opts.MinRating
+	}
+	if opts.MaxRating != nil {
+		rating["$lte"] = *opts.MaxRating
+	}
+	if len(rating) > 0 {
+		filter["rating"] = rating
+	}
+
+	field := models.ApplicationSortFields[opts.SortBy]
+	if opts.After != nil {
+		filter["$or"] = keysetFilter(field, opts.After, opts.SortDesc)
+	}
+
+	direction := 1
+	if opts.SortDesc {
+		direction = -1
+	}
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: field, Value: direction}, {Key: "application_id", Value: direction}}).
+		SetLimit(int64(opts.Limit) + 1)
+	return repo.findApplications(filter, findOpts)
+}
+
+// GetJobBoard counts a job's applications per stage and collects the newest
+// cardsPerStage+1 of each, so the caller can tell which columns continue. $topN
+// keeps only those cards while grouping, so a crowded stage never builds a
+// document holding all of its applications.
+func (repo *ApplicationRepo) GetJobBoard(jobID uint, cardsPerStage int) ([]models.BoardColumn, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"job_id": idFilter(jobID)}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$status.current_stage",
+			"count": bson.M{"$sum": 1},
+			"cards": bson.M{"$topN": bson.M{
+				"n": cardsPerStage + 1,
+				// same order as the default application listing, so card cursors carry on from here
+				"sortBy": bson.D{{Key: "applied_at", Value: -1}, {Key: "application_id", Value: -1}},
+				"output": bson.M{
+					"application_id": "$application_id",
+					"candidate_id":   "$candidate_id",
+					"email":          "$email",
+					"applied_at":     "$applied_at",
+					"last_updated":   "$status.last_updated",
+					"tags":           "$tags",
+					"rating":         "$rating",
+				},
+			}},
+		}}},
+	}
+
+	cursor, err := repo.Collection.Aggregate(context.TODO(), pipeline)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to aggregate job board: %v", err)
 	}
 	defer cursor.Close(context.TODO())
 
-	if err = cursor.All(context.TODO(), &applications); err != nil {
-		return nil, err
+	columns := make([]models.BoardColumn, 0)
+	if err = cursor.All(context.TODO(), &columns); err != nil {
+		return nil, fmt.Errorf("failed to decode job board: %v", err)
 	}
+	return columns, nil
+}
 
-	return applications, nil
+// keysetFilter matches the applications that sort after the cursor. Missing values
+// sort before every other value in MongoDB, so they come first ascending and last descending.
+func keysetFilter(field string, after *models.ApplicationCursor, desc bool) bson.A {
+	idAfter := bson.M{"$gt": after.ApplicationID}
+	if desc {
+		idAfter = bson.M{"$lt": after.ApplicationID}
+	}
+
+	if after.Value == nil {
+		conditions := bson.A{bson.M{field: nil, "application_id": idAfter}}
+		if !desc {
+			conditions = append(conditions, bson.M{field: bson.M{"$ne": nil}})
+		}
+		return conditions
+	}
+
+	valueAfter := bson.M{"$gt": after.Value}
+	if desc {
+		valueAfter = bson.M{"$lt": after.Value}
+	}
+	conditions := bson.A{
+		bson.M{field: valueAfter},
+		bson.M{field: after.Value, "application_id": idAfter},
+	}
+	if desc {
+		conditions = append(conditions, bson.M{field: nil})
+	}
+	return conditions
 }
 
-func (repo *ApplicationRepo) GetApplicationByID(applicationID string) (bson.M, error) {
-	var application bson.M
+func (repo *ApplicationRepo) GetApplicationByID(applicationID string) (*models.Application, error) {
+	var application models.Application
 	filter := bson.M{"application_id": applicationID}
 	err := repo.Collection.FindOne(context.TODO(), filter).Decode(&application)
 	if err != nil {
 		return nil, err
 	}
 
-	return application, nil
+	return &application, nil
 }
 
-func (repo *ApplicationRepo) GetApplicationsByCandidateID(candidateID uint) ([]bson.M, error) {
-	log.Printf("Searching for applications with candidate_id: %d", candidateID)
+// GetApplicationsByIDs returns the applications with the given IDs that exist, in no particular order
+func (repo *ApplicationRepo) GetApplicationsByIDs(applicationIDs []string) ([]models.Application, error) {
+	return repo.findApplications(bson.M{"application_id": bson.M{"$in": applicationIDs}})
+}
 
-	filter := bson.M{"candidate_id": candidateID}
+func (repo *ApplicationRepo) GetApplicationsByCandidateID(candidateID uint) ([]models.Application, error) {
+	log.Printf("Searching for applications with candidate_id: %d", candidateID)
 
-	cursor, err := repo.Collection.Find(context.TODO(), filter)
+	filter := bson.M{"candidate_id": idFilter(candidateID)}
+	applications, err := repo.findApplications(filter)
 	if err != nil {
 		log.Printf("Error finding applications: %v", err)
-		return nil, fmt.Errorf("failed to find applications: %v", err)
-	}
-	defer cursor.Close(context.TODO())
-
-	var applications []bson.M
-	if err = cursor.All(context.TODO(), &applications); err != nil {
-		log.Printf("Error decoding applications: %v", err)
-		return nil, fmt.Errorf("failed to decode applications: %v", err)
+		return nil, err
 	}
 
 	log.Printf("Found %d applications for candidate_id: %d", len(applications), candidateID)
 	return applications, nil
 }
 
-func (repo *ApplicationRepo) GetApplicationByCandidateID(candidateID uint) (bson.M, error) {
+func (repo *ApplicationRepo) GetApplicationByCandidateID(candidateID uint) (*models.Application, error) {
 	log.Printf("Searching for application with candidate_id: %d", candidateID)
 
-	filter := bson.M{"candidate_id": candidateID}
+	filter := bson.M{"candidate_id": idFilter(candidateID)}
 
-	var application bson.M
+	var application models.Application
 	err := repo.Collection.FindOne(context.TODO(), filter).Decode(&application)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -145,5 +295,291 @@ func (repo *ApplicationRepo) GetApplicationByCandidateID(candidateID uint) (bson
 		return nil, fmt.Errorf("failed to find application: %v", err)
 	}
 
-	return application, nil
+	return &application, nil
+}
+
+// GetLatestApplication returns the candidate's most recent application for a job,
+// or nil if they never applied
+func (repo *ApplicationRepo) GetLatestApplication(candidateID uint, jobID uint) (*models.Application, error) {
+	filter := bson.M{
+		"candidate_id": idFilter(candidateID),
+		"job_id":       idFilter(jobID),
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "active", Value: -1}, {Key: "withdrawn_at", Value: -1}})
+
+	var application models.Application
+	err := repo.Collection.FindOne(context.TODO(), filter, opts).Decode(&application)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find application: %v", err)
+	}
+	return &application, nil
+}
+
+func (repo *ApplicationRepo) findApplications(filter bson.M, opts ...*options.FindOptions) ([]models.Application, error) {
+	cursor, err := repo.Collection.Find(context.TODO(), filter, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find applications: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	applications := make([]models.Application, 0)
+	if err = cursor.All(context.TODO(), &applications); err != nil {
+		return nil, fmt.Errorf("failed to decode applications: %v", err)
+	}
+	return applications, nil
+}
+
+// idFilter matches an ID field whether it was stored as a number or, in older
+// documents, as a string
+func idFilter(id uint) bson.M {
+	return bson.M{"$in": bson.A{id, strconv.FormatUint(uint64(id), 10)}}
+}
+
+// UpdateStage moves an application to change.To and appends change to its stage history.
+// The update only matches while the application is still in change.From, so of two
+// concurrent moves only one succeeds; the other gets ErrStageConflict.
+func (repo *ApplicationRepo) UpdateStage(applicationID string, change models.StageChange) (*models.Application, error) {
+	return repo.updateStage(applicationID, change, nil)
+}
+
+// WithdrawApplication moves an application to change.To like UpdateStage and marks it
+// inactive so the candidate can apply to the job again
+func (repo *ApplicationRepo) WithdrawApplication(applicationID string, change models.StageChange) (*models.Application, error) {
+	return repo.updateStage(applicationID, change, bson.M{
+		"active":       false,
+		"withdrawn_at": change.ChangedAt,
+	})
+}
+
+// RejectApplication moves an application to a rejected stage like UpdateStage and
+// records why it was rejected
+func (repo *ApplicationRepo) RejectApplication(applicationID string, change models.StageChange, disposition models.Disposition) (*models.Application, error) {
+	return repo.updateStage(applicationID, change, bson.M{"disposition": disposition})
+}
+
+// RevertStage undoes change, the last stage change of an application, when a write that
+// had to go with it failed. The application gets back the status, active flag and
+// withdrawal time of previous, its copy from before the change, and the change is taken
+// off its stage history. If the application has moved on since, ErrStageConflict is
+// returned and nothing is undone.
+func (repo *ApplicationRepo) RevertStage(previous *models.Application, change models.StageChange) error {
+	filter := bson.M{
+		"application_id":       previous.ApplicationID,
+		"status.current_stage": change.To,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       previous.Status,
+			"active":       previous.Active,
+			"withdrawn_at": previous.WithdrawnAt,
+		},
+		"$pop": bson.M{"stage_history": 1},
+	}
+	result, err := repo.Collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to revert application stage: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrStageConflict
+	}
+	return nil
+}
+
+// updateStage applies a stage change guarded by the expected from-stage, setting any extra fields with it
+func (repo *ApplicationRepo) updateStage(applicationID string, change models.StageChange, extra bson.M) (*models.Application, error) {
+	filter := bson.M{
+		"application_id":       applicationID,
+		"status.current_stage": change.From,
+	}
+
+	var application models.Application
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := repo.Collection.FindOneAndUpdate(context.TODO(), filter, stageChangeUpdate(change, extra), opts).Decode(&application)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrStageConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update application stage: %v", err)
+	}
+	return &application, nil
+}
+
+// SetResume attaches an uploaded resume to an application, replacing any resume URL it
+// was submitted with
+func (repo *ApplicationRepo) SetResume(applicationID string, resume models.ResumeFile) (*models.Application, error) {
+	update := bson.M{"$set": bson.M{"resume": resume, "resume_url": ""}}
+
+	var application models.Application
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := repo.Collection.FindOneAndUpdate(context.TODO(), bson.M{"application_id": applicationID}, update, opts).Decode(&application)
+	if err == mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set resume: %v", err)
+	}
+	return &application, nil
+}
+
+// SetMatch stores an application's match score. The score only lands if the application
+// still has the resume it was computed from; otherwise mongo.ErrNoDocuments is returned
+// and the newer resume's own scoring takes its place.
+func (repo *ApplicationRepo) SetMatch(applicationID string, match models.ResumeMatch) error {
+	filter := bson.M{"application_id": applicationID, "resume": nil}
+	if match.ResumeSHA256 != "" {
+		filter["resume"] = bson.M{"$ne": nil}
+		filter["resume.sha256"] = match.ResumeSHA256
+	}
+	result, err := repo.Collection.UpdateOne(context.TODO(), filter, bson.M{"$set": bson.M{"match": match}})
+	if err != nil {
+		return fmt.Errorf("failed to set match: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// stageChangeUpdate moves an application to change.To, appends change to its stage
+// history and sets any extra fields
+func stageChangeUpdate(change models.StageChange, extra bson.M) bson.M {
+	set := bson.M{
+		"status.current_stage": change.To,
+		"status.reason":        change.Reason,
+		"status.last_updated":  change.ChangedAt,
+	}
+	for field, value := range extra {
+		set[field] = value
+	}
+	return bson.M{
+		"$set":  set,
+		"$push": bson.M{"stage_history": change},
+	}
+}
+
+// BulkUpdateApplications applies updates in one unordered bulk write, so an update that
+// fails does not stop the others. Stage changes are guarded by their from-stage like
+// UpdateStage. It returns the updated applications that reflect their update, by ID;
+// updates missing from the result lost to a concurrent change or failed to write.
+func (repo *ApplicationRepo) BulkUpdateApplications(updates []models.ApplicationUpdate) (map[string]*models.Application, error) {
+	writes := make([]mongo.WriteModel, 0, len(updates))
+	byID := make(map[string]models.ApplicationUpdate, len(updates))
+	ids := make([]string, 0, len(updates))
+	for _, update := range updates {
+		filter := bson.M{"application_id": update.ApplicationID}
+		doc := bson.M{}
+		if update.Change != nil {
+			// Mongo keeps milliseconds, so the change is truncated to compare it with what was stored
+			change := *update.Change
+			change.ChangedAt = change.ChangedAt.Truncate(time.Millisecond)
+			update.Change = &change
+
+			filter["status.current_stage"] = change.From
+			extra := bson.M{}
+			if update.Disposition != nil {
+				extra["disposition"] = *update.Disposition
+			}
+			doc = stageChangeUpdate(change, extra)
+		}
+		if len(update.AddTags) > 0 {
+			doc["$addToSet"] = bson.M{"tags": bson.M{"$each": update.AddTags}}
+		}
+		if len(update.RemoveTags) > 0 {
+			doc["$pullAll"] = bson.M{"tags": update.RemoveTags}
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(doc))
+		byID[update.ApplicationID] = update
+		ids = append(ids, update.ApplicationID)
+	}
+
+	_, err := repo.Collection.BulkWrite(context.TODO(), writes, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) {
+		log.Printf("Bulk application update failed for %d of %d applications: %v", len(bulkErr.WriteErrors), len(writes), err)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to update applications: %v", err)
+	}
+
+	applications, err := repo.GetApplicationsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]*models.Application, len(applications))
+	for i := range applications {
+		app := &applications[i]
+		if update := byID[app.ApplicationID]; update.AppliedTo(app) {
+			applied[app.ApplicationID] = app
+		}
+	}
+	return applied, nil
+}
+
+// SubmitScorecard stores an interviewer's scorecard on an application, replacing any
+// earlier one by the same interviewer, and recomputes the application's rating as the
+// mean overall rating of its scorecards. Both happen in one update pipeline so
+// concurrent submissions cannot leave a stale rating behind.
+func (repo *ApplicationRepo) SubmitScorecard(applicationID string, scorecard models.Scorecard) (*models.Application, error) {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"scorecards": bson.M{"$concatArrays": bson.A{
+				bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$scorecards", bson.A{}}},
+					"cond":  bson.M{"$ne": bson.A{"$$this.interviewer_id", scorecard.InterviewerID}},
+				}},
+				// $literal keeps comments starting with $ from being read as field paths
+				bson.A{bson.M{"$literal": scorecard}},
+			}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"rating": bson.M{"$round": bson.A{bson.M{"$avg": "$scorecards.overall"}, 2}},
+		}}},
+	}
+
+	var application models.Application
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := repo.Collection.FindOneAndUpdate(context.TODO(), bson.M{"application_id": applicationID}, update, opts).Decode(&application)
+	if err == mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit scorecard: %v", err)
+	}
+	return &application, nil
+}
+
+// CloseApplicationsForJob moves a job's applications that are not yet in one of
+// terminalStages to StageJobClosed with the given reason and returns how many were changed
+func (repo *ApplicationRepo) CloseApplicationsForJob(jobID uint, terminalStages []string, reason string) (int64, error) {
+	now := time.Now()
+	filter := bson.M{
+		"job_id":               idFilter(jobID),
+		"status.current_stage": bson.M{"$nin": terminalStages},
+	}
+	// an update pipeline lets the history entry read each document's current stage
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"stage_history": bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$stage_history", bson.A{}}},
+				bson.A{bson.M{
+					"from":       "$status.current_stage",
+					"to":         models.StageJobClosed,
+					"changed_by": 0,
+					"changed_at": now,
+					"reason":     reason,
+				}},
+			}},
+			"status.current_stage": models.StageJobClosed,
+			"status.reason":        reason,
+			"status.last_updated":  now,
+		}}},
+	}
+
+	result, err := repo.Collection.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to close applications: %v", err)
+	}
+	return result.ModifiedCount, nil
 }
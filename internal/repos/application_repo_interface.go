@@ -3,16 +3,32 @@ package repos
 import (
 	"errors"
 
-	"go.mongodb.org/mongo-driver/bson"
+	"jobs-svc/internal/models"
 )
 
-var ErrDuplicateApplication = errors.New("candidate has already applied for this job")
+var (
+	ErrDuplicateApplication = errors.New("candidate has already applied for this job")
+	ErrStageConflict        = errors.New("application stage was changed by another request")
+)
 
 type ApplicationRepoInterface interface {
-	CreateApplication(application bson.M) error
-	GetApplicationsByJobID(jobID uint) ([]bson.M, error)
-	GetApplicationByID(applicationID string) (bson.M, error)
-	GetApplicationsByCandidateID(candidateID uint) ([]bson.M, error)
-	GetApplicationByCandidateID(candidateID uint) (bson.M, error)
+	CreateApplication(application *models.Application) error
+	GetApplicationsByJobID(jobID uint, opts models.ApplicationQueryOptions) ([]models.Application, error)
+	GetJobBoard(jobID uint, cardsPerStage int) ([]models.BoardColumn, error)
+	GetApplicationByID(applicationID string) (*models.Application, error)
+	GetApplicationsByIDs(applicationIDs []string) ([]models.Application, error)
+	GetApplicationsByCandidateID(candidateID uint) ([]models.Application, error)
+	GetApplicationByCandidateID(candidateID uint) (*models.Application, error)
+	GetLatestApplication(candidateID uint, jobID uint) (*models.Application, error)
+	UpdateStage(applicationID string, change models.StageChange) (*models.Application, error)
+	WithdrawApplication(applicationID string, change models.StageChange) (*models.Application, error)
+	RejectApplication(applicationID string, change models.StageChange, disposition models.Disposition) (*models.Application, error)
+	RevertStage(previous *models.Application, change models.StageChange) error
+	SetResume(applicationID string, resume models.ResumeFile) (*models.Application, error)
+	SetMatch(applicationID string, match models.ResumeMatch) error
+	BulkUpdateApplications(updates []models.ApplicationUpdate) (map[string]*models.Application, error)
+	SubmitScorecard(applicationID string, scorecard models.Scorecard) (*models.Application, error)
+	CloseApplicationsForJob(jobID uint, terminalStages []string, reason string) (int64, error)
 	CreateUniqueIndex() error
+	CreateListingIndexes() error
 }
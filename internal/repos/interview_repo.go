@@ -0,0 +1,170 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jobs-svc/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInterviewerBooked is returned by BookInterviewer when the interviewer already has a
+// slot overlapping the one being booked
+var ErrInterviewerBooked = errors.New("interviewer is booked at that time")
+
+type InterviewRepo struct {
+	Collection *mongo.Collection
+	// Slots holds one document per interviewer with the slots they are booked for
+	Slots *mongo.Collection
+}
+
+// CreateIndexes creates a unique index on interview_id, the index interviews are listed
+// by and the multikey index conflict checks search interviewers' schedules with, plus the
+// unique index that keeps each interviewer's slots in a single document
+func (repo *InterviewRepo) CreateIndexes() error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "interview_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "application_id", Value: 1}, {Key: "starts_at", Value: 1}}},
+		{Keys: bson.D{{Key: "interviewer_ids", Value: 1}, {Key: "starts_at", Value: 1}}},
+	}
+	if _, err := repo.Collection.Indexes().CreateMany(context.TODO(), indexes); err != nil {
+		return err
+	}
+	_, err := repo.Slots.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "interviewer_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (repo *InterviewRepo) CreateInterview(interview *models.Interview) error {
+	if _, err := repo.Collection.InsertOne(context.TODO(), interview); err != nil {
+		return fmt.Errorf("failed to insert interview: %v", err)
+	}
+	return nil
+}
+
+func (repo *InterviewRepo) GetInterviewByID(interviewID string) (*models.Interview, error) {
+	var interview models.Interview
+	err := repo.Collection.FindOne(context.TODO(), bson.M{"interview_id": interviewID}).Decode(&interview)
+	if err != nil {
+		return nil, err
+	}
+	return &interview, nil
+}
+
+// GetInterviewsByApplicationID returns an application's interviews, earliest first
+func (repo *InterviewRepo) GetInterviewsByApplicationID(applicationID string) ([]models.Interview, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "starts_at", Value: 1}})
+	return repo.findInterviews(bson.M{"application_id": applicationID}, opts)
+}
+
+// FindConflicts returns the scheduled interviews of any of interviewerIDs that overlap
+// start to end, other than excludeID
+func (repo *InterviewRepo) FindConflicts(interviewerIDs []uint, start, end time.Time, excludeID string) ([]models.Interview, error) {
+	filter := bson.M{
+		"interviewer_ids": bson.M{"$in": interviewerIDs},
+		"status":          models.InterviewStatusScheduled,
+		"starts_at":       bson.M{"$lt": end},
+		"ends_at":         bson.M{"$gt": start},
+	}
+	if excludeID != "" {
+		filter["interview_id"] = bson.M{"$ne": excludeID}
+	}
+	return repo.findInterviews(filter, options.Find().SetSort(bson.D{{Key: "starts_at", Value: 1}}))
+}
+
+// UpdateInterview saves an interview's schedule, place and status
+func (repo *InterviewRepo) UpdateInterview(interview *models.Interview) error {
+	update := bson.M{"$set": bson.M{
+		"title":           interview.Title,
+		"interviewer_ids": interview.InterviewerIDs,
+		"starts_at":       interview.StartsAt,
+		"ends_at":         interview.EndsAt,
+		"location":        interview.Location,
+		"video_link":      interview.VideoLink,
+		"status":          interview.Status,
+		"sequence":        interview.Sequence,
+		"slot_hold":       interview.SlotHold,
+		"updated_at":      interview.UpdatedAt,
+	}}
+	result, err := repo.Collection.UpdateOne(context.TODO(), bson.M{"interview_id": interview.ID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update interview: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// BookInterviewer adds slot to the interviewer's slots unless it overlaps a slot of
+// another interview, in which case it returns ErrInterviewerBooked. The check and the
+// write are one update of the interviewer's document, so concurrent bookings of the
+// same time cannot both succeed. Slots that have ended are dropped along the way.
+func (repo *InterviewRepo) BookInterviewer(interviewerID uint, slot models.InterviewSlot) error {
+	_, err := repo.Slots.UpdateOne(context.TODO(),
+		bson.M{"interviewer_id": interviewerID},
+		bson.M{"$setOnInsert": bson.M{"slots": bson.A{}}},
+		options.Update().SetUpsert(true))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("failed to create interviewer slots: %v", err)
+	}
+
+	filter := bson.M{
+		"interviewer_id": interviewerID,
+		"slots": bson.M{"$not": bson.M{"$elemMatch": bson.M{
+			"interview_id": bson.M{"$ne": slot.InterviewID},
+			"starts_at":    bson.M{"$lt": slot.EndsAt},
+			"ends_at":      bson.M{"$gt": slot.StartsAt},
+		}}},
+	}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"slots": bson.M{"$concatArrays": bson.A{
+			bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$slots", bson.A{}}},
+				"cond":  bson.M{"$gt": bson.A{"$$this.ends_at", time.Now()}},
+			}},
+			bson.A{slot},
+		}},
+	}}}}
+	result, err := repo.Slots.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to book interviewer: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrInterviewerBooked
+	}
+	return nil
+}
+
+// ReleaseInterviewer frees the interviewer's slots for an interview, except those of
+// keepHold
+func (repo *InterviewRepo) ReleaseInterviewer(interviewerID uint, interviewID string, keepHold string) error {
+	update := bson.M{"$pull": bson.M{"slots": bson.M{
+		"interview_id": interviewID,
+		"hold":         bson.M{"$ne": keepHold},
+	}}}
+	if _, err := repo.Slots.UpdateOne(context.TODO(), bson.M{"interviewer_id": interviewerID}, update); err != nil {
+		return fmt.Errorf("failed to release interviewer: %v", err)
+	}
+	return nil
+}
+
+func (repo *InterviewRepo) findInterviews(filter bson.M, opts *options.FindOptions) ([]models.Interview, error) {
+	cursor, err := repo.Collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find interviews: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	interviews := make([]models.Interview, 0)
+	if err = cursor.All(context.TODO(), &interviews); err != nil {
+		return nil, fmt.Errorf("failed to decode interviews: %v", err)
+	}
+	return interviews, nil
+}
@@ -0,0 +1,18 @@
+package repos
+
+import (
+	"time"
+
+	"jobs-svc/internal/models"
+)
+
+type InterviewRepoInterface interface {
+	CreateInterview(interview *models.Interview) error
+	GetInterviewByID(interviewID string) (*models.Interview, error)
+	GetInterviewsByApplicationID(applicationID string) ([]models.Interview, error)
+	FindConflicts(interviewerIDs []uint, start, end time.Time, excludeID string) ([]models.Interview, error)
+	UpdateInterview(interview *models.Interview) error
+	BookInterviewer(interviewerID uint, slot models.InterviewSlot) error
+	ReleaseInterviewer(interviewerID uint, interviewID string, keepHold string) error
+	CreateIndexes() error
+}
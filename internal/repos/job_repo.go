@@ -3,6 +3,7 @@ package repos
 import (
 	//"github.com/jinzhu/gorm"
 	"jobs-svc/internal/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -21,10 +22,20 @@ func (repo *JobRepo) GetJobByID(id uint) (*models.Job, error) {
 	return &job, err
 }
 
-func (repo *JobRepo) GetJobs() (*[]models.Job, error) {
+// GetJobs returns one page of jobs matching opts along with the total match count
+func (repo *JobRepo) GetJobs(opts models.JobQueryOptions) (*[]models.Job, int64, error) {
+	var total int64
+	if err := applyJobFilters(repo.DB.Model(&models.Job{}), opts).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	var jobs []models.Job
-	err := repo.DB.Find(&jobs).Error
-	return &jobs, err
+	err := applyJobFilters(repo.DB, opts).
+		Order(jobOrder(opts)).
+		Offset(opts.Offset()).
+		Limit(opts.Limit).
+		Find(&jobs).Error
+	return &jobs, total, err
 }
 
 func (repo *JobRepo) GetJobsByRecruiterID(recruiterID uint) (*[]models.Job, error) {
@@ -33,16 +44,164 @@ func (repo *JobRepo) GetJobsByRecruiterID(recruiterID uint) (*[]models.Job, erro
 	return &jobs, err
 }
 
+// UpdateJob saves the job only if its stored version still matches job.Version,
+// then bumps the version. A stale version returns ErrJobVersionConflict.
 func (repo *JobRepo) UpdateJob(job *models.Job) error {
-	return repo.DB.Save(job).Error
+	expected := job.Version
+	job.Version = expected + 1
+
+	result := repo.DB.Model(job).
+		Where("version = ?", expected).
+		Select("*").
+		Omit("id", "created_at", "deleted_at").
+		Updates(job)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrJobVersionConflict
+	}
+	if result.Error != nil {
+		job.Version = expected
+	}
+	return result.Error
 }
 
 func (repo *JobRepo) DeleteJob(id uint) error {
 	return repo.DB.Delete(&models.Job{}, id).Error
 }
 
+// GetDeletedJobs returns a company's soft-deleted jobs, most recently deleted first
+func (repo *JobRepo) GetDeletedJobs(companyID uint) (*[]models.Job, error) {
+	var jobs []models.Job
+	err := repo.DB.Unscoped().
+		Where("company_id = ? AND deleted_at IS NOT NULL", companyID).
+		Order("deleted_at DESC").
+		Find(&jobs).Error
+	return &jobs, err
+}
+
+func (repo *JobRepo) GetDeletedJobByID(id uint) (*models.Job, error) {
+	var job models.Job
+	err := repo.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&job, id).Error
+	return &job, err
+}
+
+// RestoreJob clears a job's soft delete and bumps its version, since a restore is
+// recorded as a revision of its own
+func (repo *JobRepo) RestoreJob(id uint) error {
+	return repo.DB.Unscoped().Model(&models.Job{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+}
+
+// PurgeDeletedJobs permanently removes jobs soft-deleted before the cutoff,
+// along with their revision history, and returns the purged jobs
+func (repo *JobRepo) PurgeDeletedJobs(before time.Time) (*[]models.Job, error) {
+	var jobs []models.Job
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(jobs))
+		for i, job := range jobs {
+			ids[i] = job.ID
+		}
+		if err := tx.Where("job_id IN ?", ids).Delete(&models.JobRevision{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Job{}).Error
+	})
+	return &jobs, err
+}
+
 func (repo *JobRepo) GetJobsByIDs(ids []uint) (*[]models.Job, error) {
 	var jobs []models.Job
 	err := repo.DB.Where("id IN ?", ids).Find(&jobs).Error
 	return &jobs, err
 }
+
+// CountJobsByPipeline counts the jobs referencing a pipeline, soft-deleted ones
+// included since they can still be restored
+func (repo *JobRepo) CountJobsByPipeline(pipelineID string) (int64, error) {
+	var total int64
+	err := repo.DB.Unscoped().Model(&models.Job{}).Where("pipeline_id = ?", pipelineID).Count(&total).Error
+	return total, err
+}
+
+const (
+	jobSearchQuery = "websearch_to_tsquery('english', ?)"
+	// ts_headline options used to build highlighted snippets for search results
+	jobSearchHeadline = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=\" ... \""
+	// jobSearchDocument HTML-escapes the searchable text before ts_headline sees it,
+	// so the only markup left in a snippet is the <mark> highlighting
+	jobSearchDocument = "replace(replace(replace(replace(replace(concat_ws(' ', title, overview, description, skills), " +
+		"'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '\"', '&quot;'), '''', '&#39;')"
+)
+
+// SearchJobs runs a ranked full-text search against the generated search_vector column
+func (repo *JobRepo) SearchJobs(query string, opts models.JobQueryOptions) (*[]models.JobSearchResult, int64, error) {
+	var total int64
+	err := applyJobFilters(repo.DB.Model(&models.Job{}), opts).
+		Where("search_vector @@ "+jobSearchQuery, query).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var results []models.JobSearchResult
+	err = applyJobFilters(repo.DB.Model(&models.Job{}), opts).
+		Select("jobs.*, ts_rank(search_vector, "+jobSearchQuery+") AS rank, "+
+			"ts_headline('english', "+jobSearchDocument+", "+jobSearchQuery+", ?) AS snippet",
+			query, query, jobSearchHeadline).
+		Where("search_vector @@ "+jobSearchQuery, query).
+		Order("rank DESC, id DESC").
+		Offset(opts.Offset()).
+		Limit(opts.Limit).
+		Scan(&results).Error
+	return &results, total, err
+}
+
+func applyJobFilters(db *gorm.DB, opts models.JobQueryOptions) *gorm.DB {
+	if opts.Status != nil {
+		db = db.Where("status = ?", *opts.Status)
+	} else if len(opts.Statuses) > 0 {
+		db = db.Where("status IN ?", opts.Statuses)
+	}
+	if opts.Location != "" {
+		db = db.Where("location ILIKE ?", "%"+opts.Location+"%")
+	}
+	if opts.Company != "" {
+		db = db.Where("company ILIKE ?", "%"+opts.Company+"%")
+	}
+	if opts.CompanyID != 0 {
+		db = db.Where("company_id = ?", opts.CompanyID)
+	}
+	if opts.RecruiterID != 0 {
+		db = db.Where("recruiter_id = ?", opts.RecruiterID)
+	}
+	if opts.PipelineID != "" {
+		db = db.Where("pipeline_id = ?", opts.PipelineID)
+	}
+	if opts.PostedAfter != nil {
+		db = db.Where("posted_date >= ?", *opts.PostedAfter)
+	}
+	if opts.PostedBefore != nil {
+		db = db.Where("posted_date <= ?", *opts.PostedBefore)
+	}
+	return db
+}
+
+func jobOrder(opts models.JobQueryOptions) string {
+	column, ok := models.JobSortFields[opts.SortBy]
+	if !ok {
+		column = "posted_date"
+	}
+	direction := "ASC"
+	if opts.SortDesc {
+		direction = "DESC"
+	}
+	// id keeps the ordering stable across pages
+	return column + " " + direction + ", id " + direction
+}
@@ -1,15 +1,26 @@
 package repos
 
 import (
+	"errors"
+	"time"
+
 	"jobs-svc/internal/models"
 )
 
+var ErrJobVersionConflict = errors.New("job was modified by another request")
+
 type JobRepoInterface interface {
 	CreateJob(job *models.Job) error
 	GetJobByID(id uint) (*models.Job, error)
-	GetJobs() (*[]models.Job, error)
+	GetJobs(opts models.JobQueryOptions) (*[]models.Job, int64, error)
+	SearchJobs(query string, opts models.JobQueryOptions) (*[]models.JobSearchResult, int64, error)
 	GetJobsByRecruiterID(recruiterID uint) (*[]models.Job, error)
 	UpdateJob(job *models.Job) error
 	DeleteJob(id uint) error
+	GetDeletedJobs(companyID uint) (*[]models.Job, error)
+	GetDeletedJobByID(id uint) (*models.Job, error)
+	RestoreJob(id uint) error
+	PurgeDeletedJobs(before time.Time) (*[]models.Job, error)
 	GetJobsByIDs(ids []uint) (*[]models.Job, error)
+	CountJobsByPipeline(pipelineID string) (int64, error)
 }
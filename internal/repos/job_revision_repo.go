@@ -0,0 +1,28 @@
+package repos
+
+import (
+	"jobs-svc/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type JobRevisionRepo struct {
+	DB *gorm.DB
+}
+
+func (repo *JobRevisionRepo) CreateRevision(revision *models.JobRevision) error {
+	return repo.DB.Create(revision).Error
+}
+
+// GetRevisions returns a job's revisions, newest first
+func (repo *JobRevisionRepo) GetRevisions(jobID uint) (*[]models.JobRevision, error) {
+	var revisions []models.JobRevision
+	err := repo.DB.Where("job_id = ?", jobID).Order("revision DESC").Find(&revisions).Error
+	return &revisions, err
+}
+
+func (repo *JobRevisionRepo) GetRevision(jobID uint, revision uint) (*models.JobRevision, error) {
+	var jobRevision models.JobRevision
+	err := repo.DB.Where("job_id = ? AND revision = ?", jobID, revision).First(&jobRevision).Error
+	return &jobRevision, err
+}
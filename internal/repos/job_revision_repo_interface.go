@@ -0,0 +1,9 @@
+package repos
+
+import "jobs-svc/internal/models"
+
+type JobRevisionRepoInterface interface {
+	CreateRevision(revision *models.JobRevision) error
+	GetRevisions(jobID uint) (*[]models.JobRevision, error)
+	GetRevision(jobID uint, revision uint) (*models.JobRevision, error)
+}
@@ -0,0 +1,94 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"jobs-svc/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NoteRepo struct {
+	Collection *mongo.Collection
+}
+
+// CreateIndexes creates a unique index on note_id and the index notes are listed by
+func (repo *NoteRepo) CreateIndexes() error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "note_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "application_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	}
+	_, err := repo.Collection.Indexes().CreateMany(context.TODO(), indexes)
+	return err
+}
+
+func (repo *NoteRepo) CreateNote(note *models.Note) error {
+	if _, err := repo.Collection.InsertOne(context.TODO(), note); err != nil {
+		return fmt.Errorf("failed to insert note: %v", err)
+	}
+	return nil
+}
+
+func (repo *NoteRepo) GetNoteByID(noteID string) (*models.Note, error) {
+	var note models.Note
+	err := repo.Collection.FindOne(context.TODO(), bson.M{"note_id": noteID}).Decode(&note)
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// GetNotesByApplicationID returns an application's team notes and the viewer's own
+// private notes, oldest first
+func (repo *NoteRepo) GetNotesByApplicationID(applicationID string, viewerID uint) ([]models.Note, error) {
+	filter := bson.M{
+		"application_id": applicationID,
+		"$or": bson.A{
+			bson.M{"visibility": models.NoteVisibilityTeam},
+			bson.M{"author_id": viewerID},
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "note_id", Value: 1}})
+	cursor, err := repo.Collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notes: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	notes := make([]models.Note, 0)
+	if err = cursor.All(context.TODO(), &notes); err != nil {
+		return nil, fmt.Errorf("failed to decode notes: %v", err)
+	}
+	return notes, nil
+}
+
+// UpdateNote replaces a note's body, visibility and mentions
+func (repo *NoteRepo) UpdateNote(note *models.Note) error {
+	update := bson.M{"$set": bson.M{
+		"body":       note.Body,
+		"visibility": note.Visibility,
+		"mentions":   note.Mentions,
+		"updated_at": note.UpdatedAt,
+	}}
+	result, err := repo.Collection.UpdateOne(context.TODO(), bson.M{"note_id": note.ID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update note: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (repo *NoteRepo) DeleteNote(noteID string) error {
+	result, err := repo.Collection.DeleteOne(context.TODO(), bson.M{"note_id": noteID})
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
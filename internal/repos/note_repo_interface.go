@@ -0,0 +1,12 @@
+package repos
+
+import "jobs-svc/internal/models"
+
+type NoteRepoInterface interface {
+	CreateNote(note *models.Note) error
+	GetNoteByID(noteID string) (*models.Note, error)
+	GetNotesByApplicationID(applicationID string, viewerID uint) ([]models.Note, error)
+	UpdateNote(note *models.Note) error
+	DeleteNote(noteID string) error
+	CreateIndexes() error
+}
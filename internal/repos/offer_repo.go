@@ -0,0 +1,118 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"jobs-svc/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OfferRepo struct {
+	Collection *mongo.Collection
+}
+
+// openOfferStatuses are the statuses of an offer still in progress
+var openOfferStatuses = []string{models.OfferStatusDraft, models.OfferStatusPendingApproval, models.OfferStatusExtended}
+
+// CreateIndexes creates a unique index on offer_id, the index offers are listed by, the
+// index the expiry sweeper searches and a unique index on application_id over offers in
+// progress, so an application cannot get two open offers however close together they
+// are created
+func (repo *OfferRepo) CreateIndexes() error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "offer_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "application_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "application_id", Value: 1}},
+			Options: options.Index().
+				SetName("application_id_1_open").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": bson.M{"$in": openOfferStatuses}}),
+		},
+	}
+	_, err := repo.Collection.Indexes().CreateMany(context.TODO(), indexes)
+	return err
+}
+
+// CreateOffer inserts an offer, failing with ErrOpenOfferExists when its application
+// already has an offer in progress
+func (repo *OfferRepo) CreateOffer(offer *models.Offer) error {
+	_, err := repo.Collection.InsertOne(context.TODO(), offer)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrOpenOfferExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert offer: %v", err)
+	}
+	return nil
+}
+
+func (repo *OfferRepo) GetOfferByID(offerID string) (*models.Offer, error) {
+	var offer models.Offer
+	err := repo.Collection.FindOne(context.TODO(), bson.M{"offer_id": offerID}).Decode(&offer)
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// GetOffersByApplicationID returns an application's offers, oldest first
+func (repo *OfferRepo) GetOffersByApplicationID(applicationID string) ([]models.Offer, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	return repo.findOffers(bson.M{"application_id": applicationID}, opts)
+}
+
+// FindExpiredOffers returns the extended offers whose expiry is not after now
+func (repo *OfferRepo) FindExpiredOffers(now time.Time) ([]models.Offer, error) {
+	filter := bson.M{
+		"status":     models.OfferStatusExtended,
+		"expires_at": bson.M{"$lte": now},
+	}
+	return repo.findOffers(filter, options.Find().SetSort(bson.D{{Key: "expires_at", Value: 1}}))
+}
+
+// UpdateOffer saves an offer's terms, status and history. The update only matches while
+// the stored offer is still in status from, so of two concurrent changes only one
+// succeeds; the other gets ErrOfferConflict.
+func (repo *OfferRepo) UpdateOffer(offer *models.Offer, from string) error {
+	filter := bson.M{
+		"offer_id": offer.ID,
+		"status":   from,
+	}
+	update := bson.M{"$set": bson.M{
+		"salary":     offer.Salary,
+		"currency":   offer.Currency,
+		"start_date": offer.StartDate,
+		"expires_at": offer.ExpiresAt,
+		"status":     offer.Status,
+		"history":    offer.History,
+		"updated_at": offer.UpdatedAt,
+	}}
+	result, err := repo.Collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update offer: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrOfferConflict
+	}
+	return nil
+}
+
+func (repo *OfferRepo) findOffers(filter bson.M, opts *options.FindOptions) ([]models.Offer, error) {
+	cursor, err := repo.Collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find offers: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	offers := make([]models.Offer, 0)
+	if err = cursor.All(context.TODO(), &offers); err != nil {
+		return nil, fmt.Errorf("failed to decode offers: %v", err)
+	}
+	return offers, nil
+}
@@ -0,0 +1,24 @@
+package repos
+
+import (
+	"errors"
+	"time"
+
+	"jobs-svc/internal/models"
+)
+
+var (
+	ErrOfferConflict = errors.New("offer status was changed by another request")
+	// ErrOpenOfferExists is returned by CreateOffer when the application already has an
+	// offer in progress
+	ErrOpenOfferExists = errors.New("application already has an open offer")
+)
+
+type OfferRepoInterface interface {
+	CreateOffer(offer *models.Offer) error
+	GetOfferByID(offerID string) (*models.Offer, error)
+	GetOffersByApplicationID(applicationID string) ([]models.Offer, error)
+	FindExpiredOffers(now time.Time) ([]models.Offer, error)
+	UpdateOffer(offer *models.Offer, from string) error
+	CreateIndexes() error
+}
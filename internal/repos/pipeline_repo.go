@@ -0,0 +1,96 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"jobs-svc/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PipelineRepo struct {
+	Collection *mongo.Collection
+}
+
+// CreateUniqueIndex creates a unique compound index on company_id and name so a
+// company cannot define two pipelines with the same name
+func (repo *PipelineRepo) CreateUniqueIndex() error {
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "company_id", Value: 1},
+			{Key: "name", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := repo.Collection.Indexes().CreateOne(context.TODO(), indexModel)
+	return err
+}
+
+func (repo *PipelineRepo) CreatePipeline(pipeline *models.Pipeline) error {
+	_, err := repo.Collection.InsertOne(context.TODO(), pipeline)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicatePipeline
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert pipeline: %v", err)
+	}
+	return nil
+}
+
+func (repo *PipelineRepo) GetPipelineByID(pipelineID string) (*models.Pipeline, error) {
+	var pipeline models.Pipeline
+	err := repo.Collection.FindOne(context.TODO(), bson.M{"pipeline_id": pipelineID}).Decode(&pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// GetPipelinesByCompanyID returns a company's pipelines ordered by name
+func (repo *PipelineRepo) GetPipelinesByCompanyID(companyID uint) ([]models.Pipeline, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := repo.Collection.Find(context.TODO(), bson.M{"company_id": companyID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pipelines: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	pipelines := make([]models.Pipeline, 0)
+	if err = cursor.All(context.TODO(), &pipelines); err != nil {
+		return nil, fmt.Errorf("failed to decode pipelines: %v", err)
+	}
+	return pipelines, nil
+}
+
+// UpdatePipeline replaces a pipeline's name and stages
+func (repo *PipelineRepo) UpdatePipeline(pipeline *models.Pipeline) error {
+	update := bson.M{"$set": bson.M{
+		"name":       pipeline.Name,
+		"stages":     pipeline.Stages,
+		"updated_at": pipeline.UpdatedAt,
+	}}
+	result, err := repo.Collection.UpdateOne(context.TODO(), bson.M{"pipeline_id": pipeline.ID}, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicatePipeline
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update pipeline: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (repo *PipelineRepo) DeletePipeline(pipelineID string) error {
+	result, err := repo.Collection.DeleteOne(context.TODO(), bson.M{"pipeline_id": pipelineID})
+	if err != nil {
+		return fmt.Errorf("failed to delete pipeline: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
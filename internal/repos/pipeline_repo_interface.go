@@ -0,0 +1,18 @@
+package repos
+
+import (
+	"errors"
+
+	"jobs-svc/internal/models"
+)
+
+var ErrDuplicatePipeline = errors.New("company already has a pipeline with this name")
+
+type PipelineRepoInterface interface {
+	CreatePipeline(pipeline *models.Pipeline) error
+	GetPipelineByID(pipelineID string) (*models.Pipeline, error)
+	GetPipelinesByCompanyID(companyID uint) ([]models.Pipeline, error)
+	UpdatePipeline(pipeline *models.Pipeline) error
+	DeletePipeline(pipelineID string) error
+	CreateUniqueIndex() error
+}
@@ -0,0 +1,75 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"jobs-svc/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RejectionReasonRepo struct {
+	Collection *mongo.Collection
+}
+
+// CreateUniqueIndex creates a unique compound index on company_id and code so each
+// code appears once in a company's catalog
+func (repo *RejectionReasonRepo) CreateUniqueIndex() error {
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "company_id", Value: 1},
+			{Key: "code", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := repo.Collection.Indexes().CreateOne(context.TODO(), indexModel)
+	return err
+}
+
+func (repo *RejectionReasonRepo) CreateReason(reason *models.RejectionReason) error {
+	_, err := repo.Collection.InsertOne(context.TODO(), reason)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicateRejectionReason
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert rejection reason: %v", err)
+	}
+	return nil
+}
+
+// GetReasonsByCompanyID returns a company's catalog in the order the reasons were added
+func (repo *RejectionReasonRepo) GetReasonsByCompanyID(companyID uint) ([]models.RejectionReason, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := repo.Collection.Find(context.TODO(), bson.M{"company_id": companyID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find rejection reasons: %v", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	reasons := make([]models.RejectionReason, 0)
+	if err = cursor.All(context.TODO(), &reasons); err != nil {
+		return nil, fmt.Errorf("failed to decode rejection reasons: %v", err)
+	}
+	return reasons, nil
+}
+
+// UpdateReason saves a reason's label, description and archived flag
+func (repo *RejectionReasonRepo) UpdateReason(reason *models.RejectionReason) error {
+	filter := bson.M{"company_id": reason.CompanyID, "code": reason.Code}
+	update := bson.M{"$set": bson.M{
+		"label":       reason.Label,
+		"description": reason.Description,
+		"archived":    reason.Archived,
+		"updated_at":  reason.UpdatedAt,
+	}}
+	result, err := repo.Collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update rejection reason: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
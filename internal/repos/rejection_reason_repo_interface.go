@@ -0,0 +1,16 @@
+package repos
+
+import (
+	"errors"
+
+	"jobs-svc/internal/models"
+)
+
+var ErrDuplicateRejectionReason = errors.New("company already has a rejection reason with this code")
+
+type RejectionReasonRepoInterface interface {
+	CreateReason(reason *models.RejectionReason) error
+	GetReasonsByCompanyID(companyID uint) ([]models.RejectionReason, error)
+	UpdateReason(reason *models.RejectionReason) error
+	CreateUniqueIndex() error
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+)
+
+var ErrAnalyticsForbidden = errors.New("report covers another company")
+
+// AnalyticsService reports on how applications move through hiring pipelines
+type AnalyticsService struct {
+	AnalyticsRepo repos.AnalyticsRepoInterface
+	JobRepo       repos.JobRepoInterface
+	// PipelineRepo resolves the pipelines stages belong to; without it every job uses the default pipeline
+	PipelineRepo repos.PipelineRepoInterface
+	// ReasonRepo labels rejection reasons from companies' catalogs; without it the default labels are used
+	ReasonRepo repos.RejectionReasonRepoInterface
+}
+
+// GetJobFunnel reports the funnel of a job owned by the user's company, laid out along the job's pipeline
+func (s *AnalyticsService) GetJobFunnel(user *clients.UserResponse, jobID uint, filter models.FunnelFilter) (*models.FunnelReport, error) {
+	job, err := findCompanyJob(s.JobRepo, user, jobID)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := pipelineForJob(s.PipelineRepo, job)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.JobID = job.ID
+	filter.CompanyID = job.CompanyID
+	groups, err := s.AnalyticsRepo.GetStageVisits(filter)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewFunnelReport(filter, []models.Pipeline{pipeline}, groups), nil
+}
+
+// GetCompanyFunnel reports the funnel across all jobs of the user's company. Stages are
+// laid out along the default pipeline followed by the company's own pipelines.
+func (s *AnalyticsService) GetCompanyFunnel(user *clients.UserResponse, companyID uint, filter models.FunnelFilter) (*models.FunnelReport, error) {
+	userCompanyID, err := clients.GetCompanyID(user)
+	if err != nil || uint(userCompanyID) != companyID {
+		return nil, ErrAnalyticsForbidden
+	}
+
+	pipelines := []models.Pipeline{models.DefaultPipeline}
+	if s.PipelineRepo != nil {
+		companyPipelines, err := s.PipelineRepo.GetPipelinesByCompanyID(companyID)
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, companyPipelines...)
+	}
+
+	filter.JobID = 0
+	filter.CompanyID = companyID
+	groups, err := s.AnalyticsRepo.GetStageVisits(filter)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewFunnelReport(filter, pipelines, groups), nil
+}
+
+// GetJobRejections reports the reasons the applications of a job owned by the user's company were rejected for
+func (s *AnalyticsService) GetJobRejections(user *clients.UserResponse, jobID uint, filter models.FunnelFilter) (*models.RejectionReport, error) {
+	job, err := findCompanyJob(s.JobRepo, user, jobID)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := rejectionCatalog(s.ReasonRepo, job.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.JobID = job.ID
+	filter.CompanyID = job.CompanyID
+	counts, err := s.AnalyticsRepo.GetRejectionReasons(filter)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewRejectionReport(filter, catalog, counts), nil
+}
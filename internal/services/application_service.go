@@ -1,36 +1,514 @@
 package services
 
 import (
+	"errors"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/models"
 	"jobs-svc/internal/repos"
+	"slices"
+	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ApplicationsService struct {
 	AppRepo repos.ApplicationRepoInterface
+	JobRepo repos.JobRepoInterface
+	// PipelineRepo resolves the pipelines jobs reference; applications follow the default pipeline when nil
+	PipelineRepo repos.PipelineRepoInterface
+	// ReapplyCooldown is how long after withdrawing a candidate must wait to apply to the same job again
+	ReapplyCooldown time.Duration
+	// ReasonRepo holds companies' rejection reason catalogs; rejections use DefaultRejectionReasons when nil
+	ReasonRepo repos.RejectionReasonRepoInterface
+	// ResumeLinks signs the download links of uploaded resumes; without it their ResumeURL is left empty
+	ResumeLinks *ResumeLinks
+	// Matcher scores new applications against their job in the background; nil skips scoring
+	Matcher *ResumeMatcher
 }
 
-func (s *ApplicationsService) CreateApplication(app bson.M) error {
-	return s.AppRepo.CreateApplication(app)
+// DefaultReapplyCooldown is the cooldown used when APPLICATION_REAPPLY_COOLDOWN_DAYS is not set
+const DefaultReapplyCooldown = 30 * 24 * time.Hour
+
+var (
+	ErrJobNotOpen             = errors.New("job is not open for applications")
+	ErrApplicationNotFound    = errors.New("application not found")
+	ErrUnknownStage           = errors.New("stage is not part of the pipeline")
+	ErrInvalidStageTransition = errors.New("application stage transition not allowed")
+	ErrApplicationForbidden   = errors.New("application belongs to another candidate")
+	ErrApplicationClosed      = errors.New("application is already closed")
+	ErrReapplyCooldown        = errors.New("candidate withdrew from this job too recently to apply again")
+	ErrNoScorecardTemplate    = errors.New("job has no scorecard template")
+	ErrWithdrawnStage         = errors.New("only the candidate can move an application to a withdrawn stage")
+)
+
+// CreateApplication validates an application and stores it in the first stage of its
+// job's pipeline if the job exists and is open. The job's company is stamped onto the
+// application so it can be scoped later.
+func (s *ApplicationsService) CreateApplication(app *models.Application) error {
+	if err := app.Validate(); err != nil {
+		return err
+	}
+
+	job, err := findJob(s.JobRepo, uint(app.JobID))
+	if err != nil {
+		return err
+	}
+	if job.Status != models.Open {
+		return ErrJobNotOpen
+	}
+	pipeline, err := pipelineForJob(s.PipelineRepo, job)
+	if err != nil {
+		return err
+	}
+	if err := s.checkReapply(uint(app.CandidateID), uint(app.JobID)); err != nil {
+		return err
+	}
+
+	if app.ApplicationID == "" {
+		app.ApplicationID = primitive.NewObjectID().Hex()
+	}
+	app.Status = models.Status{CurrentStage: pipeline.InitialStage(), LastUpdated: time.Now()}
+	app.StageHistory = []models.StageChange{{
+		To:        app.Status.CurrentStage,
+		ChangedBy: uint(app.CandidateID),
+		ChangedAt: app.Status.LastUpdated,
+	}}
+	app.CompanyID = models.NumericID(job.CompanyID)
+	app.AppliedAt = app.Status.LastUpdated
+	app.Active = true
+	app.WithdrawnAt = nil
+	// tags, ratings, scorecards and dispositions come from the hiring team, not the candidate
+	app.Tags = nil
+	app.Rating = nil
+	app.Scorecards = nil
+	app.Disposition = nil
+	// uploaded resumes are attached through UploadResume once the application exists
+	app.Resume = nil
+	app.Match = nil
+	if err := s.AppRepo.CreateApplication(app); err != nil {
+		return err
+	}
+	s.Matcher.Enqueue(app.ApplicationID)
+	return nil
+}
+
+// checkReapply rejects a new application while the candidate has an active one for
+// the job, or withdrew from it less than ReapplyCooldown ago
+func (s *ApplicationsService) checkReapply(candidateID uint, jobID uint) error {
+	previous, err := s.AppRepo.GetLatestApplication(candidateID, jobID)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return nil
+	}
+	if previous.Active {
+		return repos.ErrDuplicateApplication
+	}
+	if previous.WithdrawnAt != nil && time.Since(*previous.WithdrawnAt) < s.ReapplyCooldown {
+		return ErrReapplyCooldown
+	}
+	return nil
+}
+
+// WithdrawApplication lets a candidate retract their own application. It moves to the
+// pipeline's withdrawn stage from any stage that is not terminal.
+func (s *ApplicationsService) WithdrawApplication(user *clients.UserResponse, applicationID string, reason string) (*models.Application, error) {
+	app, err := findApplication(s.AppRepo, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || uint(user.ID) != uint(app.CandidateID) {
+		return nil, ErrApplicationForbidden
+	}
+
+	// a deleted job's applications are already closed, but fall back to the default
+	// pipeline rather than failing if one slipped through
+	pipeline := models.DefaultPipeline
+	job, err := findJob(s.JobRepo, uint(app.JobID))
+	switch {
+	case err == nil:
+		if pipeline, err = pipelineForJob(s.PipelineRepo, job); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, ErrJobNotFound):
+		return nil, err
+	}
+	if !app.Active || pipeline.IsTerminal(app.Status.CurrentStage) {
+		return nil, ErrApplicationClosed
+	}
+
+	change := models.StageChange{
+		From:      app.Status.CurrentStage,
+		To:        pipeline.WithdrawnStage(),
+		ChangedBy: uint(user.ID),
+		ChangedAt: time.Now(),
+		Reason:    reason,
+	}
+	return s.AppRepo.WithdrawApplication(applicationID, change)
+}
+
+// MoveStage moves an application to another stage of its job's pipeline on behalf of
+// a member of the company that owns the job, recording the change in the stage history.
+// Moving to a stage of kind rejected needs a disposition with a reason from the
+// company's catalog; other moves cannot have one. Stages of kind withdrawn are
+// reached only through WithdrawApplication.
+func (s *ApplicationsService) MoveStage(user *clients.UserResponse, applicationID string, stage string, reason string, disposition *models.Disposition) (*models.Application, error) {
+	app, job, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline, err := pipelineForJob(s.PipelineRepo, job)
+	if err != nil {
+		return nil, err
+	}
+	target, ok := pipeline.Stage(stage)
+	if !ok {
+		return nil, ErrUnknownStage
+	}
+	if target.Kind == models.StageKindWithdrawn {
+		return nil, ErrWithdrawnStage
+	}
+	if !pipeline.CanTransition(app.Status.CurrentStage, target.Name) {
+		return nil, ErrInvalidStageTransition
+	}
+
+	change := models.StageChange{
+		From:      app.Status.CurrentStage,
+		To:        target.Name,
+		ChangedBy: uint(user.ID),
+		ChangedAt: time.Now(),
+		Reason:    reason,
+	}
+	if target.Kind != models.StageKindRejected {
+		if disposition != nil {
+			return nil, &models.ValidationError{Message: "a disposition can only be given when rejecting an application"}
+		}
+		return s.AppRepo.UpdateStage(applicationID, change)
+	}
+
+	if disposition == nil {
+		return nil, ErrRejectionReasonRequired
+	}
+	if err := disposition.Validate(); err != nil {
+		return nil, err
+	}
+	rejection, err := findRejectionReason(s.ReasonRepo, job.CompanyID, disposition.ReasonCode)
+	if err != nil {
+		return nil, err
+	}
+	disposition.ReasonLabel = rejection.Label
+	disposition.DecidedBy = uint(user.ID)
+	disposition.DecidedAt = change.ChangedAt
+	return s.AppRepo.RejectApplication(applicationID, change, *disposition)
+}
+
+// BulkUpdate applies a bulk action to applications of the user's company, application
+// by application: one that cannot be changed fails on its own without holding back the
+// rest. It returns the outcome for every application and the applications it changed.
+func (s *ApplicationsService) BulkUpdate(user *clients.UserResponse, action *models.BulkAction) (*models.BulkActionResult, []models.Application, error) {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return nil, nil, ErrJobForbidden
+	}
+	if err := action.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if action.Action == models.BulkActionReject {
+		rejection, err := findRejectionReason(s.ReasonRepo, uint(companyID), action.Disposition.ReasonCode)
+		if err != nil {
+			return nil, nil, err
+		}
+		action.Disposition.ReasonLabel = rejection.Label
+		action.Disposition.DecidedBy = uint(user.ID)
+	}
+
+	found, err := s.AppRepo.GetApplicationsByIDs(action.ApplicationIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	apps := make(map[string]*models.Application, len(found))
+	for i := range found {
+		apps[found[i].ApplicationID] = &found[i]
+	}
+
+	// pipelines are looked up once per job; a job's error fails all of its applications
+	type jobPipeline struct {
+		pipeline models.Pipeline
+		err      error
+	}
+	pipelines := make(map[uint]jobPipeline)
+	pipelineFor := func(jobID uint) (models.Pipeline, error) {
+		if cached, ok := pipelines[jobID]; ok {
+			return cached.pipeline, cached.err
+		}
+		var cached jobPipeline
+		job, err := findCompanyJob(s.JobRepo, user, jobID)
+		if err == nil {
+			cached.pipeline, err = pipelineForJob(s.PipelineRepo, job)
+		}
+		cached.err = err
+		pipelines[jobID] = cached
+		return cached.pipeline, cached.err
+	}
+
+	now := time.Now()
+	failures := make(map[string]error)
+	updates := make([]models.ApplicationUpdate, 0, len(action.ApplicationIDs))
+	for _, id := range action.ApplicationIDs {
+		app, ok := apps[id]
+		if !ok {
+			failures[id] = ErrApplicationNotFound
+			continue
+		}
+		pipeline, err := pipelineFor(uint(app.JobID))
+		if err != nil {
+			failures[id] = err
+			continue
+		}
+		update, err := bulkUpdateFor(app, pipeline, action, uint(user.ID), now)
+		if err != nil {
+			failures[id] = err
+			continue
+		}
+		if update != nil {
+			updates = append(updates, *update)
+		}
+	}
+
+	applied := make(map[string]*models.Application)
+	if len(updates) > 0 {
+		if applied, err = s.AppRepo.BulkUpdateApplications(updates); err != nil {
+			return nil, nil, err
+		}
+	}
+	pending := make(map[string]bool, len(updates))
+	for _, update := range updates {
+		pending[update.ApplicationID] = true
+	}
+
+	result := &models.BulkActionResult{Action: action.Action, Results: make([]models.BulkItemResult, 0, len(action.ApplicationIDs))}
+	changed := make([]models.Application, 0, len(applied))
+	for _, id := range action.ApplicationIDs {
+		item := models.BulkItemResult{ApplicationID: id, Status: models.BulkItemFailed}
+		switch app, ok := applied[id]; {
+		case failures[id] != nil:
+			item.Error = failures[id].Error()
+		case ok:
+			item.Status = models.BulkItemUpdated
+			changed = append(changed, *app)
+		case pending[id]:
+			item.Error = repos.ErrStageConflict.Error()
+		default:
+			item.Status = models.BulkItemUnchanged
+		}
+		result.Add(item)
+	}
+	return result, changed, nil
+}
+
+// bulkUpdateFor works out what a bulk action changes on one application. It returns nil
+// when the application is already as the action would leave it.
+func bulkUpdateFor(app *models.Application, pipeline models.Pipeline, action *models.BulkAction, changedBy uint, now time.Time) (*models.ApplicationUpdate, error) {
+	update := &models.ApplicationUpdate{ApplicationID: app.ApplicationID}
+	switch action.Action {
+	case models.BulkActionAddTags:
+		for _, tag := range action.Tags {
+			if !slices.Contains(app.Tags, tag) {
+				update.AddTags = append(update.AddTags, tag)
+			}
+		}
+		if len(update.AddTags) == 0 {
+			return nil, nil
+		}
+		return update, nil
+	case models.BulkActionRemoveTags:
+		for _, tag := range action.Tags {
+			if slices.Contains(app.Tags, tag) {
+				update.RemoveTags = append(update.RemoveTags, tag)
+			}
+		}
+		if len(update.RemoveTags) == 0 {
+			return nil, nil
+		}
+		return update, nil
+	}
+
+	var stage string
+	if action.Action == models.BulkActionReject {
+		rejected, ok := pipeline.RejectedStage()
+		if !ok {
+			return nil, ErrUnknownStage
+		}
+		stage = rejected
+		disposition := *action.Disposition
+		disposition.DecidedAt = now
+		update.Disposition = &disposition
+	} else {
+		target, ok := pipeline.Stage(action.Stage)
+		if !ok {
+			return nil, ErrUnknownStage
+		}
+		switch target.Kind {
+		case models.StageKindRejected:
+			return nil, ErrRejectionReasonRequired
+		case models.StageKindWithdrawn:
+			return nil, ErrWithdrawnStage
+		}
+		stage = target.Name
+	}
+	if !pipeline.CanTransition(app.Status.CurrentStage, stage) {
+		return nil, ErrInvalidStageTransition
+	}
+	update.Change = &models.StageChange{
+		From:      app.Status.CurrentStage,
+		To:        stage,
+		ChangedBy: changedBy,
+		ChangedAt: now,
+		Reason:    action.Reason,
+	}
+	return update, nil
 }
 
-func (s *ApplicationsService) GetApplicationsByJobID(jobID uint) ([]bson.M, error) {
-	return s.AppRepo.GetApplicationsByJobID(jobID)
+// SubmitScorecard records the user's scorecard for an application of their company,
+// scored against the job's template. An interviewer who submits again replaces their
+// earlier scorecard.
+func (s *ApplicationsService) SubmitScorecard(user *clients.UserResponse, applicationID string, scorecard *models.Scorecard) (*models.ScorecardSummary, error) {
+	_, job, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Scorecard == nil {
+		return nil, ErrNoScorecardTemplate
+	}
+	if err := scorecard.Score(job.Scorecard); err != nil {
+		return nil, err
+	}
+
+	scorecard.InterviewerID = uint(user.ID)
+	scorecard.SubmittedAt = time.Now()
+	app, err := s.AppRepo.SubmitScorecard(applicationID, *scorecard)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrApplicationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return models.NewScorecardSummary(app), nil
 }
 
-func (s *ApplicationsService) GetApplicationByID(applicationID string) (bson.M, error) {
+// GetScorecards returns the rating and scorecards of an application of the user's company
+func (s *ApplicationsService) GetScorecards(user *clients.UserResponse, applicationID string) (*models.ScorecardSummary, error) {
+	app, _, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewScorecardSummary(app), nil
+}
+
+// GetApplicationsByJobID lists a page of a job's applications for a member of the company that owns the job
+func (s *ApplicationsService) GetApplicationsByJobID(user *clients.UserResponse, jobID uint, opts models.ApplicationQueryOptions) (*models.ApplicationPage, error) {
+	if _, err := findCompanyJob(s.JobRepo, user, jobID); err != nil {
+		return nil, err
+	}
+	opts.Normalize()
+	applications, err := s.AppRepo.GetApplicationsByJobID(jobID, opts)
+	if err != nil {
+		return nil, err
+	}
+	page := models.NewApplicationPage(applications, opts)
+	now := time.Now()
+	for i := range page.Items {
+		s.ResumeLinks.Sign(&page.Items[i], now)
+	}
+	return page, nil
+}
+
+// GetJobBoard groups a job's applications by stage for a member of the company that owns the job
+func (s *ApplicationsService) GetJobBoard(user *clients.UserResponse, jobID uint, cardsPerStage int) (*models.JobBoard, error) {
+	job, err := findCompanyJob(s.JobRepo, user, jobID)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := pipelineForJob(s.PipelineRepo, job)
+	if err != nil {
+		return nil, err
+	}
+
+	if cardsPerStage < 1 {
+		cardsPerStage = models.DefaultBoardCardLimit
+	}
+	if cardsPerStage > models.MaxBoardCardLimit {
+		cardsPerStage = models.MaxBoardCardLimit
+	}
+	columns, err := s.AppRepo.GetJobBoard(jobID, cardsPerStage)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewJobBoard(job.ID, pipeline, columns, cardsPerStage), nil
+}
+
+// GetApplicationByID, GetApplicationByCandidateID and GetApplicationsByCandidateID back
+// unauthenticated routes, so their resume links are never signed
+func (s *ApplicationsService) GetApplicationByID(applicationID string) (*models.Application, error) {
 	return s.AppRepo.GetApplicationByID(applicationID)
 }
 
-func (s *ApplicationsService) GetApplicationByCandidateID(candidateID uint) (bson.M, error) {
+func (s *ApplicationsService) GetApplicationByCandidateID(candidateID uint) (*models.Application, error) {
 	return s.AppRepo.GetApplicationByCandidateID(candidateID)
 }
 
-func (s *ApplicationsService) GetApplicationsByCandidateID(candidateID uint) ([]bson.M, error) {
+func (s *ApplicationsService) GetApplicationsByCandidateID(candidateID uint) ([]models.Application, error) {
 	return s.AppRepo.GetApplicationsByCandidateID(candidateID)
 }
 
+// findApplication loads an application and maps a missing document to ErrApplicationNotFound
+func findApplication(repo repos.ApplicationRepoInterface, applicationID string) (*models.Application, error) {
+	app, err := repo.GetApplicationByID(applicationID)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && app == nil) {
+		return nil, ErrApplicationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// findCompanyApplication loads an application and its job, checking that the job
+// belongs to the user's company
+func findCompanyApplication(appRepo repos.ApplicationRepoInterface, jobRepo repos.JobRepoInterface, user *clients.UserResponse, applicationID string) (*models.Application, *models.Job, error) {
+	app, err := findApplication(appRepo, applicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	job, err := findCompanyJob(jobRepo, user, uint(app.JobID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return app, job, nil
+}
+
+// checkApplicationOpen fails with ErrApplicationClosed for withdrawn applications and
+// applications in a terminal stage of their job's pipeline
+func checkApplicationOpen(pipelineRepo repos.PipelineRepoInterface, app *models.Application, job *models.Job) (models.Pipeline, error) {
+	pipeline, err := pipelineForJob(pipelineRepo, job)
+	if err != nil {
+		return pipeline, err
+	}
+	if !app.Active || pipeline.IsTerminal(app.Status.CurrentStage) {
+		return pipeline, ErrApplicationClosed
+	}
+	return pipeline, nil
+}
+
 // CreateUniqueIndex creates a unique compound index on candidate_id and job_id
 func (s *ApplicationsService) CreateUniqueIndex() error {
 	return s.AppRepo.CreateUniqueIndex()
 }
+
+// CreateListingIndexes creates the indexes behind paged application listings
+func (s *ApplicationsService) CreateListingIndexes() error {
+	return s.AppRepo.CreateListingIndexes()
+}
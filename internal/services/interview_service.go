@@ -0,0 +1,299 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"log"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrInterviewNotFound      = errors.New("interview not found")
+	ErrInterviewForbidden     = errors.New("interview belongs to another company")
+	ErrInterviewCancelled     = errors.New("interview has been cancelled")
+	ErrInterviewerUnavailable = errors.New("interviewer already has an interview at that time")
+	ErrNotInterviewStage      = errors.New("application has not reached an interview stage")
+)
+
+// InterviewService schedules interviews for applications. An interviewer cannot be
+// booked into two scheduled interviews that overlap: each interview holds a slot in its
+// interviewers' schedules, taken with a write that fails if the time is already held.
+type InterviewService struct {
+	InterviewRepo repos.InterviewRepoInterface
+	AppRepo       repos.ApplicationRepoInterface
+	JobRepo       repos.JobRepoInterface
+	// PipelineRepo resolves the job's pipeline to tell whether an application is closed
+	PipelineRepo repos.PipelineRepoInterface
+}
+
+// ScheduleInterview books an interview for an open application of the user's company
+// that is in, or has been through, an interview stage of the job's pipeline
+func (s *InterviewService) ScheduleInterview(user *clients.UserResponse, applicationID string, interview *models.Interview) error {
+	app, job, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID)
+	if err != nil {
+		return err
+	}
+	pipeline, err := checkApplicationOpen(s.PipelineRepo, app, job)
+	if err != nil {
+		return err
+	}
+	if !reachedInterviewStage(pipeline, app) {
+		return ErrNotInterviewStage
+	}
+	if err := interview.Validate(); err != nil {
+		return err
+	}
+	interview.ID = ""
+	if err := s.checkConflicts(interview); err != nil {
+		return err
+	}
+
+	interview.ID = primitive.NewObjectID().Hex()
+	hold, err := s.bookInterviewers(interview, "")
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	interview.SlotHold = hold
+	interview.ApplicationID = app.ApplicationID
+	interview.JobID = job.ID
+	interview.JobTitle = job.Title
+	interview.CompanyID = job.CompanyID
+	interview.CandidateID = uint(app.CandidateID)
+	interview.CandidateEmail = app.Email
+	interview.Status = models.InterviewStatusScheduled
+	interview.Sequence = 0
+	interview.CreatedBy = uint(user.ID)
+	interview.CreatedAt = now
+	interview.UpdatedAt = now
+	if err := s.InterviewRepo.CreateInterview(interview); err != nil {
+		s.releaseInterviewers(interview.ID, interview.InterviewerIDs, "")
+		return err
+	}
+	return nil
+}
+
+// GetInterviews lists the interviews of an application of the user's company, earliest first
+func (s *InterviewService) GetInterviews(user *clients.UserResponse, applicationID string) ([]models.Interview, error) {
+	app, _, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	interviews, err := s.InterviewRepo.GetInterviewsByApplicationID(app.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if interviews == nil {
+		interviews = make([]models.Interview, 0)
+	}
+	return interviews, nil
+}
+
+func (s *InterviewService) GetInterview(user *clients.UserResponse, interviewID string) (*models.Interview, error) {
+	return s.findCompanyInterview(user, interviewID)
+}
+
+// RescheduleInterview replaces the slot, interviewers and place of a scheduled interview
+func (s *InterviewService) RescheduleInterview(user *clients.UserResponse, interviewID string, update *models.Interview) (*models.Interview, error) {
+	interview, err := s.findCompanyInterview(user, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	if interview.Status == models.InterviewStatusCancelled {
+		return nil, ErrInterviewCancelled
+	}
+	app, job, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, interview.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := checkApplicationOpen(s.PipelineRepo, app, job); err != nil {
+		return nil, err
+	}
+
+	update.ID = interview.ID
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkConflicts(update); err != nil {
+		return nil, err
+	}
+	// the new slots are held alongside the old ones until the interview is saved
+	previousHold := interview.SlotHold
+	hold, err := s.bookInterviewers(update, previousHold)
+	if err != nil {
+		return nil, err
+	}
+
+	previousInterviewers := interview.InterviewerIDs
+	interview.Title = update.Title
+	interview.InterviewerIDs = update.InterviewerIDs
+	interview.StartsAt = update.StartsAt
+	interview.EndsAt = update.EndsAt
+	interview.Location = update.Location
+	interview.VideoLink = update.VideoLink
+	interview.SlotHold = hold
+	saved, err := s.saveInterview(interview)
+	if err != nil {
+		s.releaseInterviewers(interview.ID, update.InterviewerIDs, previousHold)
+		return nil, err
+	}
+	interviewers := slices.Clone(update.InterviewerIDs)
+	for _, id := range previousInterviewers {
+		if !slices.Contains(interviewers, id) {
+			interviewers = append(interviewers, id)
+		}
+	}
+	s.releaseInterviewers(interview.ID, interviewers, hold)
+	return saved, nil
+}
+
+// CancelInterview cancels a scheduled interview, freeing its interviewers' time
+func (s *InterviewService) CancelInterview(user *clients.UserResponse, interviewID string) (*models.Interview, error) {
+	interview, err := s.findCompanyInterview(user, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	if interview.Status == models.InterviewStatusCancelled {
+		return nil, ErrInterviewCancelled
+	}
+	interview.Status = models.InterviewStatusCancelled
+	saved, err := s.saveInterview(interview)
+	if err != nil {
+		return nil, err
+	}
+	s.releaseInterviewers(interview.ID, interview.InterviewerIDs, "")
+	return saved, nil
+}
+
+// GetInterviewCalendar renders an interview as an iCalendar file for members of the
+// company, its interviewers and the candidate
+func (s *InterviewService) GetInterviewCalendar(user *clients.UserResponse, interviewID string) ([]byte, error) {
+	interview, err := findInterview(s.InterviewRepo, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInterviewForbidden
+	}
+	companyID, companyErr := clients.GetCompanyID(user)
+	member := companyErr == nil && uint(companyID) == interview.CompanyID
+	attendee := uint(user.ID) == interview.CandidateID || slices.Contains(interview.InterviewerIDs, uint(user.ID))
+	if !member && !attendee {
+		return nil, ErrInterviewForbidden
+	}
+	return interview.ICalendar(time.Now()), nil
+}
+
+// saveInterview bumps the interview's sequence so calendar clients pick up the change
+func (s *InterviewService) saveInterview(interview *models.Interview) (*models.Interview, error) {
+	interview.Sequence++
+	interview.UpdatedAt = time.Now()
+	if err := s.InterviewRepo.UpdateInterview(interview); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInterviewNotFound
+		}
+		return nil, err
+	}
+	return interview, nil
+}
+
+// checkConflicts fails with ErrInterviewerUnavailable when one of the interview's
+// interviewers is already booked during its slot
+func (s *InterviewService) checkConflicts(interview *models.Interview) error {
+	conflicts, err := s.InterviewRepo.FindConflicts(interview.InterviewerIDs, interview.StartsAt, interview.EndsAt, interview.ID)
+	if err != nil {
+		return err
+	}
+	for _, conflict := range conflicts {
+		for _, id := range interview.InterviewerIDs {
+			if slices.Contains(conflict.InterviewerIDs, id) {
+				return fmt.Errorf("%w: interviewer %d is booked from %s to %s", ErrInterviewerUnavailable, id,
+					conflict.StartsAt.Format(time.RFC3339), conflict.EndsAt.Format(time.RFC3339))
+			}
+		}
+	}
+	return nil
+}
+
+// bookInterviewers holds the interview's slot for each of its interviewers under a new
+// hold and returns it. If one of them is booked, the slots taken so far are released,
+// leaving those of keepHold, and ErrInterviewerUnavailable is returned. Interviewers are
+// booked in ID order so two bookings of the same people cannot each lock the other out.
+func (s *InterviewService) bookInterviewers(interview *models.Interview, keepHold string) (string, error) {
+	slot := models.InterviewSlot{
+		InterviewID: interview.ID,
+		Hold:        primitive.NewObjectID().Hex(),
+		StartsAt:    interview.StartsAt,
+		EndsAt:      interview.EndsAt,
+	}
+	interviewers := slices.Clone(interview.InterviewerIDs)
+	slices.Sort(interviewers)
+	for i, id := range interviewers {
+		err := s.InterviewRepo.BookInterviewer(id, slot)
+		if err == nil {
+			continue
+		}
+		s.releaseInterviewers(interview.ID, interviewers[:i], keepHold)
+		if errors.Is(err, repos.ErrInterviewerBooked) {
+			return "", fmt.Errorf("%w: interviewer %d is booked during that time", ErrInterviewerUnavailable, id)
+		}
+		return "", err
+	}
+	return slot.Hold, nil
+}
+
+// releaseInterviewers frees the interview's slots other than those of keepHold. A slot
+// left behind only blocks time, so failures are logged rather than returned.
+func (s *InterviewService) releaseInterviewers(interviewID string, interviewerIDs []uint, keepHold string) {
+	for _, id := range interviewerIDs {
+		if err := s.InterviewRepo.ReleaseInterviewer(id, interviewID, keepHold); err != nil {
+			log.Printf("Failed to release interviewer %d from interview %s: %v", id, interviewID, err)
+		}
+	}
+}
+
+// reachedInterviewStage reports whether the application is in an interview stage of
+// the pipeline or has moved through one on its way to its current stage
+func reachedInterviewStage(pipeline models.Pipeline, app *models.Application) bool {
+	if pipeline.IsInterviewStage(app.Status.CurrentStage) {
+		return true
+	}
+	for _, change := range app.StageHistory {
+		if pipeline.IsInterviewStage(change.To) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *InterviewService) findCompanyInterview(user *clients.UserResponse, interviewID string) (*models.Interview, error) {
+	interview, err := findInterview(s.InterviewRepo, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil || uint(companyID) != interview.CompanyID {
+		return nil, ErrInterviewForbidden
+	}
+	return interview, nil
+}
+
+// findInterview loads an interview and maps a missing document to ErrInterviewNotFound
+func findInterview(repo repos.InterviewRepoInterface, interviewID string) (*models.Interview, error) {
+	interview, err := repo.GetInterviewByID(interviewID)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && interview == nil) {
+		return nil, ErrInterviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return interview, nil
+}
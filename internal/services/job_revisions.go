@@ -0,0 +1,159 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/models"
+	"log"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+var ErrJobRevisionNotFound = errors.New("job revision not found")
+
+// revisionIgnoredFields change on every save and are left out of revision diffs
+var revisionIgnoredFields = map[string]bool{"version": true, "UpdatedAt": true}
+
+// recordRevision stores a snapshot of job at its current version. History is
+// best-effort: the job is already saved, so a failed write is only logged.
+func (s *JobService) recordRevision(user *clients.UserResponse, job *models.Job) {
+	if s.RevisionRepo == nil {
+		return
+	}
+
+	snapshot, err := json.Marshal(job)
+	if err != nil {
+		log.Printf("Failed to snapshot job %d: %v", job.ID, err)
+		return
+	}
+
+	revision := &models.JobRevision{
+		JobID:    job.ID,
+		Revision: job.Version,
+		Snapshot: snapshot,
+	}
+	if user != nil {
+		revision.EditorID = uint(user.ID)
+	}
+	if err := s.RevisionRepo.CreateRevision(revision); err != nil {
+		log.Printf("Failed to record revision %d of job %d: %v", job.Version, job.ID, err)
+	}
+}
+
+// GetJobRevisions lists the revisions of a job owned by the user's company, newest first
+func (s *JobService) GetJobRevisions(user *clients.UserResponse, id uint) ([]models.JobRevision, error) {
+	if _, err := findCompanyJob(s.JobRepo, user, id); err != nil {
+		return nil, err
+	}
+
+	revisions := make([]models.JobRevision, 0)
+	if s.RevisionRepo == nil {
+		return revisions, nil
+	}
+	stored, err := s.RevisionRepo.GetRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		revisions = *stored
+	}
+	return revisions, nil
+}
+
+// DiffJobRevision compares a revision with the one before it. The first recorded
+// revision, including that of a job created before history was kept, is compared
+// with an empty job, so every field shows as added and From is 0.
+func (s *JobService) DiffJobRevision(user *clients.UserResponse, id uint, revision uint) (*models.JobRevisionDiff, error) {
+	if _, err := findCompanyJob(s.JobRepo, user, id); err != nil {
+		return nil, err
+	}
+
+	current, err := s.findRevision(id, revision)
+	if err != nil {
+		return nil, err
+	}
+	after, err := snapshotObject(current)
+	if err != nil {
+		return nil, err
+	}
+
+	before := map[string]interface{}{}
+	from := uint(0)
+	if revision > 1 {
+		previous, err := s.findRevision(id, revision-1)
+		switch {
+		case errors.Is(err, ErrJobRevisionNotFound):
+			// the job predates revision history, so there is nothing earlier to compare with
+		case err != nil:
+			return nil, err
+		default:
+			if before, err = snapshotObject(previous); err != nil {
+				return nil, err
+			}
+			from = revision - 1
+		}
+	}
+
+	return &models.JobRevisionDiff{
+		JobID:     id,
+		From:      from,
+		To:        revision,
+		EditorID:  current.EditorID,
+		CreatedAt: current.CreatedAt,
+		Changes:   diffJobFields(before, after),
+	}, nil
+}
+
+func (s *JobService) findRevision(id uint, revision uint) (*models.JobRevision, error) {
+	if s.RevisionRepo == nil || revision == 0 {
+		return nil, ErrJobRevisionNotFound
+	}
+	found, err := s.RevisionRepo.GetRevision(id, revision)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && found == nil) {
+		return nil, ErrJobRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+func snapshotObject(revision *models.JobRevision) (map[string]interface{}, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(revision.Snapshot, &object); err != nil {
+		return nil, fmt.Errorf("invalid snapshot for revision %d: %w", revision.Revision, err)
+	}
+	return object, nil
+}
+
+// diffJobFields returns the top-level fields that differ between two job snapshots, sorted by name
+func diffJobFields(before, after map[string]interface{}) []models.JobFieldChange {
+	fields := make(map[string]bool)
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		if !revisionIgnoredFields[field] {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]models.JobFieldChange, 0)
+	for _, field := range names {
+		oldValue, newValue := before[field], after[field]
+		if sameJSONValue(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, models.JobFieldChange{Field: field, Old: oldValue, New: newValue})
+	}
+	return changes
+}
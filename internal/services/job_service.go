@@ -1,38 +1,455 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jobs-svc/internal/clients"
 	"jobs-svc/internal/models"
 	"jobs-svc/internal/repos"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrJobNotFound             = errors.New("job not found")
+	ErrInvalidInitialStatus    = errors.New("new jobs must start as draft or open")
+	ErrInvalidStatusTransition = errors.New("job status transition not allowed")
+	ErrJobForbidden            = errors.New("job belongs to another company")
+	ErrInvalidPatch            = errors.New("invalid merge patch")
 )
 
+// immutableJobFields are the job JSON fields a merge patch may not change
+var immutableJobFields = []string{"ID", "companyId", "CreatedAt", "DeletedAt", "version"}
+
+// ImmutableFieldError reports an attempt to change a field that cannot be patched
+type ImmutableFieldError struct {
+	Field string
+}
+
+func (e *ImmutableFieldError) Error() string {
+	return fmt.Sprintf("field %s is immutable", e.Field)
+}
+
 type JobService struct {
 	JobRepo repos.JobRepoInterface
+	// RevisionRepo keeps the job's edit history; revisions are not recorded when nil
+	RevisionRepo repos.JobRevisionRepoInterface
+	// PurgeRetention is how long soft-deleted jobs are kept before they can be purged
+	PurgeRetention time.Duration
+	// AppRepo is used to close a job's applications when the job closes or is deleted
+	AppRepo repos.ApplicationRepoInterface
+	// PipelineRepo resolves the pipelines jobs reference; jobs can only use the default pipeline when nil
+	PipelineRepo repos.PipelineRepoInterface
 }
 
-func (s *JobService) CreateJob(job *models.Job) error {
-	return s.JobRepo.CreateJob(job)
+// DefaultJobPurgeRetention is used when PurgeRetention is not set
+const DefaultJobPurgeRetention = 30 * 24 * time.Hour
+
+// CreateJob saves a new job and records it as revision 1, edited by user
+func (s *JobService) CreateJob(user *clients.UserResponse, job *models.Job) error {
+	if job.Status != models.Open && job.Status != models.Draft {
+		return ErrInvalidInitialStatus
+	}
+	if job.Status == models.Open && job.PostedDate.IsZero() {
+		job.PostedDate = time.Now()
+	}
+	if err := s.checkPipeline(job, nil); err != nil {
+		return err
+	}
+	if err := checkScorecard(job); err != nil {
+		return err
+	}
+	job.Version = 1
+	if err := s.JobRepo.CreateJob(job); err != nil {
+		return err
+	}
+	s.recordRevision(user, job)
+	return nil
 }
 
 func (s *JobService) GetJobByID(id uint) (*models.Job, error) {
 	return s.JobRepo.GetJobByID(id)
 }
 
-func (s *JobService) GetJobs() (*[]models.Job, error) {
-	return s.JobRepo.GetJobs()
+func (s *JobService) GetJobs(opts models.JobQueryOptions) (*models.JobPage, error) {
+	opts.Normalize()
+	jobs, total, err := s.JobRepo.GetJobs(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.Job, 0)
+	if jobs != nil {
+		items = *jobs
+	}
+	return models.NewJobPage(items, total, opts), nil
+}
+
+func (s *JobService) SearchJobs(query string, opts models.JobQueryOptions) (*models.JobSearchPage, error) {
+	query = strings.TrimSpace(query)
+	opts.Normalize()
+	results, total, err := s.JobRepo.SearchJobs(query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.JobSearchResult, 0)
+	if results != nil {
+		items = *results
+	}
+	return models.NewJobSearchPage(query, items, total, opts), nil
 }
 
 func (s *JobService) GetJobsByRecruiterID(recruiterID uint) (*[]models.Job, error) {
 	return s.JobRepo.GetJobsByRecruiterID(recruiterID)
 }
 
-func (s *JobService) UpdateJob(job *models.Job) error {
-	return s.JobRepo.UpdateJob(job)
+// UpdateJob replaces a job owned by the user's company. Ownership, creation time and,
+// when the body leaves them out, the status, posted date and pipeline are kept from
+// the stored job. status is nil when the body has no status.
+// expectedVersion comes from If-Match; 0 matches any version.
+func (s *JobService) UpdateJob(user *clients.UserResponse, job *models.Job, status *models.JobStatus, expectedVersion uint) error {
+	existing, err := findCompanyJob(s.JobRepo, user, job.ID)
+	if err != nil {
+		return err
+	}
+	if expectedVersion != 0 && existing.Version != expectedVersion {
+		return repos.ErrJobVersionConflict
+	}
+	job.Status = existing.Status
+	if status != nil {
+		job.Status = *status
+	}
+	if job.Status != existing.Status && !existing.Status.CanTransitionTo(job.Status) {
+		return ErrInvalidStatusTransition
+	}
+	if job.PostedDate.IsZero() {
+		job.PostedDate = existing.PostedDate
+	}
+	// a draft goes live when it is first published
+	if existing.Status == models.Draft && job.Status == models.Open {
+		job.PostedDate = time.Now()
+	}
+	if job.PipelineID == "" {
+		job.PipelineID = existing.PipelineID
+	}
+	if err := s.checkPipeline(job, existing); err != nil {
+		return err
+	}
+	if job.Scorecard == nil {
+		job.Scorecard = existing.Scorecard
+	}
+	if err := checkScorecard(job); err != nil {
+		return err
+	}
+
+	job.CompanyID = existing.CompanyID
+	job.CreatedAt = existing.CreatedAt
+	job.Version = existing.Version
+	if err := s.JobRepo.UpdateJob(job); err != nil {
+		return err
+	}
+	s.recordRevision(user, job)
+	if job.Status != existing.Status {
+		s.closeApplicationsIfClosed(job)
+	}
+	return nil
 }
 
-func (s *JobService) DeleteJob(id uint) error {
-	return s.JobRepo.DeleteJob(id)
+// PatchJob applies an RFC 7396 merge patch to a job owned by the user's company.
+// It reports whether the patch changed anything; unchanged jobs are not saved.
+func (s *JobService) PatchJob(user *clients.UserResponse, id uint, patch []byte, expectedVersion uint) (*models.Job, bool, error) {
+	existing, err := findCompanyJob(s.JobRepo, user, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if expectedVersion != 0 && existing.Version != expectedVersion {
+		return nil, false, repos.ErrJobVersionConflict
+	}
+
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	patchObject, ok := patchDoc.(map[string]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf("%w: patch must be a JSON object", ErrInvalidPatch)
+	}
+
+	original, err := toJSONObject(existing)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, field := range immutableJobFields {
+		patchKey, value, present := lookupField(patchObject, field)
+		if !present {
+			continue
+		}
+		_, current, _ := lookupField(original, field)
+		if !sameJSONValue(value, current) {
+			return nil, false, &ImmutableFieldError{Field: patchKey}
+		}
+	}
+
+	target, err := toJSONObject(existing)
+	if err != nil {
+		return nil, false, err
+	}
+	merged := applyMergePatch(target, patchObject)
+	if sameJSONValue(merged, original) {
+		return existing, false, nil
+	}
+
+	mergedBytes, err := json.Marshal(merged)
+	if err != nil {
+		return nil, false, err
+	}
+	var job models.Job
+	if err := json.Unmarshal(mergedBytes, &job); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	if job.Status != existing.Status && !existing.Status.CanTransitionTo(job.Status) {
+		return nil, false, ErrInvalidStatusTransition
+	}
+	// a draft goes live when it is first published
+	if existing.Status == models.Draft && job.Status == models.Open {
+		job.PostedDate = time.Now()
+	}
+	if err := s.checkPipeline(&job, existing); err != nil {
+		return nil, false, err
+	}
+	if err := checkScorecard(&job); err != nil {
+		return nil, false, err
+	}
+
+	job.ID = existing.ID
+	job.CompanyID = existing.CompanyID
+	job.CreatedAt = existing.CreatedAt
+	job.Version = existing.Version
+	if err := s.JobRepo.UpdateJob(&job); err != nil {
+		return nil, false, err
+	}
+	s.recordRevision(user, &job)
+	if job.Status != existing.Status {
+		s.closeApplicationsIfClosed(&job)
+	}
+	return &job, true, nil
+}
+
+// TransitionJob moves a job to the target status if its lifecycle allows it
+func (s *JobService) TransitionJob(user *clients.UserResponse, id uint, target models.JobStatus) (*models.Job, error) {
+	job, err := findCompanyJob(s.JobRepo, user, id)
+	if err != nil {
+		return nil, err
+	}
+	if !job.Status.CanTransitionTo(target) {
+		return nil, ErrInvalidStatusTransition
+	}
+
+	// a draft goes live when it is first published
+	if job.Status == models.Draft && target == models.Open {
+		job.PostedDate = time.Now()
+	}
+	job.Status = target
+	if err := s.JobRepo.UpdateJob(job); err != nil {
+		return nil, err
+	}
+	s.recordRevision(user, job)
+	s.closeApplicationsIfClosed(job)
+	return job, nil
+}
+
+// DeleteJob soft-deletes a job owned by the user's company and returns it
+func (s *JobService) DeleteJob(user *clients.UserResponse, id uint) (*models.Job, error) {
+	job, err := findCompanyJob(s.JobRepo, user, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.JobRepo.DeleteJob(id); err != nil {
+		return nil, err
+	}
+	s.closeApplications(job, "job deleted")
+	return job, nil
+}
+
+// GetDeletedJobs lists the soft-deleted jobs of the user's company
+func (s *JobService) GetDeletedJobs(user *clients.UserResponse) ([]models.Job, error) {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return nil, ErrJobForbidden
+	}
+
+	jobs, err := s.JobRepo.GetDeletedJobs(uint(companyID))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.Job, 0)
+	if jobs != nil {
+		items = *jobs
+	}
+	return items, nil
+}
+
+// RestoreJob undoes the soft delete of a job owned by the user's company
+func (s *JobService) RestoreJob(user *clients.UserResponse, id uint) (*models.Job, error) {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return nil, ErrJobForbidden
+	}
+
+	job, err := s.JobRepo.GetDeletedJobByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && job == nil) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if job.CompanyID != uint(companyID) {
+		return nil, ErrJobForbidden
+	}
+
+	if err := s.JobRepo.RestoreJob(id); err != nil {
+		return nil, err
+	}
+	job.DeletedAt = gorm.DeletedAt{}
+	job.Version++
+	s.recordRevision(user, job)
+	return job, nil
+}
+
+// PurgeDeletedJobs permanently removes jobs that were soft-deleted longer ago than the retention
+func (s *JobService) PurgeDeletedJobs() ([]models.Job, error) {
+	jobs, err := s.JobRepo.PurgeDeletedJobs(time.Now().Add(-s.purgeRetention()))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.Job, 0)
+	if jobs != nil {
+		items = *jobs
+	}
+	return items, nil
+}
+
+func (s *JobService) purgeRetention() time.Duration {
+	if s.PurgeRetention <= 0 {
+		return DefaultJobPurgeRetention
+	}
+	return s.PurgeRetention
 }
 
 func (s *JobService) GetJobsByIDs(ids []uint) (*[]models.Job, error) {
 	return s.JobRepo.GetJobsByIDs(ids)
 }
+
+// closeApplicationsIfClosed closes the job's applications if the job has reached a closed status
+func (s *JobService) closeApplicationsIfClosed(job *models.Job) {
+	if job.Status.IsClosed() {
+		s.closeApplications(job, "job "+job.Status.String())
+	}
+}
+
+// closeApplications moves a job's open applications to the Job Closed stage.
+// Postgres and Mongo cannot share a transaction, so the job change stands and a
+// failed cascade is only logged.
+func (s *JobService) closeApplications(job *models.Job, reason string) {
+	if s.AppRepo == nil {
+		return
+	}
+	pipeline, err := pipelineForJob(s.PipelineRepo, job)
+	if err != nil {
+		log.Printf("Failed to load pipeline %s for job %d, using the default: %v", job.PipelineID, job.ID, err)
+		pipeline = models.DefaultPipeline
+	}
+	closed, err := s.AppRepo.CloseApplicationsForJob(job.ID, pipeline.TerminalStages(), reason)
+	if err != nil {
+		log.Printf("Failed to close applications for job %d: %v", job.ID, err)
+		return
+	}
+	if closed > 0 {
+		log.Printf("Closed %d applications for job %d: %s", closed, job.ID, reason)
+	}
+}
+
+// checkPipeline verifies that the pipeline a job references belongs to the job's company.
+// existing is the stored job when updating; its pipeline can only change while it is a draft.
+func (s *JobService) checkPipeline(job *models.Job, existing *models.Job) error {
+	if existing != nil && job.PipelineID == existing.PipelineID {
+		return nil
+	}
+	if existing != nil && existing.Status != models.Draft {
+		return ErrJobPipelineLocked
+	}
+	if job.PipelineID == "" {
+		return nil
+	}
+
+	pipeline, err := findPipeline(s.PipelineRepo, job.PipelineID)
+	if errors.Is(err, ErrPipelineNotFound) {
+		return ErrUnknownPipeline
+	}
+	if err != nil {
+		return err
+	}
+	companyID := job.CompanyID
+	if existing != nil {
+		companyID = existing.CompanyID
+	}
+	if pipeline.CompanyID != companyID {
+		return ErrUnknownPipeline
+	}
+	return nil
+}
+
+// checkScorecard validates the job's scorecard template, if it has one
+func checkScorecard(job *models.Job) error {
+	if job.Scorecard == nil {
+		return nil
+	}
+	return job.Scorecard.Validate()
+}
+
+// findJob loads a job and maps a missing row to ErrJobNotFound
+func findJob(repo repos.JobRepoInterface, id uint) (*models.Job, error) {
+	job, err := repo.GetJobByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && job == nil) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// findCompanyJob loads a job and checks that it belongs to the user's company
+func findCompanyJob(repo repos.JobRepoInterface, user *clients.UserResponse, id uint) (*models.Job, error) {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return nil, ErrJobForbidden
+	}
+
+	job, err := findJob(repo, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.CompanyID != uint(companyID) {
+		return nil, ErrJobForbidden
+	}
+	return job, nil
+}
+
+// toJSONObject round-trips v through encoding/json into a generic object
+func toJSONObject(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// applyMergePatch applies an RFC 7396 JSON merge patch to target. Both values are
+// expected to be decoded JSON (maps, slices and scalars from encoding/json).
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		// non-object patches replace the target wholesale
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = applyMergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// lookupField finds key in a decoded JSON object the way encoding/json matches
+// struct fields: exact match first, then case-insensitively
+func lookupField(object map[string]interface{}, key string) (string, interface{}, bool) {
+	if value, ok := object[key]; ok {
+		return key, value, true
+	}
+	for name, value := range object {
+		if strings.EqualFold(name, key) {
+			return name, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// sameJSONValue compares two decoded JSON values, treating timestamps that
+// denote the same instant as equal
+func sameJSONValue(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	aString, aOK := a.(string)
+	bString, bOK := b.(string)
+	if !aOK || !bOK {
+		return false
+	}
+	aTime, aErr := time.Parse(time.RFC3339Nano, aString)
+	bTime, bErr := time.Parse(time.RFC3339Nano, bString)
+	return aErr == nil && bErr == nil && aTime.Equal(bTime)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrNoteNotFound  = errors.New("note not found")
+	ErrNoteForbidden = errors.New("only the author can change a note")
+)
+
+// NoteService manages the notes hiring teams leave on applications. Notes can be
+// read by the company that owns the application's job, except private notes, which
+// only their author sees.
+type NoteService struct {
+	NoteRepo repos.NoteRepoInterface
+	AppRepo  repos.ApplicationRepoInterface
+	JobRepo  repos.JobRepoInterface
+}
+
+// CreateNote adds a note by the user to an application of their company
+func (s *NoteService) CreateNote(user *clients.UserResponse, applicationID string, note *models.Note) error {
+	app, job, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID)
+	if err != nil {
+		return err
+	}
+
+	note.ApplicationID = app.ApplicationID
+	note.JobID = job.ID
+	note.CompanyID = job.CompanyID
+	note.AuthorID = uint(user.ID)
+	if err := note.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	note.ID = primitive.NewObjectID().Hex()
+	note.CreatedAt = now
+	note.UpdatedAt = now
+	return s.NoteRepo.CreateNote(note)
+}
+
+// GetNotes lists the notes on an application the user can read, oldest first
+func (s *NoteService) GetNotes(user *clients.UserResponse, applicationID string) ([]models.Note, error) {
+	app, _, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.NoteRepo.GetNotesByApplicationID(app.ApplicationID, uint(user.ID))
+	if err != nil {
+		return nil, err
+	}
+	if notes == nil {
+		notes = make([]models.Note, 0)
+	}
+	return notes, nil
+}
+
+// UpdateNote replaces the body and visibility of one of the user's notes. It also
+// returns the users the note mentions that it did not mention before.
+func (s *NoteService) UpdateNote(user *clients.UserResponse, applicationID string, noteID string, update *models.Note) (*models.Note, []uint, error) {
+	note, err := s.findAuthoredNote(user, applicationID, noteID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	previous := note.Mentions
+	note.Body = update.Body
+	note.Visibility = update.Visibility
+	if err := note.Validate(); err != nil {
+		return nil, nil, err
+	}
+	note.UpdatedAt = time.Now()
+	if err := s.NoteRepo.UpdateNote(note); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, ErrNoteNotFound
+		}
+		return nil, nil, err
+	}
+
+	var added []uint
+	for _, userID := range note.Mentions {
+		if !slices.Contains(previous, userID) {
+			added = append(added, userID)
+		}
+	}
+	return note, added, nil
+}
+
+// DeleteNote deletes one of the user's notes
+func (s *NoteService) DeleteNote(user *clients.UserResponse, applicationID string, noteID string) error {
+	note, err := s.findAuthoredNote(user, applicationID, noteID)
+	if err != nil {
+		return err
+	}
+	if err := s.NoteRepo.DeleteNote(note.ID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrNoteNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// findAuthoredNote loads a note on an application of the user's company. Notes the
+// user cannot read are not found; notes by someone else are forbidden.
+func (s *NoteService) findAuthoredNote(user *clients.UserResponse, applicationID string, noteID string) (*models.Note, error) {
+	if _, _, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID); err != nil {
+		return nil, err
+	}
+
+	note, err := s.NoteRepo.GetNoteByID(noteID)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && note == nil) {
+		return nil, ErrNoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if note.ApplicationID != applicationID || !note.VisibleTo(uint(user.ID)) {
+		return nil, ErrNoteNotFound
+	}
+	if note.AuthorID != uint(user.ID) {
+		return nil, ErrNoteForbidden
+	}
+	return note, nil
+}
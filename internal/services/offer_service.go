@@ -0,0 +1,355 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrOfferNotFound          = errors.New("offer not found")
+	ErrOfferForbidden         = errors.New("offer belongs to another company or candidate")
+	ErrOfferExists            = errors.New("application already has an open offer")
+	ErrOfferNotEditable       = errors.New("only draft and pending offers can be edited")
+	ErrOfferExpired           = errors.New("offer has expired")
+	ErrInvalidOfferTransition = errors.New("offer status transition not allowed")
+)
+
+// DefaultOfferSweepInterval is how often expired offers are swept when OFFER_SWEEP_INTERVAL_MINUTES is not set
+const DefaultOfferSweepInterval = 5 * time.Minute
+
+// OfferService manages the offers made on applications. Extending, accepting and
+// declining an offer move its application to the matching stage of the job's pipeline.
+type OfferService struct {
+	OfferRepo    repos.OfferRepoInterface
+	AppRepo      repos.ApplicationRepoInterface
+	JobRepo      repos.JobRepoInterface
+	PipelineRepo repos.PipelineRepoInterface
+}
+
+// CreateOffer drafts an offer for an open application of the user's company. An
+// application has at most one offer in progress at a time.
+func (s *OfferService) CreateOffer(user *clients.UserResponse, applicationID string, offer *models.Offer) error {
+	app, job, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID)
+	if err != nil {
+		return err
+	}
+	if _, err := checkApplicationOpen(s.PipelineRepo, app, job); err != nil {
+		return err
+	}
+	offers, err := s.OfferRepo.GetOffersByApplicationID(app.ApplicationID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range offers {
+		if existing.IsOpen() {
+			return ErrOfferExists
+		}
+	}
+
+	now := time.Now()
+	if err := offer.Validate(now); err != nil {
+		return err
+	}
+	offer.ID = primitive.NewObjectID().Hex()
+	offer.ApplicationID = app.ApplicationID
+	offer.JobID = job.ID
+	offer.CompanyID = job.CompanyID
+	offer.CandidateID = uint(app.CandidateID)
+	offer.Status = ""
+	offer.History = nil
+	offer.SetStatus(models.OfferStatusDraft, uint(user.ID), now, "")
+	offer.CreatedBy = uint(user.ID)
+	offer.CreatedAt = now
+	// the check above gives a quick answer; the repo enforces the rule against
+	// concurrent requests
+	if err := s.OfferRepo.CreateOffer(offer); err != nil {
+		if errors.Is(err, repos.ErrOpenOfferExists) {
+			return ErrOfferExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetOffers lists the offers of an application of the user's company, oldest first
+func (s *OfferService) GetOffers(user *clients.UserResponse, applicationID string) ([]models.Offer, error) {
+	app, _, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	offers, err := s.OfferRepo.GetOffersByApplicationID(app.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if offers == nil {
+		offers = make([]models.Offer, 0)
+	}
+	return offers, nil
+}
+
+// GetOffer returns an offer to a member of its company, or to its candidate once it has
+// been extended
+func (s *OfferService) GetOffer(user *clients.UserResponse, offerID string) (*models.Offer, error) {
+	offer, err := findOffer(s.OfferRepo, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrOfferForbidden
+	}
+	if companyID, err := clients.GetCompanyID(user); err == nil && uint(companyID) == offer.CompanyID {
+		return offer, nil
+	}
+	if uint(user.ID) != offer.CandidateID {
+		return nil, ErrOfferForbidden
+	}
+	if offer.Status == models.OfferStatusDraft || offer.Status == models.OfferStatusPendingApproval {
+		return nil, ErrOfferNotFound
+	}
+	return offer, nil
+}
+
+// UpdateOffer replaces the terms of a draft or pending offer. A pending offer goes back
+// to draft and has to be submitted for approval again.
+func (s *OfferService) UpdateOffer(user *clients.UserResponse, offerID string, update *models.Offer) (*models.Offer, error) {
+	offer, err := s.findCompanyOffer(user, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.Status != models.OfferStatusDraft && offer.Status != models.OfferStatusPendingApproval {
+		return nil, ErrOfferNotEditable
+	}
+
+	now := time.Now()
+	if err := update.Validate(now); err != nil {
+		return nil, err
+	}
+	from := offer.Status
+	offer.Salary = update.Salary
+	offer.Currency = update.Currency
+	offer.StartDate = update.StartDate
+	offer.ExpiresAt = update.ExpiresAt
+	if from == models.OfferStatusPendingApproval {
+		offer.SetStatus(models.OfferStatusDraft, uint(user.ID), now, "Terms changed")
+	} else {
+		offer.UpdatedAt = now
+	}
+	if err := s.OfferRepo.UpdateOffer(offer, from); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// SubmitOffer asks for a draft offer to be approved
+func (s *OfferService) SubmitOffer(user *clients.UserResponse, offerID string) (*models.Offer, error) {
+	offer, err := s.findCompanyOffer(user, offerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transition(offer, models.OfferStatusPendingApproval, uint(user.ID), "")
+}
+
+// ExtendOffer approves a pending offer and extends it to the candidate, moving the
+// application to the Offer stage when the job's pipeline has one. It returns the offer
+// with the moved application, which is nil when the application stayed where it was.
+func (s *OfferService) ExtendOffer(user *clients.UserResponse, offerID string) (*models.Offer, *models.Application, error) {
+	offer, err := s.findCompanyOffer(user, offerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !offer.ExpiresAt.After(time.Now()) {
+		return nil, nil, ErrOfferExpired
+	}
+	app, job, err := findCompanyApplication(s.AppRepo, s.JobRepo, user, offer.ApplicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pipeline, err := checkApplicationOpen(s.PipelineRepo, app, job)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stage, _ := pipeline.Stage(models.StageOffer)
+	return s.transitionWithApplication(offer, models.OfferStatusExtended, uint(user.ID), "", app, stage.Name, "Offer extended", false)
+}
+
+// AcceptOffer lets the candidate accept an extended offer that has not expired, moving
+// the application to the pipeline's hired stage. It returns the offer with the moved
+// application, which is nil when the application stayed where it was.
+func (s *OfferService) AcceptOffer(user *clients.UserResponse, offerID string) (*models.Offer, *models.Application, error) {
+	offer, app, pipeline, err := s.findCandidateOffer(user, offerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	stage, _ := pipeline.HiredStage()
+	return s.transitionWithApplication(offer, models.OfferStatusAccepted, uint(user.ID), "", app, stage, "Offer accepted", false)
+}
+
+// DeclineOffer lets the candidate decline an extended offer and returns it with the
+// application, which is withdrawn so the candidate can apply to the job again after the
+// reapply cooldown
+func (s *OfferService) DeclineOffer(user *clients.UserResponse, offerID string, reason string) (*models.Offer, *models.Application, error) {
+	offer, app, pipeline, err := s.findCandidateOffer(user, offerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	stageReason := "Offer declined"
+	if reason != "" {
+		stageReason += ": " + reason
+	}
+	return s.transitionWithApplication(offer, models.OfferStatusDeclined, uint(user.ID), reason, app, pipeline.WithdrawnStage(), stageReason, true)
+}
+
+// ExpireOffers marks the extended offers whose expiry has passed as expired and returns
+// them. Their applications stay in the Offer stage so a new offer can be made. Offers
+// answered while the sweep runs are left alone.
+func (s *OfferService) ExpireOffers(now time.Time) ([]models.Offer, error) {
+	offers, err := s.OfferRepo.FindExpiredOffers(now)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := make([]models.Offer, 0, len(offers))
+	for i := range offers {
+		offer := &offers[i]
+		offer.SetStatus(models.OfferStatusExpired, 0, now, "Offer expired")
+		err := s.OfferRepo.UpdateOffer(offer, models.OfferStatusExtended)
+		if errors.Is(err, repos.ErrOfferConflict) {
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired = append(expired, *offer)
+	}
+	return expired, nil
+}
+
+// transition moves an offer to status to if its current status allows it
+func (s *OfferService) transition(offer *models.Offer, to string, changedBy uint, reason string) (*models.Offer, error) {
+	if !offer.CanTransition(to) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidOfferTransition, offer.Status, to)
+	}
+	from := offer.Status
+	offer.SetStatus(to, changedBy, time.Now(), reason)
+	if err := s.OfferRepo.UpdateOffer(offer, from); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// transitionWithApplication moves an offer to status to along with its application,
+// which goes to stage, or stays put when stage is empty. The application is moved
+// first; if the offer cannot be saved afterwards the move is reverted, so neither
+// changes unless both do. It returns the offer and the moved application, which is nil
+// when the application stayed where it was.
+func (s *OfferService) transitionWithApplication(offer *models.Offer, to string, changedBy uint, reason string,
+	app *models.Application, stage string, stageReason string, withdraw bool) (*models.Offer, *models.Application, error) {
+	if !offer.CanTransition(to) {
+		return nil, nil, fmt.Errorf("%w: %s to %s", ErrInvalidOfferTransition, offer.Status, to)
+	}
+	moved, change, err := s.moveApplication(app, stage, changedBy, stageReason, withdraw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if offer, err = s.transition(offer, to, changedBy, reason); err != nil {
+		if change != nil {
+			if revertErr := s.AppRepo.RevertStage(app, *change); revertErr != nil {
+				log.Printf("Failed to revert application %s to %s after offer update failed: %v", app.ApplicationID, change.From, revertErr)
+			}
+		}
+		return nil, nil, err
+	}
+	return offer, moved, nil
+}
+
+// moveApplication moves an application to stage unless stage is empty or it is already
+// there, withdrawing it when withdraw is set. It returns the moved application and the
+// change made, both nil when it did not move.
+func (s *OfferService) moveApplication(app *models.Application, stage string, changedBy uint, reason string, withdraw bool) (*models.Application, *models.StageChange, error) {
+	if stage == "" || strings.EqualFold(app.Status.CurrentStage, stage) {
+		return nil, nil, nil
+	}
+	change := models.StageChange{
+		From:      app.Status.CurrentStage,
+		To:        stage,
+		ChangedBy: changedBy,
+		ChangedAt: time.Now(),
+		Reason:    reason,
+	}
+	var moved *models.Application
+	var err error
+	if withdraw {
+		moved, err = s.AppRepo.WithdrawApplication(app.ApplicationID, change)
+	} else {
+		moved, err = s.AppRepo.UpdateStage(app.ApplicationID, change)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return moved, &change, nil
+}
+
+func (s *OfferService) findCompanyOffer(user *clients.UserResponse, offerID string) (*models.Offer, error) {
+	offer, err := findOffer(s.OfferRepo, offerID)
+	if err != nil {
+		return nil, err
+	}
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil || uint(companyID) != offer.CompanyID {
+		return nil, ErrOfferForbidden
+	}
+	return offer, nil
+}
+
+// findCandidateOffer loads an offer extended to the user, with its open application and
+// the job's pipeline. Offers past their expiry fail with ErrOfferExpired even before the
+// sweeper has marked them.
+func (s *OfferService) findCandidateOffer(user *clients.UserResponse, offerID string) (*models.Offer, *models.Application, models.Pipeline, error) {
+	offer, err := s.GetOffer(user, offerID)
+	if err != nil {
+		return nil, nil, models.Pipeline{}, err
+	}
+	if uint(user.ID) != offer.CandidateID {
+		return nil, nil, models.Pipeline{}, ErrOfferForbidden
+	}
+	if offer.Status == models.OfferStatusExpired ||
+		(offer.Status == models.OfferStatusExtended && !offer.ExpiresAt.After(time.Now())) {
+		return nil, nil, models.Pipeline{}, ErrOfferExpired
+	}
+
+	app, err := findApplication(s.AppRepo, offer.ApplicationID)
+	if err != nil {
+		return nil, nil, models.Pipeline{}, err
+	}
+	job, err := findJob(s.JobRepo, offer.JobID)
+	if err != nil {
+		return nil, nil, models.Pipeline{}, err
+	}
+	pipeline, err := checkApplicationOpen(s.PipelineRepo, app, job)
+	if err != nil {
+		return nil, nil, models.Pipeline{}, err
+	}
+	return offer, app, pipeline, nil
+}
+
+// findOffer loads an offer and maps a missing document to ErrOfferNotFound
+func findOffer(repo repos.OfferRepoInterface, offerID string) (*models.Offer, error) {
+	offer, err := repo.GetOfferByID(offerID)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && offer == nil) {
+		return nil, ErrOfferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
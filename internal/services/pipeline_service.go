@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrPipelineNotFound  = errors.New("pipeline not found")
+	ErrPipelineForbidden = errors.New("pipeline belongs to another company")
+	ErrPipelineInUse     = errors.New("pipeline is used by jobs")
+	ErrUnknownPipeline   = errors.New("job references an unknown pipeline")
+	ErrJobPipelineLocked = errors.New("a job's pipeline can only be changed while the job is a draft")
+)
+
+// PipelineService manages the hiring pipelines companies define for their jobs
+type PipelineService struct {
+	PipelineRepo repos.PipelineRepoInterface
+	// JobRepo is used to find jobs that still use a pipeline
+	JobRepo repos.JobRepoInterface
+}
+
+// CreatePipeline validates a pipeline and stores it for the user's company
+func (s *PipelineService) CreatePipeline(user *clients.UserResponse, pipeline *models.Pipeline) error {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return ErrPipelineForbidden
+	}
+	if err := pipeline.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	pipeline.ID = primitive.NewObjectID().Hex()
+	pipeline.CompanyID = uint(companyID)
+	pipeline.CreatedBy = uint(user.ID)
+	pipeline.CreatedAt = now
+	pipeline.UpdatedAt = now
+	return s.PipelineRepo.CreatePipeline(pipeline)
+}
+
+// GetPipelines lists the pipelines of the user's company
+func (s *PipelineService) GetPipelines(user *clients.UserResponse) ([]models.Pipeline, error) {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return nil, ErrPipelineForbidden
+	}
+	pipelines, err := s.PipelineRepo.GetPipelinesByCompanyID(uint(companyID))
+	if err != nil {
+		return nil, err
+	}
+	if pipelines == nil {
+		pipelines = make([]models.Pipeline, 0)
+	}
+	return pipelines, nil
+}
+
+func (s *PipelineService) GetPipeline(user *clients.UserResponse, pipelineID string) (*models.Pipeline, error) {
+	return s.findCompanyPipeline(user, pipelineID)
+}
+
+// UpdatePipeline replaces the name and stages of a pipeline owned by the user's company.
+// While jobs use the pipeline its stages can be added to and rewired but not removed,
+// since applications may be sitting in them.
+func (s *PipelineService) UpdatePipeline(user *clients.UserResponse, pipelineID string, pipeline *models.Pipeline) (*models.Pipeline, error) {
+	existing, err := s.findCompanyPipeline(user, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+
+	inUse, err := s.inUse(existing)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		for _, stage := range existing.Stages {
+			if _, ok := pipeline.Stage(stage.Name); !ok {
+				return nil, fmt.Errorf("%w: stage %q cannot be removed", ErrPipelineInUse, stage.Name)
+			}
+		}
+	}
+
+	pipeline.ID = existing.ID
+	pipeline.CompanyID = existing.CompanyID
+	pipeline.CreatedBy = existing.CreatedBy
+	pipeline.CreatedAt = existing.CreatedAt
+	pipeline.UpdatedAt = time.Now()
+	if err := s.PipelineRepo.UpdatePipeline(pipeline); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+// DeletePipeline removes a pipeline owned by the user's company that no job uses
+func (s *PipelineService) DeletePipeline(user *clients.UserResponse, pipelineID string) error {
+	pipeline, err := s.findCompanyPipeline(user, pipelineID)
+	if err != nil {
+		return err
+	}
+	inUse, err := s.inUse(pipeline)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrPipelineInUse
+	}
+	return s.PipelineRepo.DeletePipeline(pipeline.ID)
+}
+
+// inUse reports whether any job, including a soft-deleted one that may yet be
+// restored, references the pipeline
+func (s *PipelineService) inUse(pipeline *models.Pipeline) (bool, error) {
+	total, err := s.JobRepo.CountJobsByPipeline(pipeline.ID)
+	if err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// findCompanyPipeline loads a pipeline and checks that it belongs to the user's company
+func (s *PipelineService) findCompanyPipeline(user *clients.UserResponse, pipelineID string) (*models.Pipeline, error) {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return nil, ErrPipelineForbidden
+	}
+	pipeline, err := findPipeline(s.PipelineRepo, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.CompanyID != uint(companyID) {
+		return nil, ErrPipelineForbidden
+	}
+	return pipeline, nil
+}
+
+// findPipeline loads a pipeline and maps a missing document to ErrPipelineNotFound
+func findPipeline(repo repos.PipelineRepoInterface, pipelineID string) (*models.Pipeline, error) {
+	if repo == nil || strings.TrimSpace(pipelineID) == "" {
+		return nil, ErrPipelineNotFound
+	}
+	pipeline, err := repo.GetPipelineByID(pipelineID)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && pipeline == nil) {
+		return nil, ErrPipelineNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+// pipelineForJob returns the pipeline a job's applications move through
+func pipelineForJob(repo repos.PipelineRepoInterface, job *models.Job) (models.Pipeline, error) {
+	if job.PipelineID == "" {
+		return models.DefaultPipeline, nil
+	}
+	pipeline, err := findPipeline(repo, job.PipelineID)
+	if err != nil {
+		return models.Pipeline{}, err
+	}
+	return *pipeline, nil
+}
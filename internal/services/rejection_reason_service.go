@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrRejectionReasonNotFound  = errors.New("rejection reason not found")
+	ErrRejectionReasonForbidden = errors.New("rejection reasons belong to an organization")
+	ErrRejectionReasonRequired  = errors.New("a rejection reason is required to move an application to a rejected stage")
+)
+
+// RejectionReasonService manages the catalog of reasons a company rejects candidates with
+type RejectionReasonService struct {
+	ReasonRepo repos.RejectionReasonRepoInterface
+}
+
+// GetReasons lists the catalog of the user's company, which is DefaultRejectionReasons
+// until the company changes it
+func (s *RejectionReasonService) GetReasons(user *clients.UserResponse) ([]models.RejectionReason, error) {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return nil, ErrRejectionReasonForbidden
+	}
+	return rejectionCatalog(s.ReasonRepo, uint(companyID))
+}
+
+// CreateReason adds a reason to the catalog of the user's company
+func (s *RejectionReasonService) CreateReason(user *clients.UserResponse, reason *models.RejectionReason) error {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return ErrRejectionReasonForbidden
+	}
+	if err := reason.Validate(); err != nil {
+		return err
+	}
+	if err := s.seedCatalog(uint(companyID)); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	reason.CompanyID = uint(companyID)
+	reason.Archived = false
+	reason.CreatedAt = now
+	reason.UpdatedAt = now
+	return s.ReasonRepo.CreateReason(reason)
+}
+
+// UpdateReason changes the label and description of a reason in the catalog of the
+// user's company, or archives it. Codes cannot change, since dispositions refer to them.
+func (s *RejectionReasonService) UpdateReason(user *clients.UserResponse, code string, update *models.RejectionReason) (*models.RejectionReason, error) {
+	companyID, err := clients.GetCompanyID(user)
+	if err != nil {
+		return nil, ErrRejectionReasonForbidden
+	}
+	update.Code = code
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.seedCatalog(uint(companyID)); err != nil {
+		return nil, err
+	}
+
+	update.CompanyID = uint(companyID)
+	update.UpdatedAt = time.Now()
+	err = s.ReasonRepo.UpdateReason(update)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrRejectionReasonNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reasons, err := s.ReasonRepo.GetReasonsByCompanyID(uint(companyID))
+	if err != nil {
+		return nil, err
+	}
+	for i := range reasons {
+		if reasons[i].Code == code {
+			return &reasons[i], nil
+		}
+	}
+	return nil, ErrRejectionReasonNotFound
+}
+
+// seedCatalog copies DefaultRejectionReasons into a company's catalog before its first
+// change, so the defaults stay available and can be archived like any other reason
+func (s *RejectionReasonService) seedCatalog(companyID uint) error {
+	reasons, err := s.ReasonRepo.GetReasonsByCompanyID(companyID)
+	if err != nil || len(reasons) > 0 {
+		return err
+	}
+	now := time.Now()
+	for _, reason := range models.DefaultRejectionReasons {
+		reason.CompanyID = companyID
+		reason.CreatedAt = now
+		reason.UpdatedAt = now
+		if err := s.ReasonRepo.CreateReason(&reason); err != nil && !errors.Is(err, repos.ErrDuplicateRejectionReason) {
+			return err
+		}
+	}
+	return nil
+}
+
+// rejectionCatalog returns a company's rejection reasons, or DefaultRejectionReasons
+// when it has none or no repository is configured
+func rejectionCatalog(repo repos.RejectionReasonRepoInterface, companyID uint) ([]models.RejectionReason, error) {
+	if repo != nil {
+		reasons, err := repo.GetReasonsByCompanyID(companyID)
+		if err != nil {
+			return nil, err
+		}
+		if len(reasons) > 0 {
+			return reasons, nil
+		}
+	}
+	defaults := make([]models.RejectionReason, len(models.DefaultRejectionReasons))
+	copy(defaults, models.DefaultRejectionReasons)
+	return defaults, nil
+}
+
+// findRejectionReason looks up a reason in a company's catalog that can be used for new rejections
+func findRejectionReason(repo repos.RejectionReasonRepoInterface, companyID uint, code string) (*models.RejectionReason, error) {
+	reasons, err := rejectionCatalog(repo, companyID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reasons {
+		if reasons[i].Code == code && !reasons[i].Archived {
+			return &reasons[i], nil
+		}
+	}
+	return nil, &models.ValidationError{Message: fmt.Sprintf("unknown rejection reason %q", code)}
+}
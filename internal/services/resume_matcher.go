@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"jobs-svc/internal/storage"
+	"log"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DefaultMatchQueueSize is how many applications can wait to be scored before new ones are dropped
+const DefaultMatchQueueSize = 256
+
+// ResumeMatcher scores applications against their job's skills and experience in the
+// background, so submitting an application or a resume never waits for it. Applications
+// are queued with Enqueue and scored one at a time by Run.
+type ResumeMatcher struct {
+	AppRepo repos.ApplicationRepoInterface
+	JobRepo repos.JobRepoInterface
+	Store   storage.BlobStore
+	// Queue holds the IDs of applications waiting to be scored
+	Queue chan string
+}
+
+// Enqueue queues an application for scoring without blocking. When the queue is full
+// the application is left unscored until it is queued again.
+func (m *ResumeMatcher) Enqueue(applicationID string) {
+	if m == nil || m.Queue == nil {
+		return
+	}
+	select {
+	case m.Queue <- applicationID:
+	default:
+		log.Printf("Match queue is full, application %s will not be scored", applicationID)
+	}
+}
+
+// Run scores queued applications until the queue is closed
+func (m *ResumeMatcher) Run() {
+	for applicationID := range m.Queue {
+		if _, err := m.Score(applicationID); err != nil {
+			log.Printf("Failed to score application %s: %v", applicationID, err)
+		}
+	}
+}
+
+// Score matches an application's resume text and listed skills against its job and
+// stores the result. It returns nil without storing anything when the job lists no
+// requirements, or when the resume was replaced while it was being scored.
+func (m *ResumeMatcher) Score(applicationID string) (*models.ResumeMatch, error) {
+	app, err := findApplication(m.AppRepo, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	job, err := findJob(m.JobRepo, uint(app.JobID))
+	if err != nil {
+		return nil, err
+	}
+
+	text := ""
+	if app.Resume != nil {
+		if text, err = m.resumeText(app.Resume); err != nil {
+			// the skills listed with the application still count
+			log.Printf("Failed to read resume of application %s: %v", applicationID, err)
+		}
+	}
+	match := models.MatchResume(job, app, text)
+	if match == nil {
+		return nil, nil
+	}
+
+	err = m.AppRepo.SetMatch(applicationID, *match)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// the upload of the newer resume queued its own scoring
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// resumeText reads an uploaded resume from the blob store and extracts its text
+func (m *ResumeMatcher) resumeText(resume *models.ResumeFile) (string, error) {
+	if m.Store == nil {
+		return "", errors.New("no blob store configured")
+	}
+	file, err := m.Store.Get(resume.Key)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxResumeSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read resume: %v", err)
+	}
+	return models.ExtractResumeText(resume.ContentType, data)
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"jobs-svc/internal/clients"
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"jobs-svc/internal/storage"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrResumeTooLarge    = fmt.Errorf("resume cannot be larger than %d MB", models.MaxResumeSize>>20)
+	ErrResumeLinkInvalid = errors.New("resume link is invalid")
+	ErrResumeLinkExpired = errors.New("resume link has expired")
+)
+
+// DefaultResumeLinkTTL is how long resume links stay valid when RESUME_LINK_TTL_MINUTES is not set
+const DefaultResumeLinkTTL = 15 * time.Minute
+
+// maxResumeFileNameLength caps the file name kept for the download's Content-Disposition
+const maxResumeFileNameLength = 255
+
+// ResumeLinks signs the links uploaded resumes are downloaded through. A link carries its
+// expiry and an HMAC of the application, the resume's key and the expiry, so it stops
+// working when it expires or a new resume is uploaded.
+type ResumeLinks struct {
+	Secret []byte
+	TTL    time.Duration
+	// BaseURL is where clients reach this service, such as https://jobs.example.com
+	BaseURL string
+}
+
+// Sign sets the application's ResumeURL to a fresh link if it has an uploaded resume.
+// Applications with a resume hosted elsewhere keep their URL.
+func (l *ResumeLinks) Sign(app *models.Application, now time.Time) {
+	if l == nil || app == nil || app.Resume == nil {
+		return
+	}
+	expires := now.Add(l.TTL).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", l.signature(app.ApplicationID, app.Resume.Key, expires))
+	app.ResumeURL = fmt.Sprintf("%s/applications/%s/resume?%s", strings.TrimRight(l.BaseURL, "/"), url.PathEscape(app.ApplicationID), query.Encode())
+}
+
+// Verify checks a link's expiry and signature against the application's current resume
+func (l *ResumeLinks) Verify(app *models.Application, expires string, signature string, now time.Time) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || l == nil || app.Resume == nil {
+		return ErrResumeLinkInvalid
+	}
+	expected := l.signature(app.ApplicationID, app.Resume.Key, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrResumeLinkInvalid
+	}
+	if now.Unix() > expiresAt {
+		return ErrResumeLinkExpired
+	}
+	return nil
+}
+
+func (l *ResumeLinks) signature(applicationID string, key string, expires int64) string {
+	mac := hmac.New(sha256.New, l.Secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d", applicationID, key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ResumeService stores the resumes candidates upload for their applications and serves
+// them back through signed links
+type ResumeService struct {
+	AppRepo      repos.ApplicationRepoInterface
+	JobRepo      repos.JobRepoInterface
+	PipelineRepo repos.PipelineRepoInterface
+	Store        storage.BlobStore
+	Links        *ResumeLinks
+	// Matcher rescores applications when their resume changes; nil skips scoring
+	Matcher *ResumeMatcher
+}
+
+// UploadResume stores a PDF or DOCX resume for an open application of the user. Files
+// are stored by content hash, so a file uploaded before is not stored again. changed is
+// false when the application already has this exact resume.
+func (s *ResumeService) UploadResume(user *clients.UserResponse, applicationID string, fileName string, data []byte) (app *models.Application, changed bool, err error) {
+	if app, err = findApplication(s.AppRepo, applicationID); err != nil {
+		return nil, false, err
+	}
+	if user == nil || uint(user.ID) != uint(app.CandidateID) {
+		return nil, false, ErrApplicationForbidden
+	}
+	job, err := findJob(s.JobRepo, uint(app.JobID))
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := checkApplicationOpen(s.PipelineRepo, app, job); err != nil {
+		return nil, false, err
+	}
+
+	if len(data) > models.MaxResumeSize {
+		return nil, false, ErrResumeTooLarge
+	}
+	contentType, err := models.DetectResumeType(fileName, data)
+	if err != nil {
+		return nil, false, err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if app.Resume != nil && app.Resume.SHA256 == hash {
+		s.Links.Sign(app, time.Now())
+		return app, false, nil
+	}
+
+	key := models.ResumeKey(hash, contentType)
+	exists, err := s.Store.Exists(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if !exists {
+		if err := s.Store.Put(key, bytes.NewReader(data)); err != nil {
+			return nil, false, err
+		}
+	}
+
+	resume := models.ResumeFile{
+		Key:         key,
+		FileName:    cleanResumeFileName(fileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hash,
+		UploadedAt:  time.Now(),
+	}
+	app, err = s.AppRepo.SetResume(applicationID, resume)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, ErrApplicationNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	s.Links.Sign(app, resume.UploadedAt)
+	s.Matcher.Enqueue(app.ApplicationID)
+	return app, true, nil
+}
+
+// OpenResume opens the resume a signed link points at. Links to applications without an
+// uploaded resume are invalid rather than not found, so links cannot be used to probe
+// for applications.
+func (s *ResumeService) OpenResume(applicationID string, expires string, signature string) (io.ReadCloser, *models.ResumeFile, error) {
+	app, err := findApplication(s.AppRepo, applicationID)
+	if errors.Is(err, ErrApplicationNotFound) {
+		return nil, nil, ErrResumeLinkInvalid
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.Links.Verify(app, expires, signature, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := s.Store.Get(app.Resume.Key)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil, ErrResumeLinkInvalid
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, app.Resume, nil
+}
+
+// cleanResumeFileName keeps the base name of an uploaded file, without characters that
+// would break a Content-Disposition header
+func cleanResumeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if len(name) > maxResumeFileNameLength {
+		name = strings.ToValidUTF8(name[len(name)-maxResumeFileNameLength:], "")
+	}
+	return name
+}
@@ -1,11 +1,16 @@
 package tests
 
 import (
+	"errors"
+	"jobs-svc/internal/clients"
 	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
 	"jobs-svc/internal/services"
 	"jobs-svc/internal/tests"
 	"testing"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 func TestJobService_CreateJob(t *testing.T) {
@@ -26,7 +31,7 @@ func TestJobService_CreateJob(t *testing.T) {
 		RecruiterId: 1,
 	}
 
-	err := service.CreateJob(job)
+	err := service.CreateJob(companyUser(1), job)
 	if err != nil {
 		t.Errorf("CreateJob failed: %v", err)
 	}
@@ -60,13 +65,69 @@ func TestJobService_GetJobs(t *testing.T) {
 	mockRepo.CreateJob(job1)
 	mockRepo.CreateJob(job2)
 
-	jobs, err := service.GetJobs()
+	page, err := service.GetJobs(models.JobQueryOptions{})
 	if err != nil {
 		t.Errorf("GetJobs failed: %v", err)
 	}
 
-	if len(*jobs) != 2 {
-		t.Errorf("Expected 2 jobs, got %d", len(*jobs))
+	if len(page.Items) != 2 {
+		t.Errorf("Expected 2 jobs, got %d", len(page.Items))
+	}
+	if page.Total != 2 || page.Page != 1 || page.Limit != models.DefaultJobPageLimit {
+		t.Errorf("Unexpected paging envelope: %+v", page)
+	}
+}
+
+func TestJobService_GetJobs_FiltersAndPaging(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	service := services.JobService{JobRepo: mockRepo}
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		mockRepo.CreateJob(&models.Job{
+			Title:      "Remote Job",
+			Location:   "Remote",
+			CompanyID:  1,
+			PostedDate: now.Add(-time.Duration(i) * 24 * time.Hour),
+		})
+	}
+	mockRepo.CreateJob(&models.Job{
+		Title:      "Onsite Job",
+		Location:   "Berlin",
+		CompanyID:  2,
+		PostedDate: now,
+	})
+
+	postedAfter := now.Add(-36 * time.Hour)
+	page, err := service.GetJobs(models.JobQueryOptions{
+		Location:    "remote",
+		PostedAfter: &postedAfter,
+	})
+	if err != nil {
+		t.Fatalf("GetJobs failed: %v", err)
+	}
+	if page.Total != 2 {
+		t.Errorf("Expected 2 recent remote jobs, got %d", page.Total)
+	}
+
+	page, err = service.GetJobs(models.JobQueryOptions{
+		CompanyID: 1,
+		Page:      2,
+		Limit:     2,
+		SortBy:    "postedDate",
+		SortDesc:  true,
+	})
+	if err != nil {
+		t.Fatalf("GetJobs failed: %v", err)
+	}
+	if page.Total != 5 || page.TotalPages != 3 {
+		t.Errorf("Expected 5 jobs over 3 pages, got %d over %d", page.Total, page.TotalPages)
+	}
+	if len(page.Items) != 2 {
+		t.Fatalf("Expected 2 jobs on page 2, got %d", len(page.Items))
+	}
+	if !page.Items[0].PostedDate.After(page.Items[1].PostedDate) {
+		t.Error("Expected jobs sorted by posted date descending")
 	}
 }
 
@@ -111,15 +172,16 @@ func TestJobService_UpdateJob(t *testing.T) {
 
 	// Create a job
 	job := &models.Job{
-		Title:    "Original Title",
-		Overview: "Original Overview",
-		Company:  "Original Company",
+		Title:     "Original Title",
+		Overview:  "Original Overview",
+		Company:   "Original Company",
+		CompanyID: 1,
 	}
 	mockRepo.CreateJob(job)
 
 	// Update the job
 	job.Title = "Updated Title"
-	err := service.UpdateJob(job)
+	err := service.UpdateJob(companyUser(1), job, nil, job.Version)
 	if err != nil {
 		t.Errorf("UpdateJob failed: %v", err)
 	}
@@ -140,13 +202,14 @@ func TestJobService_DeleteJob(t *testing.T) {
 
 	// Create a job
 	job := &models.Job{
-		Title:    "Test Job",
-		Overview: "Test Overview",
+		Title:     "Test Job",
+		Overview:  "Test Overview",
+		CompanyID: 1,
 	}
 	mockRepo.CreateJob(job)
 
 	// Delete the job
-	err := service.DeleteJob(job.ID)
+	_, err := service.DeleteJob(companyUser(1), job.ID)
 	if err != nil {
 		t.Errorf("DeleteJob failed: %v", err)
 	}
@@ -194,3 +257,477 @@ func TestJobService_GetJobsByIDs(t *testing.T) {
 		t.Errorf("Expected 2 jobs, got %d", len(*jobs))
 	}
 }
+
+func TestJobService_TransitionJob(t *testing.T) {
+	transitions := []struct {
+		name        string
+		from        models.JobStatus
+		to          models.JobStatus
+		expectedErr error
+	}{
+		{name: "publish draft", from: models.Draft, to: models.Open},
+		{name: "pause open job", from: models.Open, to: models.Paused},
+		{name: "resume paused job", from: models.Paused, to: models.Open},
+		{name: "close paused job", from: models.Paused, to: models.Closed},
+		{name: "archive filled job", from: models.Filled, to: models.Archived},
+		{name: "pause draft", from: models.Draft, to: models.Paused, expectedErr: services.ErrInvalidStatusTransition},
+		{name: "reopen closed job", from: models.Closed, to: models.Open, expectedErr: services.ErrInvalidStatusTransition},
+		{name: "resume archived job", from: models.Archived, to: models.Open, expectedErr: services.ErrInvalidStatusTransition},
+	}
+
+	for _, tt := range transitions {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := tests.NewMockJobRepo()
+			service := services.JobService{JobRepo: mockRepo}
+
+			job := &models.Job{Title: "Job", Status: tt.from, CompanyID: 1}
+			mockRepo.CreateJob(job)
+
+			updated, err := service.TransitionJob(companyUser(1), job.ID, tt.to)
+			if err != tt.expectedErr {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr == nil && updated.Status != tt.to {
+				t.Errorf("Expected status %s, got %s", tt.to, updated.Status)
+			}
+		})
+	}
+}
+
+func TestJobService_TransitionJob_NotFound(t *testing.T) {
+	service := services.JobService{JobRepo: tests.NewMockJobRepo()}
+
+	if _, err := service.TransitionJob(companyUser(1), 42, models.Open); err != services.ErrJobNotFound {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestJobService_UpdateJob_RejectsInvalidStatusChange(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	service := services.JobService{JobRepo: mockRepo}
+
+	mockRepo.CreateJob(&models.Job{Title: "Closed Job", Status: models.Closed, CompanyID: 1})
+
+	open := models.Open
+	err := service.UpdateJob(companyUser(1), &models.Job{Model: gorm.Model{ID: 1}, Title: "Closed Job"}, &open, 0)
+	if err != services.ErrInvalidStatusTransition {
+		t.Errorf("Expected ErrInvalidStatusTransition, got %v", err)
+	}
+}
+
+func TestJobService_UpdateJob_KeepsStatusWhenOmitted(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	service := services.JobService{JobRepo: mockRepo}
+
+	draft := &models.Job{Title: "Draft", Status: models.Draft, CompanyID: 1}
+	mockRepo.CreateJob(draft)
+	paused := &models.Job{Title: "Paused", Status: models.Paused, CompanyID: 1, PostedDate: time.Now().Add(-48 * time.Hour)}
+	mockRepo.CreateJob(paused)
+
+	// a routine edit neither publishes a draft nor reopens a paused job
+	edit := &models.Job{Model: gorm.Model{ID: draft.ID}, Title: "Draft v2"}
+	if err := service.UpdateJob(companyUser(1), edit, nil, 0); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+	if edit.Status != models.Draft || !edit.PostedDate.IsZero() {
+		t.Errorf("Expected the draft to stay unpublished, got %s posted %v", edit.Status, edit.PostedDate)
+	}
+	edit = &models.Job{Model: gorm.Model{ID: paused.ID}, Title: "Paused v2"}
+	if err := service.UpdateJob(companyUser(1), edit, nil, 0); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+	if edit.Status != models.Paused || !edit.PostedDate.Equal(paused.PostedDate) {
+		t.Errorf("Expected the paused job and its posted date to be kept, got %s posted %v", edit.Status, edit.PostedDate)
+	}
+
+	// publishing a draft over PUT stamps the posted date like the publish endpoint
+	open := models.Open
+	edit = &models.Job{Model: gorm.Model{ID: draft.ID}, Title: "Draft v3"}
+	if err := service.UpdateJob(companyUser(1), edit, &open, 0); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+	if edit.Status != models.Open || edit.PostedDate.IsZero() {
+		t.Errorf("Expected the draft to be published with a posted date, got %s posted %v", edit.Status, edit.PostedDate)
+	}
+}
+
+func TestJobService_PatchJob_PublishesDraft(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	service := services.JobService{JobRepo: mockRepo}
+
+	draft := &models.Job{Title: "Draft", Status: models.Draft, CompanyID: 1}
+	mockRepo.CreateJob(draft)
+
+	// an edit that leaves the status alone keeps the draft unpublished
+	job, _, err := service.PatchJob(companyUser(1), draft.ID, []byte(`{"title":"Draft v2"}`), 0)
+	if err != nil {
+		t.Fatalf("PatchJob failed: %v", err)
+	}
+	if job.Status != models.Draft || !job.PostedDate.IsZero() {
+		t.Errorf("Expected the draft to stay unpublished, got %s posted %v", job.Status, job.PostedDate)
+	}
+
+	// publishing a draft over PATCH stamps the posted date like the publish endpoint
+	job, _, err = service.PatchJob(companyUser(1), draft.ID, []byte(`{"status":"open"}`), 0)
+	if err != nil {
+		t.Fatalf("PatchJob failed: %v", err)
+	}
+	if job.Status != models.Open || job.PostedDate.IsZero() || time.Since(job.PostedDate) > time.Minute {
+		t.Errorf("Expected the draft to be published with a posted date, got %s posted %v", job.Status, job.PostedDate)
+	}
+	posted := job.PostedDate
+
+	// later edits of the open job keep its posted date
+	job, _, err = service.PatchJob(companyUser(1), draft.ID, []byte(`{"title":"Live"}`), 0)
+	if err != nil {
+		t.Fatalf("PatchJob failed: %v", err)
+	}
+	if !job.PostedDate.Equal(posted) {
+		t.Errorf("Expected the posted date %v to be kept, got %v", posted, job.PostedDate)
+	}
+}
+
+func TestJobService_CreateJob_InitialStatus(t *testing.T) {
+	service := services.JobService{JobRepo: tests.NewMockJobRepo()}
+
+	draft := &models.Job{Title: "Draft", Status: models.Draft}
+	if err := service.CreateJob(companyUser(1), draft); err != nil {
+		t.Errorf("Expected draft job to be created, got %v", err)
+	}
+	if !draft.PostedDate.IsZero() {
+		t.Error("Expected draft job to have no posted date")
+	}
+
+	if err := service.CreateJob(companyUser(1), &models.Job{Title: "Paused", Status: models.Paused}); err != services.ErrInvalidInitialStatus {
+		t.Errorf("Expected ErrInvalidInitialStatus, got %v", err)
+	}
+}
+
+func TestJobService_CompanyScoping(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	service := services.JobService{JobRepo: mockRepo}
+
+	createdAt := time.Now().Add(-48 * time.Hour)
+	job := &models.Job{Title: "Owned Job", CompanyID: 1}
+	job.CreatedAt = createdAt
+	mockRepo.CreateJob(job)
+
+	if err := service.UpdateJob(companyUser(2), &models.Job{Model: gorm.Model{ID: job.ID}, Title: "Hijacked"}, nil, 0); err != services.ErrJobForbidden {
+		t.Errorf("Expected ErrJobForbidden on update, got %v", err)
+	}
+	if _, err := service.DeleteJob(companyUser(2), job.ID); err != services.ErrJobForbidden {
+		t.Errorf("Expected ErrJobForbidden on delete, got %v", err)
+	}
+	if _, err := service.TransitionJob(companyUser(2), job.ID, models.Paused); err != services.ErrJobForbidden {
+		t.Errorf("Expected ErrJobForbidden on transition, got %v", err)
+	}
+	if _, err := service.DeleteJob(&clients.UserResponse{ID: 3}, job.ID); err != services.ErrJobForbidden {
+		t.Errorf("Expected ErrJobForbidden for user without an organization, got %v", err)
+	}
+	if _, err := service.DeleteJob(companyUser(1), 99); err != services.ErrJobNotFound {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+
+	// ownership and creation time survive a full replacement
+	update := &models.Job{Model: gorm.Model{ID: job.ID}, Title: "Renamed"}
+	if err := service.UpdateJob(companyUser(1), update, nil, 1); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+	if update.CompanyID != 1 || !update.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected company and creation time to be preserved, got %d and %v", update.CompanyID, update.CreatedAt)
+	}
+}
+
+func companyUser(orgID int) *clients.UserResponse {
+	return &clients.UserResponse{
+		ID:    orgID * 10,
+		Email: "recruiter@example.com",
+		Org:   &clients.Org{ID: orgID, Name: "Test Company"},
+	}
+}
+
+func TestJobService_UpdateJob_OptimisticConcurrency(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	service := services.JobService{JobRepo: mockRepo}
+
+	job := &models.Job{Title: "Original", CompanyID: 1}
+	if err := service.CreateJob(companyUser(1), job); err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	if job.Version != 1 {
+		t.Fatalf("Expected new job at version 1, got %d", job.Version)
+	}
+
+	first := &models.Job{Model: gorm.Model{ID: job.ID}, Title: "First edit"}
+	if err := service.UpdateJob(companyUser(1), first, nil, 1); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected version to be bumped to 2, got %d", first.Version)
+	}
+
+	// a second editor still holding version 1 must not overwrite the first edit
+	second := &models.Job{Model: gorm.Model{ID: job.ID}, Title: "Second edit"}
+	if err := service.UpdateJob(companyUser(1), second, nil, 1); err != repos.ErrJobVersionConflict {
+		t.Errorf("Expected ErrJobVersionConflict, got %v", err)
+	}
+
+	if _, _, err := service.PatchJob(companyUser(1), job.ID, []byte(`{"title":"Patched"}`), 1); err != repos.ErrJobVersionConflict {
+		t.Errorf("Expected ErrJobVersionConflict from stale patch, got %v", err)
+	}
+
+	stored, _ := service.GetJobByID(job.ID)
+	if stored.Title != "First edit" {
+		t.Errorf("Expected first edit to be kept, got %q", stored.Title)
+	}
+}
+
+func TestJobService_Revisions(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	service := services.JobService{JobRepo: mockRepo, RevisionRepo: tests.NewMockJobRevisionRepo()}
+
+	job := &models.Job{Title: "Backend Engineer", CompanyID: 1, SalaryRange: "$100,000", Status: models.Draft}
+	if err := service.CreateJob(companyUser(1), job); err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	editor := &clients.UserResponse{ID: 42, Org: &clients.Org{ID: 1}}
+	if _, _, err := service.PatchJob(editor, job.ID, []byte(`{"salaryRange":"$120,000"}`), 1); err != nil {
+		t.Fatalf("PatchJob failed: %v", err)
+	}
+	if _, err := service.TransitionJob(companyUser(1), job.ID, models.Open); err != nil {
+		t.Fatalf("TransitionJob failed: %v", err)
+	}
+
+	revisions, err := service.GetJobRevisions(companyUser(1), job.ID)
+	if err != nil {
+		t.Fatalf("GetJobRevisions failed: %v", err)
+	}
+	if len(revisions) != 3 || revisions[0].Revision != 3 || revisions[2].Revision != 1 {
+		t.Fatalf("Expected revisions 3..1, got %+v", revisions)
+	}
+	if revisions[1].EditorID != 42 {
+		t.Errorf("Expected revision 2 to be edited by user 42, got %d", revisions[1].EditorID)
+	}
+
+	diff, err := service.DiffJobRevision(companyUser(1), job.ID, 2)
+	if err != nil {
+		t.Fatalf("DiffJobRevision failed: %v", err)
+	}
+	if diff.From != 1 || diff.To != 2 || diff.EditorID != 42 {
+		t.Errorf("Unexpected diff header: %+v", diff)
+	}
+	if len(diff.Changes) != 1 {
+		t.Fatalf("Expected only the salary to change, got %+v", diff.Changes)
+	}
+	change := diff.Changes[0]
+	if change.Field != "salaryRange" || change.Old != "$100,000" || change.New != "$120,000" {
+		t.Errorf("Unexpected salary change: %+v", change)
+	}
+
+	// publishing changes the status and sets the posted date
+	diff, err = service.DiffJobRevision(companyUser(1), job.ID, 3)
+	if err != nil {
+		t.Fatalf("DiffJobRevision failed: %v", err)
+	}
+	fields := make([]string, len(diff.Changes))
+	for i, change := range diff.Changes {
+		fields[i] = change.Field
+	}
+	if len(fields) != 2 || fields[0] != "postedDate" || fields[1] != "status" {
+		t.Errorf("Expected postedDate and status to change, got %v", fields)
+	}
+
+	// the first revision is diffed against an empty job
+	diff, err = service.DiffJobRevision(companyUser(1), job.ID, 1)
+	if err != nil {
+		t.Fatalf("DiffJobRevision failed: %v", err)
+	}
+	for _, change := range diff.Changes {
+		if change.Old != nil {
+			t.Errorf("Expected field %s to be added in revision 1, got old value %v", change.Field, change.Old)
+		}
+	}
+
+	if _, err := service.DiffJobRevision(companyUser(1), job.ID, 4); err != services.ErrJobRevisionNotFound {
+		t.Errorf("Expected ErrJobRevisionNotFound, got %v", err)
+	}
+
+	// a job edited before history was kept has no earlier revision to compare with
+	legacy := &models.Job{Title: "Legacy", CompanyID: 1, Version: 4}
+	mockRepo.CreateJob(legacy)
+	if _, _, err := service.PatchJob(companyUser(1), legacy.ID, []byte(`{"title":"Legacy v2"}`), 0); err != nil {
+		t.Fatalf("PatchJob failed: %v", err)
+	}
+	diff, err = service.DiffJobRevision(companyUser(1), legacy.ID, 5)
+	if err != nil {
+		t.Fatalf("Expected the first recorded revision to diff, got %v", err)
+	}
+	if diff.From != 0 || diff.To != 5 || len(diff.Changes) == 0 {
+		t.Errorf("Expected revision 5 to be diffed against an empty job, got %+v", diff)
+	}
+	if _, err := service.GetJobRevisions(companyUser(2), job.ID); err != services.ErrJobForbidden {
+		t.Errorf("Expected ErrJobForbidden for another company, got %v", err)
+	}
+}
+
+func TestJobService_RestoreAndPurge(t *testing.T) {
+	mockRepo := tests.NewMockJobRepo()
+	revisionRepo := tests.NewMockJobRevisionRepo()
+	service := services.JobService{JobRepo: mockRepo, RevisionRepo: revisionRepo, PurgeRetention: 7 * 24 * time.Hour}
+
+	recent := &models.Job{Title: "Recently deleted", CompanyID: 1}
+	old := &models.Job{Title: "Deleted long ago", CompanyID: 1}
+	other := &models.Job{Title: "Other company", CompanyID: 2}
+	for _, job := range []*models.Job{recent, old, other} {
+		mockRepo.CreateJob(job)
+		if _, err := service.DeleteJob(companyUser(int(job.CompanyID)), job.ID); err != nil {
+			t.Fatalf("DeleteJob failed: %v", err)
+		}
+	}
+	mockRepo.(*tests.MockJobRepo).SetDeletedAt(old.ID, time.Now().Add(-10*24*time.Hour))
+
+	deleted, err := service.GetDeletedJobs(companyUser(1))
+	if err != nil {
+		t.Fatalf("GetDeletedJobs failed: %v", err)
+	}
+	if len(deleted) != 2 || deleted[0].ID != recent.ID {
+		t.Errorf("Expected company 1's two deleted jobs, most recent first, got %+v", deleted)
+	}
+	if _, err := service.GetDeletedJobs(&clients.UserResponse{ID: 3}); err != services.ErrJobForbidden {
+		t.Errorf("Expected ErrJobForbidden for user without an organization, got %v", err)
+	}
+
+	if _, err := service.RestoreJob(companyUser(1), other.ID); err != services.ErrJobForbidden {
+		t.Errorf("Expected ErrJobForbidden restoring another company's job, got %v", err)
+	}
+	restored, err := service.RestoreJob(companyUser(1), recent.ID)
+	if err != nil {
+		t.Fatalf("RestoreJob failed: %v", err)
+	}
+	if restored.DeletedAt.Valid {
+		t.Error("Expected restored job to have no deletion time")
+	}
+	if job, _ := service.GetJobByID(recent.ID); job == nil || job.Version != restored.Version {
+		t.Errorf("Expected restored job to be visible again at version %d, got %+v", restored.Version, job)
+	}
+	if _, err := revisionRepo.GetRevision(recent.ID, restored.Version); err != nil {
+		t.Errorf("Expected the restore to be recorded as revision %d, got %v", restored.Version, err)
+	}
+	if _, err := service.RestoreJob(companyUser(1), recent.ID); err != services.ErrJobNotFound {
+		t.Errorf("Expected ErrJobNotFound restoring a live job, got %v", err)
+	}
+
+	purged, err := service.PurgeDeletedJobs()
+	if err != nil {
+		t.Fatalf("PurgeDeletedJobs failed: %v", err)
+	}
+	if len(purged) != 1 || purged[0].ID != old.ID {
+		t.Errorf("Expected only the job past retention to be purged, got %+v", purged)
+	}
+	if _, err := service.RestoreJob(companyUser(1), old.ID); err != services.ErrJobNotFound {
+		t.Errorf("Expected purged job to be gone, got %v", err)
+	}
+}
+
+func TestJobService_JobPipeline(t *testing.T) {
+	jobRepo := NewMockJobRepo()
+	pipelineRepo := tests.NewMockPipelineRepo()
+	service := services.JobService{JobRepo: jobRepo, PipelineRepo: pipelineRepo}
+	pipelines := services.PipelineService{PipelineRepo: pipelineRepo, JobRepo: jobRepo}
+
+	stages := []models.PipelineStage{
+		{Name: "New", Transitions: []string{"Done"}},
+		{Name: "Done", Terminal: true},
+	}
+	own := &models.Pipeline{Name: "Own", Stages: stages}
+	other := &models.Pipeline{Name: "Other", Stages: stages}
+	if err := pipelines.CreatePipeline(companyUser(1), own); err != nil {
+		t.Fatalf("CreatePipeline failed: %v", err)
+	}
+	if err := pipelines.CreatePipeline(companyUser(2), other); err != nil {
+		t.Fatalf("CreatePipeline failed: %v", err)
+	}
+
+	foreign := &models.Job{Title: "Engineer", CompanyID: 1, Status: models.Draft, PipelineID: other.ID}
+	if err := service.CreateJob(companyUser(1), foreign); err != services.ErrUnknownPipeline {
+		t.Errorf("Expected ErrUnknownPipeline for another company's pipeline, got %v", err)
+	}
+
+	job := &models.Job{Title: "Engineer", CompanyID: 1, Status: models.Draft}
+	if err := service.CreateJob(companyUser(1), job); err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	if _, _, err := service.PatchJob(companyUser(1), job.ID, []byte(`{"pipelineId":"`+own.ID+`"}`), 0); err != nil {
+		t.Fatalf("Expected a draft's pipeline to be changeable, got %v", err)
+	}
+
+	// a PUT that leaves the pipeline out keeps it
+	open := models.Open
+	update := &models.Job{Model: gorm.Model{ID: job.ID}, Title: "Senior Engineer"}
+	if err := service.UpdateJob(companyUser(1), update, &open, 0); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+	if update.PipelineID != own.ID {
+		t.Errorf("Expected pipeline %s to be kept, got %q", own.ID, update.PipelineID)
+	}
+
+	if _, _, err := service.PatchJob(companyUser(1), job.ID, []byte(`{"pipelineId":null}`), 0); err != services.ErrJobPipelineLocked {
+		t.Errorf("Expected ErrJobPipelineLocked for an open job, got %v", err)
+	}
+	if err := pipelines.DeletePipeline(companyUser(1), own.ID); !errors.Is(err, services.ErrPipelineInUse) {
+		t.Errorf("Expected ErrPipelineInUse, got %v", err)
+	}
+
+	// a soft-deleted job can be restored, so it still holds on to its pipeline
+	if _, err := service.DeleteJob(companyUser(1), job.ID); err != nil {
+		t.Fatalf("DeleteJob failed: %v", err)
+	}
+	if err := pipelines.DeletePipeline(companyUser(1), own.ID); !errors.Is(err, services.ErrPipelineInUse) {
+		t.Errorf("Expected ErrPipelineInUse for a soft-deleted job, got %v", err)
+	}
+}
+
+func TestJobService_ScorecardTemplate(t *testing.T) {
+	service := services.JobService{JobRepo: NewMockJobRepo()}
+
+	invalid := []*models.ScorecardTemplate{
+		{},
+		{Competencies: []models.Competency{{Name: " ", Scale: models.RatingScale{Min: 1, Max: 5}}}},
+		{Competencies: []models.Competency{{Name: "Coding", Scale: models.RatingScale{Min: 5, Max: 5}}}},
+		{Competencies: []models.Competency{{Name: "Coding", Scale: models.RatingScale{Min: 1, Max: 5}, Weight: -1}}},
+		{Competencies: []models.Competency{
+			{Name: "Coding", Scale: models.RatingScale{Min: 1, Max: 5}},
+			{Name: "coding", Scale: models.RatingScale{Min: 0, Max: 10}},
+		}},
+	}
+	for i, template := range invalid {
+		job := &models.Job{Title: "Engineer", CompanyID: 1, Status: models.Draft, Scorecard: template}
+		var validationErr *models.ValidationError
+		if err := service.CreateJob(companyUser(1), job); !errors.As(err, &validationErr) {
+			t.Errorf("template %d: expected a validation error, got %v", i, err)
+		}
+	}
+
+	template := &models.ScorecardTemplate{Competencies: []models.Competency{{Name: "Coding", Scale: models.RatingScale{Min: 1, Max: 5}}}}
+	job := &models.Job{Title: "Engineer", CompanyID: 1, Status: models.Draft, Scorecard: template}
+	if err := service.CreateJob(companyUser(1), job); err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+
+	// a PUT that leaves the scorecard out keeps it
+	update := &models.Job{Model: gorm.Model{ID: job.ID}, Title: "Senior Engineer"}
+	if err := service.UpdateJob(companyUser(1), update, nil, 0); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+	if update.Scorecard == nil || len(update.Scorecard.Competencies) != 1 {
+		t.Errorf("Expected the scorecard template to be kept, got %+v", update.Scorecard)
+	}
+
+	patched, _, err := service.PatchJob(companyUser(1), job.ID, []byte(`{"scorecard":null}`), 0)
+	if err != nil {
+		t.Fatalf("PatchJob failed: %v", err)
+	}
+	if patched.Scorecard != nil {
+		t.Errorf("Expected the scorecard template to be removed, got %+v", patched.Scorecard)
+	}
+}
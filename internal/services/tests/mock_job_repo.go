@@ -3,22 +3,33 @@ package tests
 import (
 	"jobs-svc/internal/models"
 	"jobs-svc/internal/repos"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // MockJobRepo implements repos.JobRepoInterface
 type MockJobRepo struct {
-	jobs map[uint]*models.Job
+	jobs    map[uint]*models.Job
+	deleted map[uint]*models.Job
 }
 
 func NewMockJobRepo() repos.JobRepoInterface {
 	return &MockJobRepo{
-		jobs: make(map[uint]*models.Job),
+		jobs:    make(map[uint]*models.Job),
+		deleted: make(map[uint]*models.Job),
 	}
 }
 
 func (m *MockJobRepo) CreateJob(job *models.Job) error {
 	if job.ID == 0 {
-		job.ID = uint(len(m.jobs) + 1)
+		job.ID = uint(len(m.jobs) + len(m.deleted) + 1)
+	}
+	if job.Version == 0 {
+		job.Version = 1
 	}
 	m.jobs[job.ID] = job
 	return nil
@@ -31,12 +42,95 @@ func (m *MockJobRepo) GetJobByID(id uint) (*models.Job, error) {
 	return nil, nil
 }
 
-func (m *MockJobRepo) GetJobs() (*[]models.Job, error) {
+func (m *MockJobRepo) GetJobs(opts models.JobQueryOptions) (*[]models.Job, int64, error) {
 	jobs := make([]models.Job, 0, len(m.jobs))
 	for _, job := range m.jobs {
-		jobs = append(jobs, *job)
+		if matchesJobQuery(job, opts) {
+			jobs = append(jobs, *job)
+		}
 	}
-	return &jobs, nil
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if opts.SortDesc {
+			return jobLess(jobs[j], jobs[i], opts.SortBy)
+		}
+		return jobLess(jobs[i], jobs[j], opts.SortBy)
+	})
+
+	total := int64(len(jobs))
+	start := opts.Offset()
+	if start > len(jobs) {
+		start = len(jobs)
+	}
+	end := len(jobs)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+	}
+	page := jobs[start:end]
+	return &page, total, nil
+}
+
+// SearchJobs is an in-memory stand-in for the Postgres full-text search. Every query term
+// must appear in the job, and matches in the title and skills rank above the rest.
+func (m *MockJobRepo) SearchJobs(query string, opts models.JobQueryOptions) (*[]models.JobSearchResult, int64, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	results := make([]models.JobSearchResult, 0)
+	for _, job := range m.jobs {
+		if !matchesJobQuery(job, opts) {
+			continue
+		}
+		fields := []struct {
+			text   string
+			weight float64
+		}{
+			{job.Title, 1.0},
+			{job.Skills, 0.4},
+			{job.Overview, 0.2},
+			{job.Description, 0.1},
+		}
+
+		var rank float64
+		snippet := ""
+		matchedAll := true
+		for _, term := range terms {
+			matched := false
+			for _, field := range fields {
+				if count := strings.Count(strings.ToLower(field.text), term); count > 0 {
+					rank += float64(count) * field.weight
+					matched = true
+					if snippet == "" {
+						snippet = highlight(field.text, terms)
+					}
+				}
+			}
+			if !matched {
+				matchedAll = false
+				break
+			}
+		}
+		if matchedAll && len(terms) > 0 {
+			results = append(results, models.JobSearchResult{Job: *job, Rank: rank, Snippet: snippet})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].ID > results[j].ID
+	})
+
+	total := int64(len(results))
+	start := opts.Offset()
+	if start > len(results) {
+		start = len(results)
+	}
+	end := len(results)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+	}
+	page := results[start:end]
+	return &page, total, nil
 }
 
 func (m *MockJobRepo) GetJobsByRecruiterID(recruiterID uint) (*[]models.Job, error) {
@@ -50,7 +144,11 @@ func (m *MockJobRepo) GetJobsByRecruiterID(recruiterID uint) (*[]models.Job, err
 }
 
 func (m *MockJobRepo) UpdateJob(job *models.Job) error {
-	if _, exists := m.jobs[job.ID]; exists {
+	if existing, exists := m.jobs[job.ID]; exists {
+		if existing.Version != job.Version {
+			return repos.ErrJobVersionConflict
+		}
+		job.Version++
 		m.jobs[job.ID] = job
 		return nil
 	}
@@ -58,10 +156,63 @@ func (m *MockJobRepo) UpdateJob(job *models.Job) error {
 }
 
 func (m *MockJobRepo) DeleteJob(id uint) error {
-	delete(m.jobs, id)
+	if job, exists := m.jobs[id]; exists {
+		job.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		m.deleted[id] = job
+		delete(m.jobs, id)
+	}
+	return nil
+}
+
+func (m *MockJobRepo) GetDeletedJobs(companyID uint) (*[]models.Job, error) {
+	jobs := make([]models.Job, 0)
+	for _, job := range m.deleted {
+		if job.CompanyID == companyID {
+			jobs = append(jobs, *job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].DeletedAt.Time.After(jobs[j].DeletedAt.Time)
+	})
+	return &jobs, nil
+}
+
+func (m *MockJobRepo) GetDeletedJobByID(id uint) (*models.Job, error) {
+	if job, exists := m.deleted[id]; exists {
+		return job, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockJobRepo) RestoreJob(id uint) error {
+	if job, exists := m.deleted[id]; exists {
+		restored := *job
+		restored.DeletedAt = gorm.DeletedAt{}
+		restored.Version++
+		m.jobs[id] = &restored
+		delete(m.deleted, id)
+	}
 	return nil
 }
 
+func (m *MockJobRepo) PurgeDeletedJobs(before time.Time) (*[]models.Job, error) {
+	purged := make([]models.Job, 0)
+	for id, job := range m.deleted {
+		if job.DeletedAt.Time.Before(before) {
+			purged = append(purged, *job)
+			delete(m.deleted, id)
+		}
+	}
+	return &purged, nil
+}
+
+// SetDeletedAt backdates a soft-deleted job so retention can be tested
+func (m *MockJobRepo) SetDeletedAt(id uint, deletedAt time.Time) {
+	if job, exists := m.deleted[id]; exists {
+		job.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+	}
+}
+
 func (m *MockJobRepo) GetJobsByIDs(ids []uint) (*[]models.Job, error) {
 	jobs := make([]models.Job, 0)
 	for _, id := range ids {
@@ -71,3 +222,86 @@ func (m *MockJobRepo) GetJobsByIDs(ids []uint) (*[]models.Job, error) {
 	}
 	return &jobs, nil
 }
+
+func (m *MockJobRepo) CountJobsByPipeline(pipelineID string) (int64, error) {
+	var total int64
+	for _, jobs := range []map[uint]*models.Job{m.jobs, m.deleted} {
+		for _, job := range jobs {
+			if job.PipelineID == pipelineID {
+				total++
+			}
+		}
+	}
+	return total, nil
+}
+
+func matchesJobQuery(job *models.Job, opts models.JobQueryOptions) bool {
+	if opts.Status != nil && job.Status != *opts.Status {
+		return false
+	}
+	if opts.Status == nil && len(opts.Statuses) > 0 && !slices.Contains(opts.Statuses, job.Status) {
+		return false
+	}
+	if opts.Location != "" && !strings.Contains(strings.ToLower(job.Location), strings.ToLower(opts.Location)) {
+		return false
+	}
+	if opts.Company != "" && !strings.Contains(strings.ToLower(job.Company), strings.ToLower(opts.Company)) {
+		return false
+	}
+	if opts.CompanyID != 0 && job.CompanyID != opts.CompanyID {
+		return false
+	}
+	if opts.RecruiterID != 0 && job.RecruiterId != opts.RecruiterID {
+		return false
+	}
+	if opts.PipelineID != "" && job.PipelineID != opts.PipelineID {
+		return false
+	}
+	if opts.PostedAfter != nil && job.PostedDate.Before(*opts.PostedAfter) {
+		return false
+	}
+	if opts.PostedBefore != nil && job.PostedDate.After(*opts.PostedBefore) {
+		return false
+	}
+	return true
+}
+
+func jobLess(a, b models.Job, sortBy string) bool {
+	switch sortBy {
+	case "title":
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+	case "company":
+		if a.Company != b.Company {
+			return a.Company < b.Company
+		}
+	case "location":
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+	case "createdAt":
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+	default:
+		if !a.PostedDate.Equal(b.PostedDate) {
+			return a.PostedDate.Before(b.PostedDate)
+		}
+	}
+	return a.ID < b.ID
+}
+
+// highlight wraps every word containing a query term in <mark> tags
+func highlight(text string, terms []string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		for _, term := range terms {
+			if strings.Contains(strings.ToLower(word), term) {
+				words[i] = "<mark>" + word + "</mark>"
+				break
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps files such as uploaded resumes under slash-separated keys. Keys are
+// chosen by the service, never by clients.
+type BlobStore interface {
+	// Put stores the contents of r under key, replacing anything stored there
+	Put(key string, r io.Reader) error
+	// Get opens the blob stored under key, or fails with ErrBlobNotFound
+	Get(key string) (io.ReadCloser, error)
+	// Exists reports whether a blob is stored under key
+	Exists(key string) (bool, error)
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockInterviewRepo struct {
+	mu         sync.Mutex
+	interviews map[string]*models.Interview
+	slots      map[uint][]models.InterviewSlot
+}
+
+func NewMockInterviewRepo() repos.InterviewRepoInterface {
+	return &MockInterviewRepo{
+		interviews: make(map[string]*models.Interview),
+		slots:      make(map[uint][]models.InterviewSlot),
+	}
+}
+
+func (m *MockInterviewRepo) CreateInterview(interview *models.Interview) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *interview
+	m.interviews[interview.ID] = &stored
+	return nil
+}
+
+func (m *MockInterviewRepo) GetInterviewByID(interviewID string) (*models.Interview, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	interview, exists := m.interviews[interviewID]
+	if !exists {
+		return nil, mongo.ErrNoDocuments
+	}
+	found := *interview
+	return &found, nil
+}
+
+func (m *MockInterviewRepo) GetInterviewsByApplicationID(applicationID string) ([]models.Interview, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.find(func(interview *models.Interview) bool {
+		return interview.ApplicationID == applicationID
+	}), nil
+}
+
+func (m *MockInterviewRepo) FindConflicts(interviewerIDs []uint, start, end time.Time, excludeID string) ([]models.Interview, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.find(func(interview *models.Interview) bool {
+		if interview.ID == excludeID || !interview.Overlaps(start, end) {
+			return false
+		}
+		for _, id := range interviewerIDs {
+			if slices.Contains(interview.InterviewerIDs, id) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (m *MockInterviewRepo) UpdateInterview(interview *models.Interview) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.interviews[interview.ID]; !exists {
+		return mongo.ErrNoDocuments
+	}
+	stored := *interview
+	m.interviews[interview.ID] = &stored
+	return nil
+}
+
+func (m *MockInterviewRepo) BookInterviewer(interviewerID uint, slot models.InterviewSlot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, booked := range m.slots[interviewerID] {
+		if booked.InterviewID != slot.InterviewID && booked.StartsAt.Before(slot.EndsAt) && booked.EndsAt.After(slot.StartsAt) {
+			return repos.ErrInterviewerBooked
+		}
+	}
+	m.slots[interviewerID] = append(m.slots[interviewerID], slot)
+	return nil
+}
+
+func (m *MockInterviewRepo) ReleaseInterviewer(interviewerID uint, interviewID string, keepHold string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.slots[interviewerID] = slices.DeleteFunc(m.slots[interviewerID], func(slot models.InterviewSlot) bool {
+		return slot.InterviewID == interviewID && slot.Hold != keepHold
+	})
+	return nil
+}
+
+func (m *MockInterviewRepo) CreateIndexes() error {
+	return nil
+}
+
+func (m *MockInterviewRepo) find(match func(*models.Interview) bool) []models.Interview {
+	interviews := make([]models.Interview, 0)
+	for _, interview := range m.interviews {
+		if match(interview) {
+			interviews = append(interviews, *interview)
+		}
+	}
+	sort.Slice(interviews, func(i, j int) bool {
+		return interviews[i].StartsAt.Before(interviews[j].StartsAt)
+	})
+	return interviews
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MockJobRevisionRepo struct {
+	revisions []models.JobRevision
+}
+
+func NewMockJobRevisionRepo() repos.JobRevisionRepoInterface {
+	return &MockJobRevisionRepo{}
+}
+
+func (m *MockJobRevisionRepo) CreateRevision(revision *models.JobRevision) error {
+	revision.ID = uint(len(m.revisions) + 1)
+	if revision.CreatedAt.IsZero() {
+		revision.CreatedAt = time.Now()
+	}
+	m.revisions = append(m.revisions, *revision)
+	return nil
+}
+
+func (m *MockJobRevisionRepo) GetRevisions(jobID uint) (*[]models.JobRevision, error) {
+	revisions := make([]models.JobRevision, 0)
+	for _, revision := range m.revisions {
+		if revision.JobID == jobID {
+			revisions = append(revisions, revision)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return &revisions, nil
+}
+
+func (m *MockJobRevisionRepo) GetRevision(jobID uint, revision uint) (*models.JobRevision, error) {
+	for _, stored := range m.revisions {
+		if stored.JobID == jobID && stored.Revision == revision {
+			found := stored
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
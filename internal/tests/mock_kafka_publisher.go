@@ -3,19 +3,45 @@ package tests
 import (
 	"jobs-svc/internal/kafka"
 	"jobs-svc/internal/models"
-
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 type MockKafkaPublisher struct {
 	publishedJobs         []*models.Job
-	publishedApplications []bson.M
+	publishedJobEvents    []PublishedJobEvent
+	publishedApplications []*models.Application
+	publishedAppEvents    []PublishedApplicationEvent
+	publishedMentions     []PublishedNoteMention
+	publishedOfferEvents  []PublishedOfferEvent
+}
+
+// PublishedJobEvent records a call to PublishJobEvent
+type PublishedJobEvent struct {
+	Event string
+	Job   *models.Job
+}
+
+// PublishedApplicationEvent records a call to PublishApplicationEvent
+type PublishedApplicationEvent struct {
+	Event       string
+	Application *models.Application
+}
+
+// PublishedNoteMention records a call to PublishNoteMention
+type PublishedNoteMention struct {
+	Note   *models.Note
+	UserID uint
+}
+
+// PublishedOfferEvent records a call to PublishOfferEvent
+type PublishedOfferEvent struct {
+	Event string
+	Offer models.Offer
 }
 
 func NewMockKafkaPublisher() kafka.PublisherInterface {
 	return &MockKafkaPublisher{
 		publishedJobs:         make([]*models.Job, 0),
-		publishedApplications: make([]bson.M, 0),
+		publishedApplications: make([]*models.Application, 0),
 	}
 }
 
@@ -24,11 +50,31 @@ func (m *MockKafkaPublisher) PublishJob(job *models.Job) error {
 	return nil
 }
 
-func (m *MockKafkaPublisher) PublishApplication(application bson.M) error {
+func (m *MockKafkaPublisher) PublishJobEvent(eventType string, job *models.Job) error {
+	m.publishedJobEvents = append(m.publishedJobEvents, PublishedJobEvent{Event: eventType, Job: job})
+	return nil
+}
+
+func (m *MockKafkaPublisher) PublishApplication(application *models.Application) error {
 	m.publishedApplications = append(m.publishedApplications, application)
 	return nil
 }
 
+func (m *MockKafkaPublisher) PublishApplicationEvent(eventType string, application *models.Application) error {
+	m.publishedAppEvents = append(m.publishedAppEvents, PublishedApplicationEvent{Event: eventType, Application: application})
+	return nil
+}
+
+func (m *MockKafkaPublisher) PublishNoteMention(note *models.Note, mentionedUserID uint) error {
+	m.publishedMentions = append(m.publishedMentions, PublishedNoteMention{Note: note, UserID: mentionedUserID})
+	return nil
+}
+
+func (m *MockKafkaPublisher) PublishOfferEvent(eventType string, offer *models.Offer) error {
+	m.publishedOfferEvents = append(m.publishedOfferEvents, PublishedOfferEvent{Event: eventType, Offer: *offer})
+	return nil
+}
+
 func (m *MockKafkaPublisher) Close() error {
 	return nil
 }
@@ -37,6 +83,22 @@ func (m *MockKafkaPublisher) GetPublishedJobs() []*models.Job {
 	return m.publishedJobs
 }
 
-func (m *MockKafkaPublisher) GetPublishedApplications() []bson.M {
+func (m *MockKafkaPublisher) GetPublishedJobEvents() []PublishedJobEvent {
+	return m.publishedJobEvents
+}
+
+func (m *MockKafkaPublisher) GetPublishedApplications() []*models.Application {
 	return m.publishedApplications
 }
+
+func (m *MockKafkaPublisher) GetPublishedApplicationEvents() []PublishedApplicationEvent {
+	return m.publishedAppEvents
+}
+
+func (m *MockKafkaPublisher) GetPublishedNoteMentions() []PublishedNoteMention {
+	return m.publishedMentions
+}
+
+func (m *MockKafkaPublisher) GetPublishedOfferEvents() []PublishedOfferEvent {
+	return m.publishedOfferEvents
+}
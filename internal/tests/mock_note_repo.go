@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockNoteRepo struct {
+	notes map[string]*models.Note
+}
+
+func NewMockNoteRepo() repos.NoteRepoInterface {
+	return &MockNoteRepo{
+		notes: make(map[string]*models.Note),
+	}
+}
+
+func (m *MockNoteRepo) CreateNote(note *models.Note) error {
+	stored := *note
+	m.notes[note.ID] = &stored
+	return nil
+}
+
+func (m *MockNoteRepo) GetNoteByID(noteID string) (*models.Note, error) {
+	note, exists := m.notes[noteID]
+	if !exists {
+		return nil, mongo.ErrNoDocuments
+	}
+	found := *note
+	return &found, nil
+}
+
+func (m *MockNoteRepo) GetNotesByApplicationID(applicationID string, viewerID uint) ([]models.Note, error) {
+	notes := make([]models.Note, 0)
+	for _, note := range m.notes {
+		if note.ApplicationID == applicationID && note.VisibleTo(viewerID) {
+			notes = append(notes, *note)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		if !notes[i].CreatedAt.Equal(notes[j].CreatedAt) {
+			return notes[i].CreatedAt.Before(notes[j].CreatedAt)
+		}
+		return notes[i].ID < notes[j].ID
+	})
+	return notes, nil
+}
+
+func (m *MockNoteRepo) UpdateNote(note *models.Note) error {
+	stored, exists := m.notes[note.ID]
+	if !exists {
+		return mongo.ErrNoDocuments
+	}
+	stored.Body = note.Body
+	stored.Visibility = note.Visibility
+	stored.Mentions = note.Mentions
+	stored.UpdatedAt = note.UpdatedAt
+	return nil
+}
+
+func (m *MockNoteRepo) DeleteNote(noteID string) error {
+	if _, exists := m.notes[noteID]; !exists {
+		return mongo.ErrNoDocuments
+	}
+	delete(m.notes, noteID)
+	return nil
+}
+
+func (m *MockNoteRepo) CreateIndexes() error {
+	return nil
+}
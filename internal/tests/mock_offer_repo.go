@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockOfferRepo struct {
+	mu     sync.Mutex
+	offers map[string]*models.Offer
+}
+
+func NewMockOfferRepo() repos.OfferRepoInterface {
+	return &MockOfferRepo{
+		offers: make(map[string]*models.Offer),
+	}
+}
+
+// CreateOffer stores an offer, rejecting a second open offer for an application like
+// the partial unique index does
+func (m *MockOfferRepo) CreateOffer(offer *models.Offer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if offer.IsOpen() {
+		for _, existing := range m.offers {
+			if existing.ApplicationID == offer.ApplicationID && existing.IsOpen() {
+				return repos.ErrOpenOfferExists
+			}
+		}
+	}
+	m.offers[offer.ID] = copyOffer(offer)
+	return nil
+}
+
+func (m *MockOfferRepo) GetOfferByID(offerID string) (*models.Offer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offer, exists := m.offers[offerID]
+	if !exists {
+		return nil, mongo.ErrNoDocuments
+	}
+	return copyOffer(offer), nil
+}
+
+func (m *MockOfferRepo) GetOffersByApplicationID(applicationID string) ([]models.Offer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offers := make([]models.Offer, 0)
+	for _, offer := range m.offers {
+		if offer.ApplicationID == applicationID {
+			offers = append(offers, *copyOffer(offer))
+		}
+	}
+	sort.Slice(offers, func(i, j int) bool {
+		return offers[i].CreatedAt.Before(offers[j].CreatedAt)
+	})
+	return offers, nil
+}
+
+func (m *MockOfferRepo) FindExpiredOffers(now time.Time) ([]models.Offer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offers := make([]models.Offer, 0)
+	for _, offer := range m.offers {
+		if offer.Status == models.OfferStatusExtended && !offer.ExpiresAt.After(now) {
+			offers = append(offers, *copyOffer(offer))
+		}
+	}
+	return offers, nil
+}
+
+func (m *MockOfferRepo) UpdateOffer(offer *models.Offer, from string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, exists := m.offers[offer.ID]
+	if !exists || stored.Status != from {
+		return repos.ErrOfferConflict
+	}
+	m.offers[offer.ID] = copyOffer(offer)
+	return nil
+}
+
+func (m *MockOfferRepo) CreateIndexes() error {
+	return nil
+}
+
+// copyOffer copies an offer so callers cannot change the stored history through their copy
+func copyOffer(offer *models.Offer) *models.Offer {
+	copied := *offer
+	copied.History = append([]models.OfferStatusChange(nil), offer.History...)
+	return &copied
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockPipelineRepo struct {
+	pipelines map[string]*models.Pipeline
+}
+
+func NewMockPipelineRepo() repos.PipelineRepoInterface {
+	return &MockPipelineRepo{
+		pipelines: make(map[string]*models.Pipeline),
+	}
+}
+
+func (m *MockPipelineRepo) CreatePipeline(pipeline *models.Pipeline) error {
+	if m.nameTaken(pipeline) {
+		return repos.ErrDuplicatePipeline
+	}
+	stored := *pipeline
+	m.pipelines[pipeline.ID] = &stored
+	return nil
+}
+
+func (m *MockPipelineRepo) GetPipelineByID(pipelineID string) (*models.Pipeline, error) {
+	pipeline, exists := m.pipelines[pipelineID]
+	if !exists {
+		return nil, mongo.ErrNoDocuments
+	}
+	found := *pipeline
+	return &found, nil
+}
+
+func (m *MockPipelineRepo) GetPipelinesByCompanyID(companyID uint) ([]models.Pipeline, error) {
+	pipelines := make([]models.Pipeline, 0)
+	for _, pipeline := range m.pipelines {
+		if pipeline.CompanyID == companyID {
+			pipelines = append(pipelines, *pipeline)
+		}
+	}
+	sort.Slice(pipelines, func(i, j int) bool {
+		return pipelines[i].Name < pipelines[j].Name
+	})
+	return pipelines, nil
+}
+
+func (m *MockPipelineRepo) UpdatePipeline(pipeline *models.Pipeline) error {
+	if _, exists := m.pipelines[pipeline.ID]; !exists {
+		return mongo.ErrNoDocuments
+	}
+	if m.nameTaken(pipeline) {
+		return repos.ErrDuplicatePipeline
+	}
+	stored := *pipeline
+	m.pipelines[pipeline.ID] = &stored
+	return nil
+}
+
+func (m *MockPipelineRepo) DeletePipeline(pipelineID string) error {
+	if _, exists := m.pipelines[pipelineID]; !exists {
+		return mongo.ErrNoDocuments
+	}
+	delete(m.pipelines, pipelineID)
+	return nil
+}
+
+func (m *MockPipelineRepo) CreateUniqueIndex() error {
+	return nil
+}
+
+// nameTaken mirrors the unique index on company_id and name
+func (m *MockPipelineRepo) nameTaken(pipeline *models.Pipeline) bool {
+	for _, stored := range m.pipelines {
+		if stored.ID != pipeline.ID && stored.CompanyID == pipeline.CompanyID && stored.Name == pipeline.Name {
+			return true
+		}
+	}
+	return false
+}
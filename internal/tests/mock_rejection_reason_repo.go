@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"jobs-svc/internal/models"
+	"jobs-svc/internal/repos"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockRejectionReasonRepo struct {
+	reasons []models.RejectionReason
+}
+
+func NewMockRejectionReasonRepo() repos.RejectionReasonRepoInterface {
+	return &MockRejectionReasonRepo{
+		reasons: make([]models.RejectionReason, 0),
+	}
+}
+
+func (m *MockRejectionReasonRepo) CreateReason(reason *models.RejectionReason) error {
+	for _, existing := range m.reasons {
+		if existing.CompanyID == reason.CompanyID && existing.Code == reason.Code {
+			return repos.ErrDuplicateRejectionReason
+		}
+	}
+	m.reasons = append(m.reasons, *reason)
+	return nil
+}
+
+func (m *MockRejectionReasonRepo) GetReasonsByCompanyID(companyID uint) ([]models.RejectionReason, error) {
+	reasons := make([]models.RejectionReason, 0)
+	for _, reason := range m.reasons {
+		if reason.CompanyID == companyID {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons, nil
+}
+
+func (m *MockRejectionReasonRepo) UpdateReason(reason *models.RejectionReason) error {
+	for i, existing := range m.reasons {
+		if existing.CompanyID == reason.CompanyID && existing.Code == reason.Code {
+			m.reasons[i].Label = reason.Label
+			m.reasons[i].Description = reason.Description
+			m.reasons[i].Archived = reason.Archived
+			m.reasons[i].UpdatedAt = reason.UpdatedAt
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (m *MockRejectionReasonRepo) CreateUniqueIndex() error {
+	return nil
+}
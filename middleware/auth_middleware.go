@@ -8,6 +8,16 @@ import (
 )
 
 func AuthMiddleware(action string) func(http.Handler) http.Handler {
+	return authenticate(action, clients.ValidateToken)
+}
+
+// CandidateAuthMiddleware authenticates users who need not belong to an organization,
+// such as candidates acting on their own applications
+func CandidateAuthMiddleware(action string) func(http.Handler) http.Handler {
+	return authenticate(action, clients.ValidateCandidateToken)
+}
+
+func authenticate(action string, validate func(token string, action string) (*clients.UserResponse, error)) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -24,7 +34,7 @@ func AuthMiddleware(action string) func(http.Handler) http.Handler {
 			}
 
 			// Validate token using the auth microservice
-			userInfo, err := clients.ValidateToken(token, action)
+			userInfo, err := validate(token, action)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusForbidden)
 				return